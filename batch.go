@@ -0,0 +1,43 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/jbranchaud/go-sudoku/solver"
+)
+
+func printBatchResult(result solver.BatchResult, options solver.Options) {
+	fmt.Print(result.Log)
+
+	if result.Err != nil {
+		fmt.Printf("%s: %s, %v\n", result.Entry.Label(), result.Status, result.Err)
+		return
+	}
+
+	solutions := fmt.Sprintf("%d", result.Diagnostics.SolutionsFound)
+	if result.Status == solver.BatchMultiple && options.TraversalType == solver.EnsureUnique {
+		solutions = fmt.Sprintf("at least %d", result.Diagnostics.SolutionsFound)
+	}
+
+	fmt.Printf("%s: %s, solutions: %s, nodes: %d, backtracks: %d\n",
+		result.Entry.Label(), result.Status, solutions, result.Diagnostics.NodeVisitCount, result.Diagnostics.BacktrackCount)
+}
+
+func printBatchSummary(summary solver.BatchSummary, elapsed time.Duration) {
+	fmt.Println("")
+	fmt.Printf("Puzzles: %d\n", summary.Puzzles)
+	fmt.Printf("Solved: %d\n", summary.Solved)
+	fmt.Printf("Multiple Solutions: %d\n", summary.Multiple)
+	fmt.Printf("No Solution: %d\n", summary.NoSolution)
+	fmt.Printf("Invalid: %d\n", summary.Invalid)
+	if summary.Failed > 0 {
+		fmt.Printf("Failed: %d\n", summary.Failed)
+	}
+	if summary.Aborted > 0 {
+		fmt.Printf("Aborted: %d\n", summary.Aborted)
+	}
+	fmt.Printf("Nodes Visited: %d\n", summary.NodeVisitCount)
+	fmt.Printf("Backtracks: %d\n", summary.BacktrackCount)
+	fmt.Printf("Elapsed: %s\n", elapsed.Round(time.Millisecond))
+}
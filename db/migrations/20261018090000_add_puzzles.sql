@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+create table puzzles (
+	id integer primary key autoincrement,
+	puzzle_template_id integer not null references puzzle_templates(id),
+	board text not null unique
+)
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop table puzzles;
+-- +goose StatementEnd
@@ -0,0 +1,39 @@
+-- +goose Up
+-- +goose StatementBegin
+create table puzzle_templates_with_strategy (
+	id integer primary key autoincrement,
+	seed integer not null,
+	strategy text not null default 'first-empty',
+	board text not null unique,
+	unique (seed, strategy)
+);
+-- +goose StatementEnd
+-- +goose StatementBegin
+insert into puzzle_templates_with_strategy (id, seed, board)
+	select id, seed, board from puzzle_templates;
+-- +goose StatementEnd
+-- +goose StatementBegin
+drop table puzzle_templates;
+-- +goose StatementEnd
+-- +goose StatementBegin
+alter table puzzle_templates_with_strategy rename to puzzle_templates;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+create table puzzle_templates_without_strategy (
+	id integer primary key autoincrement,
+	seed integer not null unique,
+	board text not null unique
+);
+-- +goose StatementEnd
+-- +goose StatementBegin
+insert into puzzle_templates_without_strategy (id, seed, board)
+	select id, seed, board from puzzle_templates where strategy = 'first-empty';
+-- +goose StatementEnd
+-- +goose StatementBegin
+drop table puzzle_templates;
+-- +goose StatementEnd
+-- +goose StatementBegin
+alter table puzzle_templates_without_strategy rename to puzzle_templates;
+-- +goose StatementEnd
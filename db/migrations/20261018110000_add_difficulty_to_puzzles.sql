@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+alter table puzzles add column difficulty text;
+-- +goose StatementEnd
+-- +goose StatementBegin
+alter table puzzles add column rating real;
+-- +goose StatementEnd
+-- +goose StatementBegin
+alter table puzzles add column attempts integer;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+alter table puzzles drop column attempts;
+-- +goose StatementEnd
+-- +goose StatementBegin
+alter table puzzles drop column rating;
+-- +goose StatementEnd
+-- +goose StatementBegin
+alter table puzzles drop column difficulty;
+-- +goose StatementEnd
@@ -0,0 +1,9 @@
+-- +goose Up
+-- +goose StatementBegin
+alter table puzzles add column symmetry text not null default 'none';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+alter table puzzles drop column symmetry;
+-- +goose StatementEnd
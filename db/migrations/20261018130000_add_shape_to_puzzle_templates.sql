@@ -0,0 +1,42 @@
+-- +goose Up
+-- +goose StatementBegin
+create table puzzle_templates_with_shape (
+	id integer primary key autoincrement,
+	seed integer not null,
+	strategy text not null default 'first-empty',
+	shape text not null default '3x3',
+	board text not null unique,
+	unique (seed, strategy, shape)
+);
+-- +goose StatementEnd
+-- +goose StatementBegin
+insert into puzzle_templates_with_shape (id, seed, strategy, board)
+	select id, seed, strategy, board from puzzle_templates;
+-- +goose StatementEnd
+-- +goose StatementBegin
+drop table puzzle_templates;
+-- +goose StatementEnd
+-- +goose StatementBegin
+alter table puzzle_templates_with_shape rename to puzzle_templates;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+create table puzzle_templates_without_shape (
+	id integer primary key autoincrement,
+	seed integer not null,
+	strategy text not null default 'first-empty',
+	board text not null unique,
+	unique (seed, strategy)
+);
+-- +goose StatementEnd
+-- +goose StatementBegin
+insert into puzzle_templates_without_shape (id, seed, strategy, board)
+	select id, seed, strategy, board from puzzle_templates where shape = '3x3';
+-- +goose StatementEnd
+-- +goose StatementBegin
+drop table puzzle_templates;
+-- +goose StatementEnd
+-- +goose StatementBegin
+alter table puzzle_templates_without_shape rename to puzzle_templates;
+-- +goose StatementEnd
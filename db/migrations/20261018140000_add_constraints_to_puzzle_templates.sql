@@ -0,0 +1,44 @@
+-- +goose Up
+-- +goose StatementBegin
+create table puzzle_templates_with_constraints (
+	id integer primary key autoincrement,
+	seed integer not null,
+	strategy text not null default 'first-empty',
+	shape text not null default '3x3',
+	constraints text not null default '',
+	board text not null unique,
+	unique (seed, strategy, shape, constraints)
+);
+-- +goose StatementEnd
+-- +goose StatementBegin
+insert into puzzle_templates_with_constraints (id, seed, strategy, shape, board)
+	select id, seed, strategy, shape, board from puzzle_templates;
+-- +goose StatementEnd
+-- +goose StatementBegin
+drop table puzzle_templates;
+-- +goose StatementEnd
+-- +goose StatementBegin
+alter table puzzle_templates_with_constraints rename to puzzle_templates;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+create table puzzle_templates_without_constraints (
+	id integer primary key autoincrement,
+	seed integer not null,
+	strategy text not null default 'first-empty',
+	shape text not null default '3x3',
+	board text not null unique,
+	unique (seed, strategy, shape)
+);
+-- +goose StatementEnd
+-- +goose StatementBegin
+insert into puzzle_templates_without_constraints (id, seed, strategy, shape, board)
+	select id, seed, strategy, shape, board from puzzle_templates where constraints = '';
+-- +goose StatementEnd
+-- +goose StatementBegin
+drop table puzzle_templates;
+-- +goose StatementEnd
+-- +goose StatementBegin
+alter table puzzle_templates_without_constraints rename to puzzle_templates;
+-- +goose StatementEnd
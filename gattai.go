@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/jbranchaud/go-sudoku/solver"
+	"github.com/jbranchaud/go-sudoku/sudoku"
+)
+
+func solveGattaiPuzzle(ctx context.Context, gattai sudoku.Gattai, options solver.Options) {
+	fmt.Println("Initial puzzle:")
+	fmt.Println(gattai.PrettyString())
+
+	err := solver.ValidateGattai(gattai)
+	if err != nil {
+		fmt.Println(err.Error())
+	} else {
+		fmt.Println("Puzzle is valid")
+	}
+
+	status, solved, diagnostics, err := solver.SolveGattai(ctx, gattai, options)
+	var validationErr *solver.ValidationError
+	if err != nil && !errors.As(err, &validationErr) {
+		fmt.Println(err.Error())
+		os.Exit(1)
+	}
+
+	switch status {
+	case solver.Solved:
+		fmt.Println("Solved the puzzle:")
+		if diagnostics.SolutionsFound > 1 {
+			if options.TraversalType == solver.EnsureUnique {
+				fmt.Printf("(this puzzle has at least %d solutions)\n", diagnostics.SolutionsFound)
+			} else {
+				fmt.Printf("(this puzzle has %d solutions)\n", diagnostics.SolutionsFound)
+			}
+		}
+		printGattaiSolution(gattai, diagnostics.Solutions[0])
+	case solver.Aborted:
+		fmt.Printf("Gave up on the puzzle after visiting %d nodes, %s\n", diagnostics.NodeVisitCount, abortReason(options, diagnostics))
+		if diagnostics.SolutionsFound > 0 {
+			fmt.Printf("(found %d solution(s) before giving up, there could be more)\n", diagnostics.SolutionsFound)
+			printGattaiSolution(gattai, diagnostics.Solutions[0])
+		}
+	default:
+		fmt.Println("Unable to solve puzzle:")
+		fmt.Println(solved.PrettyString())
+	}
+
+	if options.Debug {
+		fmt.Println("")
+		fmt.Println("Search Space Diagnostics:")
+		fmt.Printf("Nodes Visited: %d\n", diagnostics.NodeVisitCount)
+		fmt.Printf("Backtracks: %d\n", diagnostics.BacktrackCount)
+		fmt.Printf("Validity Checks: %d\n", diagnostics.ValidityCheckCount)
+		if options.Propagate {
+			fmt.Printf("Propagated Placements: %d (naked singles: %d, hidden singles: %d)\n",
+				diagnostics.PropagatedCount(), diagnostics.NakedSingleCount, diagnostics.HiddenSingleCount)
+		}
+		fmt.Printf("Solutions Found: %d\n", diagnostics.SolutionsFound)
+	}
+}
+
+// Print the puzzle with one of the solutions from its diagnostics filled in.
+func printGattaiSolution(gattai sudoku.Gattai, solution string) {
+	solved, err := solver.GattaiSolutionOf(gattai, solution)
+	if err != nil {
+		fmt.Printf("Error reading back the solution: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Println(solved.PrettyString())
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+
+	"github.com/jbranchaud/go-sudoku/solver"
+)
+
+func findPuzzleTemplateById(db *sql.DB, id int64) (PuzzleTemplate, error) {
+	puzzleTemplate := PuzzleTemplate{}
+
+	findPuzzleTemplateSql := "select id, seed, strategy, shape, constraints, board from puzzle_templates where id = ?;"
+	err := db.QueryRow(findPuzzleTemplateSql, id).Scan(
+		&puzzleTemplate.ID,
+		&puzzleTemplate.Seed,
+		&puzzleTemplate.Strategy,
+		&puzzleTemplate.Shape,
+		&puzzleTemplate.Constraints,
+		&puzzleTemplate.Board,
+	)
+
+	return puzzleTemplate, err
+}
+
+func findOrRecordPuzzle(db *sql.DB, generated solver.GeneratedPuzzle, puzzleTemplateId int64) (int64, bool, error) {
+	new := true
+	var id int64
+
+	findPuzzleSql := "select id from puzzles where board = ?;"
+	err := db.QueryRow(findPuzzleSql, generated.Puzzle.String()).Scan(&id)
+
+	if err == sql.ErrNoRows {
+		return recordPuzzle(db, generated, puzzleTemplateId), new, nil
+	} else if err != nil {
+		return -1, !new, err
+	}
+
+	return id, !new, nil
+}
+
+func recordPuzzle(db *sql.DB, generated solver.GeneratedPuzzle, puzzleTemplateId int64) int64 {
+	insertPuzzle := `insert into puzzles (puzzle_template_id, board, symmetry, difficulty, rating, attempts)
+		values (?, ?, ?, ?, ?, ?);`
+
+	result, err := db.Exec(
+		insertPuzzle,
+		puzzleTemplateId,
+		generated.Puzzle.String(),
+		generated.Symmetry,
+		generated.Grade.Difficulty,
+		generated.Grade.Rating,
+		generated.Attempts,
+	)
+	if err != nil {
+		fmt.Printf("Error inserting puzzle: %v\n", err)
+		os.Exit(1)
+	}
+
+	id, _ := result.LastInsertId()
+
+	return id
+}
@@ -2,92 +2,86 @@ package main
 
 import (
 	"bufio"
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"io"
-	"math/rand"
 	"os"
 	"slices"
-	"strconv"
 	"strings"
+	"time"
 
 	_ "github.com/mattn/go-sqlite3"
 	"github.com/spf13/cobra"
 
-	"github.com/jbranchaud/go-sudoku/internal/sudoku"
+	"github.com/jbranchaud/go-sudoku/solver"
+	"github.com/jbranchaud/go-sudoku/sudoku"
 )
 
 func readInPuzzle(scanner *bufio.Scanner) sudoku.Puzzle {
+	puzzle, err := solver.Parse(readInContents(scanner))
+	if err != nil {
+		fmt.Printf("Error reading puzzle: %v\n", err)
+		os.Exit(1)
+	}
+
+	return puzzle
+}
+
+func readInContents(scanner *bufio.Scanner) string {
 	rows := []string{}
 	for scanner.Scan() {
 		row := scanner.Text()
 		rows = append(rows, row)
 	}
 
-	puzzleString := strings.Join(rows, "\n")
-	puzzle := hydratePuzzle(puzzleString)
-
-	return puzzle
+	return strings.Join(rows, "\n")
 }
 
-func hydratePuzzle(str string) sudoku.Puzzle {
-	var puzzle sudoku.Puzzle
-
-	for _, row := range strings.Split(str, "\n") {
-		unparsedCells := strings.Split(row, "")
-		var cells []int
-		for _, unparsedCell := range unparsedCells {
-			cell, err := strconv.Atoi(unparsedCell)
-			if err != nil {
-				fmt.Printf("Error: %v\n", err)
-				os.Exit(1)
-			}
-			cells = append(cells, cell)
+// Commands that take a puzzle can be invoked one of the following ways:
+// - with the puzzle file as the first argument
+// - with a puzzle redirected to stdin
+// - with no arg, in which case it will wait for a puzzle file name
+func openPuzzleReader(args []string, action string) io.Reader {
+	if len(args) > 0 {
+		// read the puzzle from the given file
+		file, err := os.Open(args[0])
+		if err != nil {
+			fmt.Printf("Unable to read file: %s\n", args[0])
+			os.Exit(1)
 		}
 
-		puzzle.Board = append(puzzle.Board, cells)
+		return file
 	}
 
-	return puzzle
-}
-
-type TraversalType string
-
-const (
-	FindFirst    TraversalType = "FindFirst"
-	EnsureUnique TraversalType = "EnsureUnique"
-	FindAll      TraversalType = "FindAll"
-)
-
-type Options struct {
-	Debug         bool
-	TraversalType TraversalType
-	SolveOrder    Order
-	Seed          int64
-	Rng           *rand.Rand
-}
-
-func NewOptions(debug bool, traversalType TraversalType, solveOrder Order, seedFromFlag *int64) Options {
-	options := Options{
-		Debug:         debug,
-		SolveOrder:    solveOrder,
-		TraversalType: traversalType,
+	file, err := os.Stdin.Stat()
+	if err != nil {
+		fmt.Printf("Error checking stdin: %v\n", err)
+		os.Exit(1)
 	}
+	waitingForUserInput := (file.Mode() & os.ModeCharDevice) != 0
 
-	if solveOrder == Shuffled {
-		var seed int64
+	if !waitingForUserInput {
+		// input is being piped in from a file to stdin
+		return os.Stdin
+	}
 
-		if seedFromFlag == nil {
-			seed = rand.Int63()
-		} else {
-			seed = *seedFromFlag
-		}
+	fmt.Printf("Enter a file name for puzzle to %s: ", action)
+	termInputScanner := bufio.NewScanner(os.Stdin)
+	var filename string
+	for termInputScanner.Scan() {
+		filename = termInputScanner.Text()
+		break
+	}
 
-		options.Seed = seed
-		options.Rng = rand.New(rand.NewSource(seed))
+	puzzleFile, err := os.Open(filename)
+	if err != nil {
+		fmt.Printf("Unable to read file: %s\n", filename)
+		os.Exit(1)
 	}
 
-	return options
+	return puzzleFile
 }
 
 func setupDatabase() *sql.DB {
@@ -105,11 +99,11 @@ func setupDatabase() *sql.DB {
 	return db
 }
 
-func recordPuzzleTemplate(db *sql.DB, puzzle sudoku.Puzzle, seed int64) int64 {
-	insertPuzzleTemplate := `insert into puzzle_templates (seed, board)
-		values (?, ?);`
+func recordPuzzleTemplate(db *sql.DB, puzzle sudoku.Puzzle, seed int64, strategy solver.CellStrategy) int64 {
+	insertPuzzleTemplate := `insert into puzzle_templates (seed, strategy, shape, constraints, board)
+		values (?, ?, ?, ?, ?);`
 
-	result, err := db.Exec(insertPuzzleTemplate, seed, puzzle.String())
+	result, err := db.Exec(insertPuzzleTemplate, seed, strategy, puzzle.GridShape().String(), sudoku.ConstraintNames(puzzle.Constraints), puzzle.String())
 	if err != nil {
 		fmt.Printf("Error inserting puzzle template: %v\n", err)
 		os.Exit(1)
@@ -121,19 +115,59 @@ func recordPuzzleTemplate(db *sql.DB, puzzle sudoku.Puzzle, seed int64) int64 {
 }
 
 type PuzzleTemplate struct {
-	ID    int
-	Seed  int64
-	Board string
+	ID          int
+	Seed        int64
+	Strategy    solver.CellStrategy
+	Shape       string
+	Constraints string
+	Board       string
+}
+
+// A puzzle template's board, with the sector shape and constraints it was
+// generated with.
+func (puzzleTemplate PuzzleTemplate) Puzzle() (sudoku.Puzzle, error) {
+	shape, err := sudoku.ParseShape(puzzleTemplate.Shape)
+	if err != nil {
+		return sudoku.Puzzle{}, err
+	}
+
+	constraints, err := sudoku.ParseConstraints(puzzleTemplate.Constraints)
+	if err != nil {
+		return sudoku.Puzzle{}, err
+	}
+
+	puzzle, err := solver.Parse(puzzleTemplate.Board)
+	if err != nil {
+		return sudoku.Puzzle{}, err
+	}
+	puzzle.Shape = shape
+	if len(constraints) > 0 {
+		puzzle.Constraints = constraints
+	}
+
+	return puzzle, nil
 }
 
-func findOrCreateSolution(db *sql.DB, options Options) (sudoku.Puzzle, int64, bool, error) {
+func findOrCreateSolution(db *sql.DB, options solver.Options) (sudoku.Puzzle, int64, bool, error) {
 	new := true
 	puzzleTemplate := &PuzzleTemplate{}
 
-	findPuzzleTemplateSql := "select id, seed, board from puzzle_templates where seed = ?;"
-	err := db.QueryRow(findPuzzleTemplateSql, options.Seed).Scan(
+	// the same seed fills in the board differently depending on the order
+	// cells are visited in, the shape of the board, and the constraints it
+	// has to follow, so templates are unique per seed, strategy, shape, and
+	// constraints
+	shape := options.Shape
+	if shape == (sudoku.Shape{}) {
+		shape = sudoku.StandardShape
+	}
+
+	findPuzzleTemplateSql := "select id, seed, strategy, shape, constraints, board from puzzle_templates where seed = ? and strategy = ? and shape = ? and constraints = ?;"
+	err := db.QueryRow(findPuzzleTemplateSql, options.Seed, options.Strategy, shape.String(), sudoku.ConstraintNames(options.Constraints)).Scan(
 		&puzzleTemplate.ID,
 		&puzzleTemplate.Seed,
+		&puzzleTemplate.Strategy,
+		&puzzleTemplate.Shape,
+		&puzzleTemplate.Constraints,
 		&puzzleTemplate.Board,
 	)
 
@@ -146,12 +180,20 @@ func findOrCreateSolution(db *sql.DB, options Options) (sudoku.Puzzle, int64, bo
 
 	if notFound {
 		// generate a new puzzle with this seed
-		puzzle := solveEmptyPuzzle(options)
-		id := recordPuzzleTemplate(db, puzzle, options.Seed)
+		puzzle, err := solver.SolveEmpty(options)
+		if err != nil {
+			return sudoku.Puzzle{}, -1, new, err
+		}
+		id := recordPuzzleTemplate(db, puzzle, options.Seed, options.Strategy)
 
 		return puzzle, id, new, nil
 	} else {
-		return hydratePuzzle(puzzleTemplate.Board), int64(puzzleTemplate.ID), !new, nil
+		puzzle, err := puzzleTemplate.Puzzle()
+		if err != nil {
+			return sudoku.Puzzle{}, -1, !new, err
+		}
+
+		return puzzle, int64(puzzleTemplate.ID), !new, nil
 	}
 }
 
@@ -161,6 +203,8 @@ func main() {
 		Short: "Randomly solve an empty board",
 		Long:  `Generate a randomly-seeded puzzle that is fully solved`,
 		Run: func(cmd *cobra.Command, args []string) {
+			strategy := strategyFromFlags(cmd)
+
 			db := setupDatabase()
 			defer db.Close()
 
@@ -175,7 +219,10 @@ func main() {
 				seedFromFlag = &seed
 			}
 
-			options := NewOptions(false, FindFirst, Shuffled, seedFromFlag)
+			options := solver.NewOptions(false, solver.FindFirst, solver.Shuffled, seedFromFlag)
+			options.Strategy = strategy
+			options.Shape = shapeFromFlags(cmd)
+			options.Constraints = constraintsFromFlags(cmd)
 
 			puzzle, id, new, err := findOrCreateSolution(db, options)
 			if err != nil {
@@ -184,426 +231,709 @@ func main() {
 			}
 
 			if new {
-				fmt.Printf("Generated new solution with seed %d\n", options.Seed)
+				fmt.Printf("Generated new solution with seed %d (%s)\n", options.Seed, options.Strategy)
 				printPuzzle(puzzle)
 				fmt.Printf("Inserted row in puzzle_templates, id: %d\n", id)
 			} else {
-				fmt.Printf("Found existing solution with seed %d\n", options.Seed)
+				fmt.Printf("Found existing solution with seed %d (%s)\n", options.Seed, options.Strategy)
 				printPuzzle(puzzle)
 				fmt.Printf("Existing row in puzzle_templates, id: %d\n", id)
 			}
 		},
 	}
+	cmdGenerate := &cobra.Command{
+		Use:   "generate",
+		Short: "Generate a playable puzzle from a solved board",
+		Long:  `Remove clues from a puzzle template while keeping exactly one solution`,
+		Run: func(cmd *cobra.Command, args []string) {
+			db := setupDatabase()
+			defer db.Close()
+
+			var solution sudoku.Puzzle
+			var templateId int64
+			var seed int64
+
+			if cmd.Flags().Changed("template-id") {
+				id, err := cmd.Flags().GetInt64("template-id")
+				if err != nil {
+					fmt.Println("Template ID flag is missing from `cmdFlags()`")
+					os.Exit(1)
+				}
+
+				puzzleTemplate, err := findPuzzleTemplateById(db, id)
+				if err == sql.ErrNoRows {
+					fmt.Printf("No puzzle template found with id %d\n", id)
+					os.Exit(1)
+				} else if err != nil {
+					fmt.Printf("Error during findPuzzleTemplateById: %v\n", err)
+					os.Exit(1)
+				}
+
+				solution, err = puzzleTemplate.Puzzle()
+				if err != nil {
+					fmt.Printf("Error reading puzzle template %d: %v\n", id, err)
+					os.Exit(1)
+				}
+				templateId = int64(puzzleTemplate.ID)
+				seed = puzzleTemplate.Seed
+			} else {
+				var seedFromFlag *int64
+				if cmd.Flags().Changed("seed") {
+					seed, err := cmd.Flags().GetInt64("seed")
+					if err != nil {
+						fmt.Println("Seed flag is missing from `cmdFlags()`")
+						os.Exit(1)
+					}
+
+					seedFromFlag = &seed
+				}
+
+				options := solver.NewOptions(false, solver.FindFirst, solver.Shuffled, seedFromFlag)
+				options.Shape = shapeFromFlags(cmd)
+				options.Constraints = constraintsFromFlags(cmd)
+
+				puzzle, id, _, err := findOrCreateSolution(db, options)
+				if err != nil {
+					fmt.Printf("Error during findOrCreateSolution: %v\n", err)
+					os.Exit(1)
+				}
+
+				solution = puzzle
+				templateId = id
+				seed = options.Seed
+			}
+
+			// seed the clue removal separately from the template generation so
+			// that the same seed always produces the same puzzle, whether or
+			// not the template already existed
+			options := solver.NewOptions(false, solver.FindFirst, solver.Shuffled, &seed)
+
+			if cmd.Flags().Changed("difficulty") {
+				difficultyFlag, err := cmd.Flags().GetString("difficulty")
+				if err != nil {
+					fmt.Println("Difficulty flag is missing from `cmdFlags()`")
+					os.Exit(1)
+				}
+
+				difficulty, err := sudoku.ParseDifficulty(difficultyFlag)
+				if err != nil {
+					fmt.Printf("Error parsing difficulty: %v\n", err)
+					os.Exit(1)
+				}
+
+				options.Difficulty = difficulty
+			}
+
+			options.Symmetry = symmetryFromFlags(cmd)
+
+			minimal, err := cmd.Flags().GetBool("minimal")
+			if err != nil {
+				fmt.Println("Minimal flag is missing from `cmdFlags()`")
+				os.Exit(1)
+			}
+			options.Minimal = minimal
+
+			generated, err := solver.Generate(solution, options)
+			if err != nil {
+				fmt.Printf("Error generating puzzle: %v\n", err)
+				os.Exit(1)
+			}
+
+			id, new, err := findOrRecordPuzzle(db, generated, templateId)
+			if err != nil {
+				fmt.Printf("Error during findOrRecordPuzzle: %v\n", err)
+				os.Exit(1)
+			}
+
+			puzzle := generated.Puzzle
+			fmt.Printf("Generated puzzle from template %d (seed %d) with %d clues\n", templateId, seed, solver.CountClues(puzzle))
+			fmt.Printf("Rated %.1f (%s), attempts: %d\n", generated.Grade.Rating, generated.Grade.Difficulty, generated.Attempts)
+			printPuzzle(puzzle)
+			if new {
+				fmt.Printf("Inserted row in puzzles, id: %d\n", id)
+			} else {
+				fmt.Printf("Existing row in puzzles, id: %d\n", id)
+			}
+		},
+	}
+	cmdExportCNF := &cobra.Command{
+		Use:   "export-cnf [puzzle file]",
+		Short: "Export the given Sudoku puzzle as DIMACS CNF",
+		Long:  `Encode a sudoku puzzle and its clues as a SAT problem in the DIMACS CNF format`,
+		Run: func(cmd *cobra.Command, args []string) {
+			reader := openPuzzleReader(args, "export")
+			scanner := bufio.NewScanner(reader)
+			puzzle := readInPuzzle(scanner)
+
+			err := solver.Validate(puzzle)
+			if err != nil {
+				fmt.Println(err.Error())
+				os.Exit(1)
+			}
+
+			cnf := solver.EncodeCNF(puzzle)
+			err = cnf.WriteDIMACS(os.Stdout)
+			if err != nil {
+				fmt.Printf("Error writing CNF: %v\n", err)
+				os.Exit(1)
+			}
+		},
+	}
+	cmdGrade := &cobra.Command{
+		Use:   "grade [puzzle file]",
+		Short: "Rate the difficulty of the given Sudoku puzzle",
+		Long:  `Rate a sudoku puzzle by the logical techniques it takes to solve, on a scale like Sudoku Explainer's`,
+		Run: func(cmd *cobra.Command, args []string) {
+			reader := openPuzzleReader(args, "grade")
+			scanner := bufio.NewScanner(reader)
+			puzzle := readInPuzzle(scanner)
+
+			unique, err := solver.HasUniqueSolution(puzzle)
+			if err != nil {
+				fmt.Println(err.Error())
+				os.Exit(1)
+			}
+
+			if !unique {
+				fmt.Println("Puzzle does not have a unique solution, it can't be graded")
+				os.Exit(1)
+			}
+
+			grade, err := sudoku.GradePuzzle(puzzle)
+			if err != nil {
+				fmt.Printf("Unable to grade puzzle, %v\n", err)
+				os.Exit(1)
+			}
+
+			printGrade(grade)
+		},
+	}
+	cmdCheckMinimal := &cobra.Command{
+		Use:   "check-minimal [puzzle file]",
+		Short: "Report the redundant clues of the given Sudoku puzzle",
+		Long:  `Check whether every clue of a sudoku puzzle is needed for it to have a unique solution`,
+		Run: func(cmd *cobra.Command, args []string) {
+			reader := openPuzzleReader(args, "check")
+			scanner := bufio.NewScanner(reader)
+			puzzle := readInPuzzle(scanner)
+
+			unique, err := solver.HasUniqueSolution(puzzle)
+			if err != nil {
+				fmt.Println(err.Error())
+				os.Exit(1)
+			}
+
+			if !unique {
+				fmt.Println("Puzzle does not have a unique solution, so it can't be minimal")
+				os.Exit(1)
+			}
+
+			printRedundantClues(puzzle, solver.RedundantClues(puzzle))
+		},
+	}
 	cmdSolve := &cobra.Command{
 		Use:   "solve [puzzle file]",
 		Short: "Solve the given Sudoku puzzle",
 		Long:  `A sudoku puzzle given to stdin will be validated and solved`,
 		Run: func(cmd *cobra.Command, args []string) {
-			debug, err := cmd.Flags().GetBool("debug")
+			reader := openPuzzleReader(args, "solve")
+			contents := readInContents(bufio.NewScanner(reader))
+
+			options := searchOptionsFromFlags(cmd)
+			explain, err := cmd.Flags().GetBool("explain")
 			if err != nil {
-				fmt.Println("Debug flag is missing from `cmdFlags()`")
+				fmt.Println("Explain flag is missing from `cmdFlags()`")
 				os.Exit(1)
 			}
 
-			var reader io.Reader
-			if len(args) > 0 {
-				// read the puzzle from the given file
-				file, err := os.Open(args[0])
+			disabled, err := cmd.Flags().GetStringSlice("disable-techniques")
+			if err != nil {
+				fmt.Println("Disable techniques flag is missing from `cmdFlags()`")
+				os.Exit(1)
+			}
+			disabledTechniques := []sudoku.Technique{}
+			for _, name := range disabled {
+				technique, err := sudoku.ParseTechnique(name)
 				if err != nil {
-					fmt.Printf("Unable to read file: %s\n", args[0])
+					fmt.Println(err.Error())
 					os.Exit(1)
 				}
+				disabledTechniques = append(disabledTechniques, technique)
+			}
 
-				reader = file
-			} else {
-				file, err := os.Stdin.Stat()
+			// the logic solver that explains the puzzle, if it's to be
+			// explained
+			var explainer *sudoku.LogicSolver
+			if explain {
+				logicSolver := sudoku.NewLogicSolver().Without(disabledTechniques...)
+				explainer = &logicSolver
+			}
+
+			workers, err := cmd.Flags().GetInt("workers")
+			if err != nil {
+				fmt.Println("Workers flag is missing from `cmdFlags()`")
+				os.Exit(1)
+			}
+			if workers < 1 {
+				fmt.Printf("Unable to solve with %d workers, there has to be at least 1\n", workers)
+				os.Exit(1)
+			}
+			options.Workers = workers
+			if err := checkSolverOptions(options); err != nil {
+				fmt.Println(err.Error())
+				os.Exit(1)
+			}
+
+			if solver.IsGattai(contents) {
+				// a gattai puzzle is searched by a single worker that always
+				// branches on the cell with the fewest candidates
+				if options.Solver != solver.Backtracking || explain || workers > 1 || cmd.Flags().Changed("strategy") {
+					fmt.Println("Gattai puzzles can only be solved with --solver backtracking and without --explain, --workers, or --strategy")
+					os.Exit(1)
+				}
+
+				gattai, err := solver.ParseGattai(contents)
 				if err != nil {
-					fmt.Printf("Error checking stdin: %v\n", err)
+					fmt.Printf("Error reading puzzle: %v\n", err)
 					os.Exit(1)
 				}
-				waitingForUserInput := (file.Mode() & os.ModeCharDevice) != 0
-
-				if waitingForUserInput {
-					fmt.Print("Enter a file name for puzzle to solve: ")
-					termInputScanner := bufio.NewScanner(os.Stdin)
-					var filename string
-					for termInputScanner.Scan() {
-						filename = termInputScanner.Text()
-						break
-					}
 
-					file, err := os.Open(filename)
-					if err != nil {
-						fmt.Printf("Unable to read file: %s\n", args[0])
-						os.Exit(1)
-					}
+				solveGattaiPuzzle(cmd.Context(), gattai, options)
+				return
+			}
 
-					reader = file
-				} else {
-					// input is being piped in from a file to stdin
-					reader = os.Stdin
+			puzzle, err := solver.Parse(contents)
+			if err != nil {
+				fmt.Printf("Error reading puzzle: %v\n", err)
+				os.Exit(1)
+			}
+			if cmd.Flags().Changed("box") {
+				puzzle.Shape = shapeFromFlags(cmd)
+			}
+			puzzle.Constraints = withConstraints(puzzle.Constraints, constraintsFromFlags(cmd))
+			if explain {
+				if err := sudoku.CheckLogicSupport(puzzle); err != nil {
+					fmt.Printf("Unable to explain puzzle, %v\n", err)
+					os.Exit(1)
 				}
 			}
+			solvePuzzle(cmd.Context(), puzzle, options, explainer)
+		},
+	}
+	cmdSolveBatch := &cobra.Command{
+		Use:   "solve-batch [collection file]",
+		Short: "Solve every puzzle of a collection file",
+		Long:  `Each line of the collection is a puzzle on a single line, followed by an optional name, and every puzzle is solved and reported on in turn`,
+		Run: func(cmd *cobra.Command, args []string) {
+			reader := openPuzzleReader(args, "solve")
+			options := searchOptionsFromFlags(cmd)
+			if err := checkSolverOptions(options); err != nil {
+				fmt.Println(err.Error())
+				os.Exit(1)
+			}
 
+			workers, err := cmd.Flags().GetInt("workers")
+			if err != nil {
+				fmt.Println("Workers flag is missing from `cmdFlags()`")
+				os.Exit(1)
+			}
+			if workers < 1 {
+				fmt.Printf("Unable to solve with %d workers, there has to be at least 1\n", workers)
+				os.Exit(1)
+			}
+
+			start := time.Now()
 			scanner := bufio.NewScanner(reader)
-			puzzle := readInPuzzle(scanner)
+			summary := solver.SolveCollection(cmd.Context(), scanner, options, workers, func(result solver.BatchResult) {
+				printBatchResult(result, options)
+			})
+			if err := scanner.Err(); err != nil {
+				fmt.Printf("Error reading collection: %v\n", err)
+				os.Exit(1)
+			}
 
-			options := NewOptions(debug, EnsureUnique, InOrder, nil)
-			solvePuzzle(puzzle, options)
+			printBatchSummary(summary, time.Since(start))
 		},
 	}
 	var Debug bool
 	var Seed int64
+	var Propagate bool
+	var Strategy string
+	var Solver string
+	var SATCommand string
+	var Explain bool
+	var DisabledTechniques []string
+	var TemplateId int64
+	var Difficulty string
+	var SymmetryType string
+	var Minimal bool
+	var Size int
+	var Box string
+	var Constraints []string
+	var Workers int
+	var Timeout time.Duration
+	var MaxNodes int
 	var rootCmd = &cobra.Command{Use: "go-sudoku"}
 	rootCmd.AddCommand(cmdSolve)
+	rootCmd.AddCommand(cmdSolveBatch)
 	rootCmd.AddCommand(cmdSolveEmpty)
+	rootCmd.AddCommand(cmdGenerate)
+	rootCmd.AddCommand(cmdExportCNF)
+	rootCmd.AddCommand(cmdGrade)
+	rootCmd.AddCommand(cmdCheckMinimal)
 	cmdSolveEmpty.PersistentFlags().Int64VarP(&Seed, "seed", "", -1, "deterministically seed generated puzzle")
+	cmdGenerate.PersistentFlags().Int64VarP(&Seed, "seed", "", -1, "seed of the puzzle template to generate from")
+	cmdGenerate.PersistentFlags().Int64VarP(&TemplateId, "template-id", "", -1, "id of the puzzle template to generate from")
+	cmdGenerate.PersistentFlags().StringVarP(&SymmetryType, "symmetry", "", string(solver.NoSymmetry), fmt.Sprintf("keep the clues symmetric, one of %v", solver.SymmetryTypes))
+	cmdGenerate.PersistentFlags().BoolVarP(&Minimal, "minimal", "", false, "generate a puzzle where no clue can be removed without losing uniqueness")
+	sizeUsage := "rows and columns of a board generated from scratch, e.g. 4, 6, 9, 12, 16, or 25"
+	boxUsage := "rows x columns of each sector, e.g. 2x3, the most square shape for --size if unset"
+	cmdSolveEmpty.PersistentFlags().IntVarP(&Size, "size", "", sudoku.StandardShape.Size(), sizeUsage)
+	cmdSolveEmpty.PersistentFlags().StringVarP(&Box, "box", "", "", boxUsage)
+	cmdGenerate.PersistentFlags().IntVarP(&Size, "size", "", sudoku.StandardShape.Size(), sizeUsage)
+	cmdGenerate.PersistentFlags().StringVarP(&Box, "box", "", "", boxUsage)
+	cmdSolve.PersistentFlags().StringVarP(&Box, "box", "", "", "rows x columns of each sector of the puzzle, e.g. 3x2, if not the most square shape for its size")
+	constraintsUsage := fmt.Sprintf("variant constraints the board has to follow, any of %v", sudoku.VariantNames)
+	cmdSolve.PersistentFlags().StringSliceVarP(&Constraints, "constraints", "", []string{}, constraintsUsage)
+	cmdSolveEmpty.PersistentFlags().StringSliceVarP(&Constraints, "constraints", "", []string{}, constraintsUsage)
+	cmdGenerate.PersistentFlags().StringSliceVarP(&Constraints, "constraints", "", []string{}, constraintsUsage)
+	cmdGenerate.PersistentFlags().StringVarP(&Difficulty, "difficulty", "", "", fmt.Sprintf("generate a puzzle graded at this difficulty, one of %v", sudoku.Difficulties))
+	cmdSolve.PersistentFlags().BoolVarP(&Propagate, "propagate", "", true, "fill in naked and hidden singles before each guess")
+	cmdSolveBatch.PersistentFlags().BoolVarP(&Propagate, "propagate", "", true, "fill in naked and hidden singles before each guess")
+	strategyUsage := fmt.Sprintf("how to pick the next empty cell, one of %v", solver.CellStrategies)
+	cmdSolve.PersistentFlags().StringVarP(&Strategy, "strategy", "", string(solver.FirstEmpty), strategyUsage)
+	cmdSolveEmpty.PersistentFlags().StringVarP(&Strategy, "strategy", "", string(solver.FirstEmpty), strategyUsage)
+	cmdSolveBatch.PersistentFlags().StringVarP(&Strategy, "strategy", "", string(solver.FirstEmpty), strategyUsage)
+	solverUsage := fmt.Sprintf("which solver to use, one of %v", solver.SolverTypes)
+	satCommandUsage := "external SAT solver to run with --solver sat instead of the built-in one"
+	cmdSolve.PersistentFlags().StringVarP(&Solver, "solver", "", string(solver.Backtracking), solverUsage)
+	cmdSolve.PersistentFlags().StringVarP(&SATCommand, "sat-command", "", "", satCommandUsage)
+	cmdSolveBatch.PersistentFlags().StringVarP(&Solver, "solver", "", string(solver.Backtracking), solverUsage)
+	cmdSolveBatch.PersistentFlags().StringVarP(&SATCommand, "sat-command", "", "", satCommandUsage)
+	timeoutUsage := "give up on a puzzle that takes longer than this to solve, e.g. 10s, no limit if unset"
+	maxNodesUsage := "give up on a puzzle once the search has visited this many nodes, no limit if unset"
+	cmdSolve.PersistentFlags().DurationVarP(&Timeout, "timeout", "", 0, timeoutUsage)
+	cmdSolve.PersistentFlags().IntVarP(&MaxNodes, "max-nodes", "", 0, maxNodesUsage)
+	cmdSolveBatch.PersistentFlags().DurationVarP(&Timeout, "timeout", "", 0, timeoutUsage)
+	cmdSolveBatch.PersistentFlags().IntVarP(&MaxNodes, "max-nodes", "", 0, maxNodesUsage)
+	cmdSolve.PersistentFlags().IntVarP(&Workers, "workers", "", 1, "how many goroutines the backtracking solver searches with, e.g. the number of cores")
+	cmdSolveBatch.PersistentFlags().IntVarP(&Workers, "workers", "", 1, "how many puzzles to solve at the same time, e.g. the number of cores")
+	cmdSolve.PersistentFlags().BoolVarP(&Explain, "explain", "", false, "solve step by step with logical techniques only and explain each step")
+	cmdSolve.PersistentFlags().StringSliceVarP(&DisabledTechniques, "disable-techniques", "", []string{}, "techniques for --explain to leave out, e.g. x-wing,swordfish")
 	rootCmd.PersistentFlags().BoolVarP(&Debug, "debug", "", false, "turns on debug mode, extra logging")
 	rootCmd.Execute()
 }
 
-func solveEmptyPuzzle(options Options) sudoku.Puzzle {
-	board := make([][]int, sudoku.GridSize)
-	for i := range sudoku.GridSize {
-		board[i] = make([]int, sudoku.GridSize)
+// The options for solving a puzzle the way `solve` does, from the flags that
+// pick the solver and how it searches.
+func searchOptionsFromFlags(cmd *cobra.Command) solver.Options {
+	debug, err := cmd.Flags().GetBool("debug")
+	if err != nil {
+		fmt.Println("Debug flag is missing from `cmdFlags()`")
+		os.Exit(1)
 	}
-	emptyPuzzle := sudoku.Puzzle{Board: board}
 
-	status, puzzle, _ := traversePuzzle(emptyPuzzle, 1, options, &Diagnostics{})
-
-	if status != Solved {
-		fmt.Println("Something went wrong with puzzle generation")
+	propagate, err := cmd.Flags().GetBool("propagate")
+	if err != nil {
+		fmt.Println("Propagate flag is missing from `cmdFlags()`")
 		os.Exit(1)
 	}
 
-	return puzzle
-}
-
-func solvePuzzle(puzzle sudoku.Puzzle, options Options) {
-	fmt.Println("Initial puzzle:")
-	printPuzzle(puzzle)
-
-	_, err := validatePuzzle(puzzle)
+	solverFlag, err := cmd.Flags().GetString("solver")
+	if err != nil {
+		fmt.Println("Solver flag is missing from `cmdFlags()`")
+		os.Exit(1)
+	}
+	solverType, err := solver.ParseSolverType(solverFlag)
 	if err != nil {
 		fmt.Println(err.Error())
-	} else {
-		fmt.Println("Puzzle is valid")
+		os.Exit(1)
 	}
 
-	status, puzzle, diagnostics := traversePuzzle(puzzle, 1, options, &Diagnostics{})
-
-	if status == Solved {
-		solvedPuzzle := hydratePuzzle(diagnostics.Solutions[0])
-		fmt.Println("Solved the puzzle:")
-		if diagnostics.SolutionsFound > 1 {
-			if options.TraversalType == EnsureUnique {
-				fmt.Printf("(this puzzle has at least %d solutions)\n", diagnostics.SolutionsFound)
-			} else {
-				fmt.Printf("(this puzzle has %d solutions)\n", diagnostics.SolutionsFound)
-			}
-		}
-		printPuzzle(solvedPuzzle)
-	} else {
-		fmt.Println("Unable to solve puzzle:")
-		printPuzzle(puzzle)
+	satCommand, err := cmd.Flags().GetString("sat-command")
+	if err != nil {
+		fmt.Println("SAT command flag is missing from `cmdFlags()`")
+		os.Exit(1)
 	}
 
-	if options.Debug {
-		fmt.Println("")
-		fmt.Println("Search Space Diagnostics:")
-		fmt.Printf("Nodes Visited: %d\n", diagnostics.NodeVisitCount)
-		fmt.Printf("Backtracks: %d\n", diagnostics.BacktrackCount)
-		fmt.Printf("Validity Checks: %d\n", diagnostics.ValidityCheckCount)
-		fmt.Printf("Solutions Found: %d\n", diagnostics.SolutionsFound)
+	timeout, err := cmd.Flags().GetDuration("timeout")
+	if err != nil {
+		fmt.Println("Timeout flag is missing from `cmdFlags()`")
+		os.Exit(1)
 	}
-}
 
-func validatePuzzle(puzzle sudoku.Puzzle) (bool, error) {
-	_, err := checkForInvalidValues(puzzle.CurrentBoard())
+	maxNodes, err := cmd.Flags().GetInt("max-nodes")
 	if err != nil {
-		// early exit
-		return false, err
+		fmt.Println("Max nodes flag is missing from `cmdFlags()`")
+		os.Exit(1)
 	}
 
-	// check each row
-	for rowIndex := range sudoku.GridSize {
-		_, err := areaHasDuplicate(puzzle.ValuesInRow(rowIndex), Row, rowIndex)
-		if err != nil {
-			return false, fmt.Errorf("Row check failed: %v", err)
-		}
-	}
+	options := solver.NewOptions(debug, solver.EnsureUnique, solver.InOrder, nil)
+	options.Strategy = strategyFromFlags(cmd)
+	options.Solver = solverType
+	options.SATCommand = satCommand
+	options.Propagate = propagate
+	options.Timeout = timeout
+	options.MaxNodes = maxNodes
 
-	// check each column
-	for columnIndex := range sudoku.GridSize {
-		_, err := areaHasDuplicate(puzzle.ValuesInColumn(columnIndex), Column, columnIndex)
-		if err != nil {
-			return false, fmt.Errorf("Column check failed: %v", err)
-		}
+	return options
+}
+
+// Only the backtracking solver picks the cell to branch on or searches with
+// more than one worker, the others would quietly ignore --strategy and
+// --workers.
+func checkSolverOptions(options solver.Options) error {
+	if options.Solver == solver.Backtracking {
+		return nil
 	}
 
-	// check each 3x3 sector
-	for sectorIndex := range sudoku.GridSize {
-		_, err := areaHasDuplicate(puzzle.ValuesInSector(sectorIndex), Sector, sectorIndex)
-		if err != nil {
-			return false, fmt.Errorf("Sector check failed: %v", err)
-		}
+	if options.Workers > 1 {
+		return fmt.Errorf("Only --solver backtracking can search with more than 1 worker, not --solver %s", options.Solver)
+	}
+	if options.Strategy != solver.FirstEmpty {
+		return fmt.Errorf("Only --solver backtracking picks cells with --strategy, not --solver %s", options.Solver)
 	}
 
-	return true, nil
+	return nil
 }
 
-type PuzzleStatus string
+func strategyFromFlags(cmd *cobra.Command) solver.CellStrategy {
+	value, err := cmd.Flags().GetString("strategy")
+	if err != nil {
+		fmt.Println("Strategy flag is missing from `cmdFlags()`")
+		os.Exit(1)
+	}
 
-const (
-	Invalid PuzzleStatus = "Invalid"
-	Valid   PuzzleStatus = "Valid"
-	Solved  PuzzleStatus = "Solved"
-)
+	strategy, err := solver.ParseCellStrategy(value)
+	if err != nil {
+		fmt.Println(err.Error())
+		os.Exit(1)
+	}
 
-type Diagnostics struct {
-	BacktrackCount     int
-	NodeVisitCount     int
-	ValidityCheckCount int
-	SolutionsFound     int
-	Solutions          []string
+	return strategy
 }
 
-func traversePuzzle(puzzle sudoku.Puzzle, level int, options Options, diagnostics *Diagnostics) (PuzzleStatus, sudoku.Puzzle, Diagnostics) {
-	// this is a recursive function, so:
-	// initial pass => puzzle should be Valid
-	// cell is filled in =>
-	// - if the value makes the puzzle invalid, Invalid
-	// - if the value is a valid value, Valid
-	// final cell is filled in =>
-	// - if the value makes the puzzle invalid, Invalid
-	// - if the value solves the puzzle, Solved
-	status := checkPuzzleStatus(puzzle)
-	(*diagnostics).ValidityCheckCount++
-
-	// max depth of the traversal is the number of cells on the board
-	// don't let the traversal exceed it
-	maxDepth := sudoku.GridSize*sudoku.GridSize + 1
-	if level > maxDepth {
-		panic(fmt.Sprintf("traversePuzzle:level has exceeded %d", maxDepth))
+func symmetryFromFlags(cmd *cobra.Command) solver.Symmetry {
+	value, err := cmd.Flags().GetString("symmetry")
+	if err != nil {
+		fmt.Println("Symmetry flag is missing from `cmdFlags()`")
+		os.Exit(1)
 	}
 
-	switch status {
-	case Solved:
-		// record solution in diagnostics
-		(*diagnostics).SolutionsFound++
-		(*diagnostics).Solutions = append((*diagnostics).Solutions, puzzle.String())
-
-		switch options.TraversalType {
-		case FindFirst:
-			return Solved, puzzle, *diagnostics
-		case EnsureUnique:
-			if (*diagnostics).SolutionsFound > 1 {
-				// essentially return early as soon as we've seen multiple solutions
-				return Solved, puzzle, *diagnostics
-			} else {
-				return Invalid, puzzle, *diagnostics
-			}
-		case FindAll:
-			return Invalid, puzzle, *diagnostics
-		default:
-			panic(fmt.Sprintf("Error: unrecognized options.TraversalType %s", options.TraversalType))
-		}
-	case Valid:
-		nextRow, nextCell, err := findNextEmptyCell(puzzle)
-		if err != nil {
-			panic(fmt.Sprintf("Shouldn't reach here for valid puzzle: %v", err))
-		}
+	symmetry, err := solver.ParseSymmetry(value)
+	if err != nil {
+		fmt.Println(err.Error())
+		os.Exit(1)
+	}
 
-		possibleValues := findPossibleValues(puzzle, nextRow, nextCell, options)
+	return symmetry
+}
 
-		// make another puzzle placement
-		for _, value := range possibleValues {
-			(*diagnostics).NodeVisitCount++
-			puzzle.PlaceValue(nextRow, nextCell, value)
+// The sector shape asked for with --box, or else the usual shape for --size.
+func shapeFromFlags(cmd *cobra.Command) sudoku.Shape {
+	size := sudoku.StandardShape.Size()
+	if cmd.Flags().Changed("size") {
+		value, err := cmd.Flags().GetInt("size")
+		if err != nil {
+			fmt.Println("Size flag is missing from `cmdFlags()`")
+			os.Exit(1)
+		}
 
-			if options.Debug {
-				fmt.Printf("%d) placing %d at (%d,%d) of %v\n", level, value, nextRow, nextCell, possibleValues)
-			}
+		size = value
+	}
 
-			latestStatus, latestPuzzle, _ := traversePuzzle(puzzle, level+1, options, diagnostics)
-			switch latestStatus {
-			case Solved:
-				return Solved, latestPuzzle, *diagnostics
-			case Invalid:
-				// undo latest placement, continue
-				(*diagnostics).BacktrackCount++
-				latestPuzzle.UndoLastPlacement()
-				continue
-			default:
-				// we shouldn't get here, something went wrong
-				panic("traversePuzzle returned an unrecognized status")
-			}
+	if cmd.Flags().Changed("box") {
+		box, err := cmd.Flags().GetString("box")
+		if err != nil {
+			fmt.Println("Box flag is missing from `cmdFlags()`")
+			os.Exit(1)
 		}
 
-		// if we haven't found a solution at this point, then we'll need to backtrack
-		// unless were at the top and some solution(s) have been found
-		if level == 1 && (*diagnostics).SolutionsFound > 0 {
-			return Solved, puzzle, *diagnostics
-		} else {
-			return Invalid, puzzle, *diagnostics
+		shape, err := sudoku.ParseShape(box)
+		if err != nil {
+			fmt.Println(err.Error())
+			os.Exit(1)
 		}
-	case Invalid:
-		if level == 1 && (*diagnostics).SolutionsFound > 0 {
-			return Solved, puzzle, *diagnostics
-		} else {
-			return Invalid, puzzle, *diagnostics
+
+		if cmd.Flags().Changed("size") && shape.Size() != size {
+			fmt.Printf("Box shape %s makes a %dx%d board, not %dx%d\n", shape, shape.Size(), shape.Size(), size, size)
+			os.Exit(1)
 		}
-	default:
-		panic("Should not have reached here when traversing puzzle")
+
+		return shape
 	}
-}
 
-func checkPuzzleStatus(puzzle sudoku.Puzzle) PuzzleStatus {
-	valid, err := validatePuzzle(puzzle)
+	shape, err := sudoku.ShapeForSize(size)
 	if err != nil {
-		return Invalid
+		fmt.Println(err.Error())
+		os.Exit(1)
 	}
 
-	if !valid {
-		return Invalid
-	} else {
-		_, _, err := findNextEmptyCell(puzzle)
-		if err != nil {
-			// we are valid, but there are no more empty cells
-			return Solved
-		} else {
-			return Valid
-		}
-	}
+	return shape
 }
 
-func findNextEmptyCell(puzzle sudoku.Puzzle) (int, int, error) {
-	currentBoard := puzzle.CurrentBoard()
+func constraintsFromFlags(cmd *cobra.Command) []sudoku.Constraint {
+	names, err := cmd.Flags().GetStringSlice("constraints")
+	if err != nil {
+		fmt.Println("Constraints flag is missing from `cmdFlags()`")
+		os.Exit(1)
+	}
 
-	for row := range sudoku.GridSize {
-		for cell := range sudoku.GridSize {
-			if currentBoard[row][cell] == 0 {
-				return row, cell, nil
-			}
-		}
+	constraints, err := sudoku.ParseConstraints(strings.Join(names, ","))
+	if err != nil {
+		fmt.Println(err.Error())
+		os.Exit(1)
 	}
 
-	return -1, -1, fmt.Errorf("No more empty cells in the puzzle")
+	return constraints
 }
 
-type Order string
+// The puzzle's constraints along with any others that it doesn't have yet,
+// e.g. ones from the command line for a puzzle file that already has some.
+func withConstraints(constraints []sudoku.Constraint, others []sudoku.Constraint) []sudoku.Constraint {
+	for _, other := range others {
+		found := slices.ContainsFunc(constraints, func(constraint sudoku.Constraint) bool {
+			return constraint.Name() == other.Name()
+		})
+		if !found {
+			constraints = append(constraints, other)
+		}
+	}
 
-const (
-	InOrder  Order = "InOrder"
-	Shuffled Order = "Shuffled"
-)
+	return constraints
+}
 
-func findPossibleValues(puzzle sudoku.Puzzle, row int, cell int, options Options) []int {
-	usedValues := make(map[int]int)
+func solvePuzzle(ctx context.Context, puzzle sudoku.Puzzle, options solver.Options, explainer *sudoku.LogicSolver) {
+	fmt.Println("Initial puzzle:")
+	printPuzzle(puzzle)
 
-	sectorNum := GetSectorNumberForCell(row, cell)
+	err := solver.Validate(puzzle)
+	if err != nil {
+		fmt.Println(err.Error())
+	} else {
+		fmt.Println("Puzzle is valid")
 
-	cellsConstrainingThisCell := slices.Concat(
-		puzzle.RowAt(row),
-		puzzle.ColumnAt(cell),
-		puzzle.SectorAt(sectorNum),
-	)
-	for _, rowEntry := range cellsConstrainingThisCell {
-		if rowEntry != 0 {
-			usedValues[rowEntry]++
+		if explainer != nil {
+			explainPuzzle(puzzle, *explainer)
 		}
 	}
 
-	unusedValues := []int{}
+	status, puzzle, diagnostics, err := solver.Solve(ctx, puzzle, options)
+	var validationErr *solver.ValidationError
+	if err != nil && !errors.As(err, &validationErr) {
+		fmt.Printf("Error running SAT solver: %v\n", err)
+		os.Exit(1)
+	}
 
-	for i := range sudoku.GridSize {
-		value := i + 1
-		if usedValues[value] == 0 {
-			unusedValues = append(unusedValues, value)
+	switch status {
+	case solver.Solved:
+		fmt.Println("Solved the puzzle:")
+		if diagnostics.SolutionsFound > 1 {
+			if options.TraversalType == solver.EnsureUnique {
+				fmt.Printf("(this puzzle has at least %d solutions)\n", diagnostics.SolutionsFound)
+			} else {
+				fmt.Printf("(this puzzle has %d solutions)\n", diagnostics.SolutionsFound)
+			}
 		}
+		printSolution(puzzle, diagnostics.Solutions[0])
+	case solver.Aborted:
+		fmt.Printf("Gave up on the puzzle after visiting %d nodes, %s\n", diagnostics.NodeVisitCount, abortReason(options, diagnostics))
+		if diagnostics.SolutionsFound > 0 {
+			fmt.Printf("(found %d solution(s) before giving up, there could be more)\n", diagnostics.SolutionsFound)
+			printSolution(puzzle, diagnostics.Solutions[0])
+		}
+	default:
+		fmt.Println("Unable to solve puzzle:")
+		printPuzzle(puzzle)
 	}
 
-	if options.SolveOrder == Shuffled {
-		Shuffle(unusedValues, options.Rng)
+	if options.Debug {
+		fmt.Println("")
+		fmt.Println("Search Space Diagnostics:")
+		fmt.Printf("Nodes Visited: %d\n", diagnostics.NodeVisitCount)
+		fmt.Printf("Backtracks: %d\n", diagnostics.BacktrackCount)
+		fmt.Printf("Validity Checks: %d\n", diagnostics.ValidityCheckCount)
+		if options.Propagate && options.Solver == solver.Backtracking {
+			fmt.Printf("Propagated Placements: %d (naked singles: %d, hidden singles: %d)\n",
+				diagnostics.PropagatedCount(), diagnostics.NakedSingleCount, diagnostics.HiddenSingleCount)
+		}
+		fmt.Printf("Solutions Found: %d\n", diagnostics.SolutionsFound)
 	}
-
-	return unusedValues
 }
 
-func listMissingValues(section []int) []int {
-	missingValues := []int{}
-
-	for i := range sudoku.GridSize {
-		val := i + 1
-		seen := false
-		for _, cell := range section {
-			if cell == val {
-				seen = true
-				break
-			}
-		}
-
-		if !seen {
-			missingValues = append(missingValues, val)
-		}
+// Why a search was aborted, from the limits it was given.
+func abortReason(options solver.Options, diagnostics solver.Diagnostics) string {
+	if options.MaxNodes > 0 && diagnostics.NodeVisitCount >= options.MaxNodes {
+		return "the most it can visit"
 	}
 
-	return missingValues
+	return "once it ran out of time"
 }
 
-func checkForInvalidValues(puzzle [][]int) (bool, error) {
-	for i, row := range puzzle {
-		for j, cell := range row {
-			if cell < 0 || cell > 9 {
-				err := fmt.Errorf("Validation check failed, value '%d' at (%d,%d) is not between 0 and 9", cell, i+1, j+1)
-				return false, err
-			}
-		}
+func explainPuzzle(puzzle sudoku.Puzzle, logicSolver sudoku.LogicSolver) {
+	result := logicSolver.Solve(puzzle)
+
+	fmt.Println("Logical steps:")
+	for i, step := range result.Steps {
+		fmt.Printf("%d. %s\n", i+1, step)
 	}
 
-	return true, nil
+	switch {
+	case result.Solved:
+		fmt.Printf("Solved with logic alone in %d steps\n", len(result.Steps))
+	case result.Contradiction:
+		fmt.Printf("Reached a contradiction after %d steps, the puzzle has no solution\n", len(result.Steps))
+	default:
+		fmt.Printf("Ran out of logical steps after %d steps, the rest of the puzzle needs trial and error\n", len(result.Steps))
+	}
 }
 
-type Area string
-
-const (
-	Row    Area = "row"
-	Column Area = "column"
-	Sector Area = "sector"
-)
+func printGrade(grade sudoku.Grade) {
+	fmt.Printf("Rating: %.1f (%s)\n", grade.Rating, grade.Difficulty)
+	if !grade.Solved {
+		fmt.Printf("Ran out of logical steps after %d steps, the rest of the puzzle needs trial and error\n", grade.Steps)
+	}
+	if grade.Hardest != "" {
+		fmt.Printf("Hardest technique: %s\n", grade.Hardest)
+	}
 
-func areaHasDuplicate(cells []int, area Area, areaIndex int) (bool, error) {
-	dupeIndex := hasDuplicates(cells)
-	if dupeIndex >= 0 {
-		var err error
-		switch area {
-		case Row:
-			err = fmt.Errorf("Duplicate check failed, value '%d' in row %d, cell %d", cells[dupeIndex], areaIndex+1, dupeIndex+1)
-		case Column:
-			err = fmt.Errorf("Duplicate check failed, value '%d' in column %d, cell %d", cells[dupeIndex], areaIndex+1, dupeIndex+1)
-		case Sector:
-			err = fmt.Errorf("Duplicate check failed, value '%d' in sector %d, cell %d", cells[dupeIndex], areaIndex+1, dupeIndex+1)
-		default:
-			panic(fmt.Sprintf("Unrecognized Area '%s' provided to areaHasDuplicate", area))
+	fmt.Println("Techniques used:")
+	for _, technique := range sudoku.AllTechniques {
+		count := grade.Counts[technique]
+		if count == 0 {
+			continue
 		}
-		return false, err
+		fmt.Printf("  %-20s %4.1f x%d\n", technique, sudoku.TechniqueRatings[technique], count)
 	}
-
-	return true, nil
 }
 
-func hasDuplicates(cells []int) int {
-	seen := make(map[int]bool)
-	for i, cell := range cells {
-		if seen[cell] {
-			return i
-		}
-		seen[cell] = true
+func printRedundantClues(puzzle sudoku.Puzzle, redundant []sudoku.Position) {
+	clues := solver.CountClues(puzzle)
+	if len(redundant) == 0 {
+		fmt.Printf("Puzzle is minimal, all %d clues are needed\n", clues)
+		return
 	}
 
-	return -1
+	fmt.Printf("Puzzle is not minimal, %d of its %d clues can each be removed without losing uniqueness:\n", len(redundant), clues)
+	for _, position := range redundant {
+		fmt.Printf("  %s (%s)\n", position, sudoku.Symbol(puzzle.ValueAt(position.Row, position.Cell)))
+	}
 }
 
 func printPuzzle(puzzle sudoku.Puzzle) {
 	fmt.Println(puzzle.PrettyString())
+	if len(puzzle.Constraints) > 0 {
+		fmt.Printf("Constraints: %s\n", sudoku.ConstraintList(puzzle.Constraints))
+	}
+}
+
+// Print the puzzle with one of the solutions from its diagnostics filled in.
+func printSolution(puzzle sudoku.Puzzle, solution string) {
+	solved, err := solver.SolutionOf(puzzle, solution)
+	if err != nil {
+		fmt.Printf("Error reading back the solution: %v\n", err)
+		os.Exit(1)
+	}
+
+	printPuzzle(solved)
 }
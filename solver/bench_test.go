@@ -0,0 +1,102 @@
+package solver
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func BenchmarkTraversePuzzle(b *testing.B) {
+	filenames, err := filepath.Glob("../samples/*.txt")
+	if err != nil {
+		panic(err)
+	}
+
+	for _, filename := range filenames {
+		contents, err := os.ReadFile(filename)
+		if err != nil {
+			panic(fmt.Sprintf("Unable to read file %s", filename))
+		}
+
+		if IsGattai(string(contents)) {
+			// gattai puzzles have their own solver, see SolveGattai
+			continue
+		}
+
+		puzzle := mustParse(string(contents))
+		options := NewOptions(false, FindAll, InOrder, nil)
+		// plain backtracking takes far too long on a board bigger than 9x9,
+		// so those only run with the other solvers, and propagation starts
+		// from the cell with the fewest candidates
+		bigBoard := puzzle.Size() > 9
+
+		b.Run(filepath.Base(filename), func(b *testing.B) {
+			if bigBoard {
+				b.Skip("plain backtracking takes far too long on a board this big")
+			}
+			for range b.N {
+				traversePuzzle(puzzle, 1, options, &Diagnostics{})
+			}
+		})
+
+		dlxOptions := NewOptions(false, FindAll, InOrder, nil)
+		dlxOptions.Solver = DLX
+
+		b.Run(filepath.Base(filename)+"/dlx", func(b *testing.B) {
+			for range b.N {
+				Solve(context.Background(), puzzle, dlxOptions)
+			}
+		})
+
+		satOptions := NewOptions(false, FindAll, InOrder, nil)
+		satOptions.Solver = SAT
+
+		b.Run(filepath.Base(filename)+"/sat", func(b *testing.B) {
+			for range b.N {
+				Solve(context.Background(), puzzle, satOptions)
+			}
+		})
+
+		parallelOptions := NewOptions(false, FindAll, InOrder, nil)
+		parallelOptions.Workers = 4
+
+		b.Run(filepath.Base(filename)+"/parallel", func(b *testing.B) {
+			if bigBoard {
+				b.Skip("plain backtracking takes far too long on a board this big")
+			}
+			for range b.N {
+				Solve(context.Background(), puzzle, parallelOptions)
+			}
+		})
+
+		propagateOptions := NewOptions(false, FindAll, InOrder, nil)
+		propagateOptions.Propagate = true
+		if bigBoard {
+			propagateOptions.Strategy = FewestCandidates
+		}
+
+		b.Run(filepath.Base(filename)+"/propagate", func(b *testing.B) {
+			for range b.N {
+				traversePuzzle(puzzle, 1, propagateOptions, &Diagnostics{})
+			}
+		})
+	}
+}
+
+func BenchmarkSolveEmptyPuzzle(b *testing.B) {
+	seed := int64(42)
+	for range b.N {
+		SolveEmpty(NewOptions(false, FindFirst, Shuffled, &seed))
+	}
+}
+
+func BenchmarkGeneratePuzzle(b *testing.B) {
+	seed := int64(42)
+	solution := mustSolveEmpty(NewOptions(false, FindFirst, Shuffled, &seed))
+	for range b.N {
+		generatePuzzle(solution, NoSymmetry, rand.New(rand.NewSource(seed)))
+	}
+}
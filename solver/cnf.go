@@ -0,0 +1,351 @@
+package solver
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/jbranchaud/go-sudoku/sudoku"
+)
+
+// A boolean formula in conjunctive normal form. Variables are numbered from 1
+// and a literal is a variable (true) or its negation (false), as in the
+// DIMACS format.
+type CNF struct {
+	VariableCount int
+	Clauses       [][]int
+	Comments      []string
+}
+
+// There is one variable per candidate placement, it is true when the value
+// is placed in the cell at (row, cell).
+func cnfVariable(size int, row int, cell int, value int) int {
+	return dlxPlacementID(size, row, cell, value) + 1
+}
+
+func cnfPlacementFromVariable(size int, variable int) (int, int, int) {
+	return dlxPlacementFromID(size, variable-1)
+}
+
+// Every cell has exactly one value, every row, column, and sector contains
+// every value exactly once, and every clue is a unit clause. A Killer Sudoku
+// also needs its cages, see encodeCages, and a variant its constraints, see
+// encodeConstraints.
+func EncodeCNF(puzzle sudoku.Puzzle) CNF {
+	layout := puzzle.Layout()
+	size := layout.Size()
+	cnf := CNF{
+		VariableCount: size * size * size,
+		Comments: []string{
+			"go-sudoku puzzle encoded as CNF",
+			fmt.Sprintf("variable (row*%d + cell)*%d + value is true when value is placed at (row, cell), counting rows and cells from 0", size, size),
+		},
+	}
+
+	exactlyOne := func(variables []int) {
+		cnf.Clauses = append(cnf.Clauses, variables)
+		cnf.atMostOne(variables)
+	}
+
+	for row := range size {
+		for cell := range size {
+			variables := []int{}
+			for value := 1; value <= size; value++ {
+				variables = append(variables, cnfVariable(size, row, cell, value))
+			}
+			exactlyOne(variables)
+		}
+	}
+
+	for _, unit := range layout.Units() {
+		for value := 1; value <= size; value++ {
+			variables := []int{}
+			for _, position := range unit {
+				variables = append(variables, cnfVariable(size, position.Row, position.Cell, value))
+			}
+			exactlyOne(variables)
+		}
+	}
+
+	for row := range size {
+		for cell := range size {
+			value := puzzle.ValueAt(row, cell)
+			if value != 0 {
+				cnf.Clauses = append(cnf.Clauses, []int{cnfVariable(size, row, cell, value)})
+			}
+		}
+	}
+
+	if len(puzzle.Cages) > 0 {
+		cnf.encodeCages(puzzle.Cages, size)
+	}
+	if len(puzzle.Constraints) > 0 {
+		cnf.encodeConstraints(puzzle)
+	}
+
+	return cnf
+}
+
+func (cnf *CNF) atMostOne(variables []int) {
+	for i := range variables {
+		for j := i + 1; j < len(variables); j++ {
+			cnf.Clauses = append(cnf.Clauses, []int{-variables[i], -variables[j]})
+		}
+	}
+}
+
+// No value repeats in a cage, and the cage holds one of the sets of values
+// that add up to its sum. Each of those sets gets a variable of its own,
+// numbered after the placements, which when true requires every value of
+// the set to be somewhere in the cage. With no repeats and as many values as
+// cells, that leaves exactly the set's values.
+func (cnf *CNF) encodeCages(cages []sudoku.Cage, size int) {
+	cnf.Comments = append(cnf.Comments, fmt.Sprintf("variables after %d pick which set of values fills each killer cage", size*size*size))
+
+	for _, cage := range cages {
+		combinations := sudoku.CageCombinations(cage, size)
+
+		var possible sudoku.CandidateMask
+		for _, combination := range combinations {
+			possible |= combination
+		}
+
+		for value := 1; value <= size; value++ {
+			variables := []int{}
+			for _, position := range cage.Cells {
+				variables = append(variables, cnfVariable(size, position.Row, position.Cell, value))
+			}
+			cnf.atMostOne(variables)
+
+			if !possible.Has(value) {
+				// no way of adding up to the sum uses the value at all
+				for _, variable := range variables {
+					cnf.Clauses = append(cnf.Clauses, []int{-variable})
+				}
+			}
+		}
+
+		someCombination := []int{}
+		for _, combination := range combinations {
+			cnf.VariableCount++
+			combinationVariable := cnf.VariableCount
+			someCombination = append(someCombination, combinationVariable)
+
+			for _, value := range combination.Values() {
+				clause := []int{-combinationVariable}
+				for _, position := range cage.Cells {
+					clause = append(clause, cnfVariable(size, position.Row, position.Cell, value))
+				}
+				cnf.Clauses = append(cnf.Clauses, clause)
+			}
+		}
+		cnf.Clauses = append(cnf.Clauses, someCombination)
+	}
+}
+
+// Most variant constraints rule out pairs of placements, a value in one cell
+// and a value in another, e.g. the same value a knight's move apart, or a
+// smaller value further up a thermometer. The pairs are found by placing each
+// value on an empty board in turn and seeing which candidates the constraint
+// prunes from the other cells, and each one becomes a clause that the two
+// placements can't both be made. An arrow's sum takes all of its cells at
+// once, see encodeArrow.
+func (cnf *CNF) encodeConstraints(puzzle sudoku.Puzzle) {
+	layout := puzzle.Layout()
+	size := layout.Size()
+	probe := EmptyPuzzle(layout.Shape)
+	probe.Regions = puzzle.Regions
+
+	type conflict struct{ a, b int }
+	seen := map[conflict]bool{}
+
+	for _, constraint := range puzzle.Constraints {
+		if arrow, ok := constraint.(sudoku.Arrow); ok {
+			cnf.encodeArrow(arrow, size)
+			continue
+		}
+
+		cells := constraint.Cells(layout)
+		for _, a := range cells {
+			for value := 1; value <= size; value++ {
+				probe.PlaceValue(a.Row, a.Cell, value)
+				placed := cnfVariable(size, a.Row, a.Cell, value)
+
+				for _, b := range cells {
+					if b == a {
+						continue
+					}
+
+					allowed := constraint.Prune(&probe, b.Row, b.Cell, layout.AllValuesMask())
+					for other := 1; other <= size; other++ {
+						if allowed.Has(other) {
+							continue
+						}
+
+						ruledOut := cnfVariable(size, b.Row, b.Cell, other)
+						pair := conflict{min(placed, ruledOut), max(placed, ruledOut)}
+						if !seen[pair] {
+							seen[pair] = true
+							cnf.Clauses = append(cnf.Clauses, []int{-placed, -ruledOut})
+						}
+					}
+				}
+
+				probe.UndoLastPlacement()
+			}
+		}
+	}
+}
+
+// Every way of filling in the arrow's path rules out all but one value in its
+// circle, its sum, so each one becomes a clause that those placements mean
+// the sum goes in the circle. Ways that add up to more than fits in the
+// circle are cut off as soon as they get there, with a clause that rules out
+// the placements so far.
+func (cnf *CNF) encodeArrow(arrow sudoku.Arrow, size int) {
+	var fill func(index int, total int, placements []int)
+	fill = func(index int, total int, placements []int) {
+		clause := []int{}
+		for _, placement := range placements {
+			clause = append(clause, -placement)
+		}
+
+		// even a 1 in each of the cells that are left would go over
+		if total+len(arrow.Path)-index > size {
+			cnf.Clauses = append(cnf.Clauses, clause)
+			return
+		}
+		if index == len(arrow.Path) {
+			circle := cnfVariable(size, arrow.Circle.Row, arrow.Circle.Cell, total)
+			cnf.Clauses = append(cnf.Clauses, append(clause, circle))
+			return
+		}
+
+		position := arrow.Path[index]
+		for value := 1; value <= size; value++ {
+			placement := cnfVariable(size, position.Row, position.Cell, value)
+			fill(index+1, total+value, append(placements[:index:index], placement))
+		}
+	}
+	fill(0, 0, []int{})
+}
+
+// Read a satisfying assignment back into the puzzle, placing every value whose
+// variable is true in a cell that isn't filled in yet. Any variables after the
+// placements, like a killer cage's, are left out.
+func puzzleFromModel(puzzle sudoku.Puzzle, model []bool) sudoku.Puzzle {
+	solved := puzzle.Clone()
+	size := solved.Size()
+
+	for variable := 1; variable < len(model) && variable <= size*size*size; variable++ {
+		if !model[variable] {
+			continue
+		}
+
+		row, cell, value := cnfPlacementFromVariable(size, variable)
+		if solved.ValueAt(row, cell) == 0 {
+			solved.PlaceValue(row, cell, value)
+		}
+	}
+
+	return solved
+}
+
+func (cnf CNF) WriteDIMACS(writer io.Writer) error {
+	buffered := bufio.NewWriter(writer)
+
+	for _, comment := range cnf.Comments {
+		fmt.Fprintf(buffered, "c %s\n", comment)
+	}
+	fmt.Fprintf(buffered, "p cnf %d %d\n", cnf.VariableCount, len(cnf.Clauses))
+
+	for _, clause := range cnf.Clauses {
+		for _, literal := range clause {
+			buffered.WriteString(strconv.Itoa(literal))
+			buffered.WriteString(" ")
+		}
+		buffered.WriteString("0\n")
+	}
+
+	return buffered.Flush()
+}
+
+func ParseDIMACS(reader io.Reader) (CNF, error) {
+	cnf := CNF{}
+	clause := []int{}
+	seenHeader := false
+	expectedClauses := 0
+
+	scanner := bufio.NewScanner(reader)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+
+		switch {
+		case line == "" || line == "%":
+			continue
+		case strings.HasPrefix(line, "c"):
+			cnf.Comments = append(cnf.Comments, strings.TrimSpace(strings.TrimPrefix(line, "c")))
+			continue
+		case strings.HasPrefix(line, "p"):
+			fields := strings.Fields(line)
+			if len(fields) != 4 || fields[1] != "cnf" {
+				return CNF{}, fmt.Errorf("Line %d: expected a `p cnf <variables> <clauses>` header, got '%s'", lineNumber, line)
+			}
+
+			variables, err := strconv.Atoi(fields[2])
+			if err != nil {
+				return CNF{}, fmt.Errorf("Line %d: invalid variable count '%s'", lineNumber, fields[2])
+			}
+			clauses, err := strconv.Atoi(fields[3])
+			if err != nil {
+				return CNF{}, fmt.Errorf("Line %d: invalid clause count '%s'", lineNumber, fields[3])
+			}
+
+			cnf.VariableCount = variables
+			expectedClauses = clauses
+			seenHeader = true
+			continue
+		}
+
+		if !seenHeader {
+			return CNF{}, fmt.Errorf("Line %d: clause found before the `p cnf` header", lineNumber)
+		}
+
+		for _, field := range strings.Fields(line) {
+			literal, err := strconv.Atoi(field)
+			if err != nil {
+				return CNF{}, fmt.Errorf("Line %d: invalid literal '%s'", lineNumber, field)
+			}
+
+			if literal == 0 {
+				cnf.Clauses = append(cnf.Clauses, clause)
+				clause = []int{}
+				continue
+			}
+
+			if literal > cnf.VariableCount || -literal > cnf.VariableCount {
+				return CNF{}, fmt.Errorf("Line %d: literal %d is out of range for %d variables", lineNumber, literal, cnf.VariableCount)
+			}
+			clause = append(clause, literal)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return CNF{}, err
+	}
+
+	if len(clause) > 0 {
+		// the final clause doesn't need a terminating 0
+		cnf.Clauses = append(cnf.Clauses, clause)
+	}
+
+	if !seenHeader {
+		return CNF{}, fmt.Errorf("Missing `p cnf` header")
+	}
+	if len(cnf.Clauses) != expectedClauses {
+		return CNF{}, fmt.Errorf("Expected %d clauses, found %d", expectedClauses, len(cnf.Clauses))
+	}
+
+	return cnf, nil
+}
@@ -0,0 +1,130 @@
+package solver
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncodePuzzleAsCNF(t *testing.T) {
+	contents, err := os.ReadFile("../samples/001.txt")
+	if err != nil {
+		panic("Unable to read file ../samples/001.txt")
+	}
+	puzzle := mustParse(string(contents))
+
+	cnf := EncodeCNF(puzzle)
+
+	clues := 81 - puzzle.EmptyCellCount()
+	// exactly one value per cell, and exactly one of each value per row,
+	// column, and sector, is one 9-literal clause and 36 pairs each
+	assert.Equal(t, 729, cnf.VariableCount)
+	assert.Equal(t, 4*81*(1+36)+clues, len(cnf.Clauses))
+	assert.Contains(t, cnf.Clauses, []int{cnfVariable(9, 0, 4, 8)})
+}
+
+func TestEncodeKillerCagesAsCNF(t *testing.T) {
+	puzzle := mustParse("0000\n0000\n0000\n0000\n\naa..\n....\n....\n....\na=5\n")
+
+	cnf := EncodeCNF(puzzle)
+
+	// 5 is 1+4 or 2+3, a variable for each of the two sets
+	assert.Equal(t, 64+2, cnf.VariableCount)
+	assert.Contains(t, cnf.Clauses, []int{65, 66})
+	assert.Contains(t, cnf.Clauses, []int{-65, cnfVariable(4, 0, 0, 1), cnfVariable(4, 0, 1, 1)})
+	assert.Contains(t, cnf.Clauses, []int{-66, cnfVariable(4, 0, 0, 3), cnfVariable(4, 0, 1, 3)})
+
+	model := make([]bool, cnf.VariableCount+1)
+	model[cnfVariable(4, 0, 0, 2)] = true
+	model[65] = true
+	solved := puzzleFromModel(puzzle, model)
+	assert.Equal(t, 2, solved.ValueAt(0, 0))
+	assert.Len(t, solved.Solution, 1)
+}
+
+func TestEncodeConstraintsAsCNF(t *testing.T) {
+	plain := EncodeCNF(mustParse("0000\n0000\n0000\n0000\n"))
+	puzzle := mustParse("0000\n0000\n0000\n0000\n\nconstraints: anti-king\n")
+
+	cnf := EncodeCNF(puzzle)
+
+	// the same value can't be in r1c1 and r2c2, which the boxes already rule
+	// out, nor in r2c2 and r3c3, which only anti-king does
+	assert.Equal(t, plain.VariableCount, cnf.VariableCount)
+	assert.Contains(t, cnf.Clauses, []int{-cnfVariable(4, 0, 0, 3), -cnfVariable(4, 1, 1, 3)})
+	assert.Contains(t, cnf.Clauses, []int{-cnfVariable(4, 1, 1, 3), -cnfVariable(4, 2, 2, 3)})
+	assert.NotContains(t, cnf.Clauses, []int{-cnfVariable(4, 2, 2, 3), -cnfVariable(4, 1, 1, 3)})
+	assert.NotContains(t, cnf.Clauses, []int{-cnfVariable(4, 1, 1, 3), -cnfVariable(4, 2, 2, 4)})
+	assert.Greater(t, len(cnf.Clauses), len(plain.Clauses))
+}
+
+func TestEncodeArrowAsCNF(t *testing.T) {
+	puzzle := mustParse("0000\n0000\n0000\n0000\n\narrow: r1c1 r2c1 r3c1\n")
+
+	cnf := EncodeCNF(puzzle)
+
+	// a 1 and a 2 on the arrow put a 3 in the circle, a 2 and a 3 go over
+	// what fits in it, and so does a 4 before the second cell is filled in
+	assert.Contains(t, cnf.Clauses, []int{-cnfVariable(4, 1, 0, 1), -cnfVariable(4, 2, 0, 2), cnfVariable(4, 0, 0, 3)})
+	assert.Contains(t, cnf.Clauses, []int{-cnfVariable(4, 1, 0, 2), -cnfVariable(4, 2, 0, 3)})
+	assert.Contains(t, cnf.Clauses, []int{-cnfVariable(4, 1, 0, 4)})
+	assert.NotContains(t, cnf.Clauses, []int{-cnfVariable(4, 1, 0, 4), -cnfVariable(4, 2, 0, 1)})
+
+	result, err := DPLLSolver{}.Solve(context.Background(), cnf)
+	assert.NoError(t, err)
+	solved := puzzleFromModel(puzzle, result.Model)
+	assert.Equal(t, solved.ValueAt(0, 0), solved.ValueAt(1, 0)+solved.ValueAt(2, 0))
+}
+
+func TestDIMACSRoundTrip(t *testing.T) {
+	cnf := CNF{
+		VariableCount: 3,
+		Clauses:       [][]int{{1, -2}, {2, 3}, {-1}},
+		Comments:      []string{"a small example"},
+	}
+
+	var buffer bytes.Buffer
+	err := cnf.WriteDIMACS(&buffer)
+	assert.NoError(t, err)
+	assert.Equal(t, "c a small example\np cnf 3 3\n1 -2 0\n2 3 0\n-1 0\n", buffer.String())
+
+	parsed, err := ParseDIMACS(&buffer)
+	assert.NoError(t, err)
+	assert.Equal(t, cnf, parsed)
+}
+
+func TestParseDIMACSErrors(t *testing.T) {
+	tests := []struct {
+		name                  string
+		input                 string
+		expectedErrorContains string
+	}{
+		{
+			name:                  "missing header",
+			input:                 "1 2 0\n",
+			expectedErrorContains: "clause found before the `p cnf` header",
+		},
+		{
+			name:                  "literal out of range",
+			input:                 "p cnf 2 1\n1 3 0\n",
+			expectedErrorContains: "literal 3 is out of range",
+		},
+		{
+			name:                  "wrong clause count",
+			input:                 "p cnf 2 2\n1 2 0\n",
+			expectedErrorContains: "Expected 2 clauses, found 1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseDIMACS(strings.NewReader(tt.input))
+			assert.Error(t, err)
+			assert.Contains(t, err.Error(), tt.expectedErrorContains)
+		})
+	}
+}
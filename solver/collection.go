@@ -0,0 +1,203 @@
+package solver
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// A puzzle from a collection file, which has a puzzle per line written on a
+// single line, e.g. the 81 symbols of a 9x9 board as in the `.sdm` format.
+// Anything after the puzzle is its name, and blank lines and lines starting
+// with `#` are skipped.
+type CollectionEntry struct {
+	Line   int
+	Name   string
+	Puzzle string
+}
+
+// The name of the entry, or its line number if it doesn't have one.
+func (entry CollectionEntry) Label() string {
+	if entry.Name != "" {
+		return entry.Name
+	}
+
+	return fmt.Sprintf("line %d", entry.Line)
+}
+
+// Read a line of a collection file, which isn't an entry if it's blank or a
+// comment.
+func ParseCollectionLine(line string, lineNumber int) (CollectionEntry, bool) {
+	line = strings.TrimSpace(line)
+	if line == "" || strings.HasPrefix(line, "#") {
+		return CollectionEntry{}, false
+	}
+
+	puzzle, name := line, ""
+	if end := strings.IndexFunc(line, unicode.IsSpace); end != -1 {
+		puzzle = line[:end]
+		name = strings.TrimSpace(strings.TrimLeft(strings.TrimSpace(line[end:]), "#"))
+	}
+
+	return CollectionEntry{Line: lineNumber, Name: name, Puzzle: puzzle}, true
+}
+
+type BatchStatus string
+
+const (
+	BatchSolved     BatchStatus = "solved"
+	BatchMultiple   BatchStatus = "multiple solutions"
+	BatchNoSolution BatchStatus = "no solution"
+	BatchInvalid    BatchStatus = "invalid"
+	BatchFailed     BatchStatus = "failed"
+	BatchAborted    BatchStatus = "aborted"
+)
+
+type BatchResult struct {
+	Entry  CollectionEntry
+	Status BatchStatus
+	// why an invalid puzzle is invalid, or couldn't be read, or why the
+	// solver failed
+	Err         error
+	Diagnostics Diagnostics
+	// the debug output of solving the puzzle
+	Log string
+}
+
+// Solve a puzzle of a collection. A puzzle that can't be read or breaks the
+// rules is invalid, which is told apart from one that follows the rules but
+// has no solution.
+//
+// Nothing is shared with the solving of any other puzzle, so puzzles can be
+// solved at the same time. The options get a random number generator of
+// their own, seeded by the entry's line so that a puzzle is solved the same
+// way however many others are solved alongside it, and debug output goes into
+// the result rather than to stdout.
+func SolveEntry(ctx context.Context, entry CollectionEntry, options Options) BatchResult {
+	result := BatchResult{Entry: entry}
+
+	options = options.reseeded(options.Seed + int64(entry.Line))
+	var log strings.Builder
+	options.Log = &log
+
+	puzzle, err := Parse(entry.Puzzle)
+	if err == nil {
+		err = Validate(puzzle)
+	}
+	if err != nil {
+		result.Status = BatchInvalid
+		result.Err = err
+		return result
+	}
+
+	status, _, diagnostics, err := Solve(ctx, puzzle, options)
+	result.Diagnostics = diagnostics
+	result.Log = log.String()
+
+	switch {
+	case err != nil:
+		result.Status = BatchFailed
+		result.Err = err
+	case status == Aborted:
+		result.Status = BatchAborted
+	case status != Solved:
+		result.Status = BatchNoSolution
+	case diagnostics.SolutionsFound > 1:
+		result.Status = BatchMultiple
+	default:
+		result.Status = BatchSolved
+	}
+
+	return result
+}
+
+// Totals over every puzzle of a collection.
+type BatchSummary struct {
+	Puzzles        int
+	Solved         int
+	Multiple       int
+	NoSolution     int
+	Invalid        int
+	Failed         int
+	Aborted        int
+	NodeVisitCount int
+	BacktrackCount int
+}
+
+func (summary *BatchSummary) Add(result BatchResult) {
+	summary.Puzzles++
+	summary.NodeVisitCount += result.Diagnostics.NodeVisitCount
+	summary.BacktrackCount += result.Diagnostics.BacktrackCount
+
+	switch result.Status {
+	case BatchSolved:
+		summary.Solved++
+	case BatchMultiple:
+		summary.Multiple++
+	case BatchNoSolution:
+		summary.NoSolution++
+	case BatchInvalid:
+		summary.Invalid++
+	case BatchFailed:
+		summary.Failed++
+	case BatchAborted:
+		summary.Aborted++
+	}
+}
+
+// A puzzle of a collection on its way to a worker, with where its result
+// goes.
+type batchJob struct {
+	entry  CollectionEntry
+	result chan BatchResult
+}
+
+// Solve every puzzle of a collection with the given number of workers, each
+// solving a puzzle at a time. The collection is read a line at a time so that
+// it never has to be held in memory all at once, and the results are reported
+// in the order the puzzles come in, each as soon as it and every puzzle
+// before it are solved. Only a few puzzles past the one that's being waited
+// on are read ahead, so one that takes a long time doesn't pile up results.
+func SolveCollection(ctx context.Context, scanner *bufio.Scanner, options Options, workers int, report func(BatchResult)) BatchSummary {
+	workers = max(workers, 1)
+	jobs := make(chan batchJob)
+	// the results still to be reported, in order
+	pending := make(chan chan BatchResult, workers)
+
+	go func() {
+		defer close(jobs)
+		defer close(pending)
+
+		for lineNumber := 1; scanner.Scan(); lineNumber++ {
+			entry, ok := ParseCollectionLine(scanner.Text(), lineNumber)
+			if !ok {
+				continue
+			}
+
+			job := batchJob{entry: entry, result: make(chan BatchResult, 1)}
+			pending <- job.result
+			jobs <- job
+		}
+	}()
+
+	for range workers {
+		go func() {
+			for job := range jobs {
+				job.result <- SolveEntry(ctx, job.entry, options)
+			}
+		}()
+	}
+
+	// only this goroutine reports results and adds them up, so neither
+	// has to be safe for concurrent use
+	summary := BatchSummary{}
+	for next := range pending {
+		result := <-next
+		summary.Add(result)
+		report(result)
+	}
+
+	return summary
+}
@@ -0,0 +1,153 @@
+package solver
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseCollectionLine(t *testing.T) {
+	tests := []struct {
+		name     string
+		line     string
+		expected CollectionEntry
+		ok       bool
+	}{
+		{name: "puzzle only", line: "12.4", expected: CollectionEntry{Line: 3, Puzzle: "12.4"}, ok: true},
+		{name: "with a name", line: "12.4 easy one", expected: CollectionEntry{Line: 3, Name: "easy one", Puzzle: "12.4"}, ok: true},
+		{name: "with a comment", line: "12.4\t# easy one ", expected: CollectionEntry{Line: 3, Name: "easy one", Puzzle: "12.4"}, ok: true},
+		{name: "blank", line: "  ", ok: false},
+		{name: "comment", line: "# 12.4", ok: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entry, ok := ParseCollectionLine(tt.line, 3)
+
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.expected, entry)
+		})
+	}
+
+	assert.Equal(t, "line 3", CollectionEntry{Line: 3, Puzzle: "12.4"}.Label())
+}
+
+func TestSolveCollection(t *testing.T) {
+	file, err := os.Open("../samples/collection.sdm")
+	if err != nil {
+		panic("Unable to read file ../samples/collection.sdm")
+	}
+	defer file.Close()
+
+	for _, solver := range SolverTypes {
+		for _, workers := range []int{1, 4} {
+			t.Run(fmt.Sprintf("%s with %d workers", solver, workers), func(t *testing.T) {
+				file.Seek(0, 0)
+				options := NewOptions(false, EnsureUnique, InOrder, nil)
+				options.Solver = solver
+				options.Propagate = true
+
+				results := []BatchResult{}
+				summary := SolveCollection(context.Background(), bufio.NewScanner(file), options, workers, func(result BatchResult) {
+					results = append(results, result)
+				})
+
+				labels := []string{}
+				statuses := []BatchStatus{}
+				for _, result := range results {
+					labels = append(labels, result.Entry.Label())
+					statuses = append(statuses, result.Status)
+				}
+				assert.Equal(t, []string{"001", "chains", "line 4", "two solutions", "broken column", "no solution", "line 11"}, labels)
+				assert.Equal(t, []BatchStatus{BatchSolved, BatchSolved, BatchSolved, BatchMultiple, BatchInvalid, BatchNoSolution, BatchInvalid}, statuses)
+				assert.Equal(t, 2, results[3].Diagnostics.SolutionsFound)
+				assert.Contains(t, results[4].Err.Error(), "Column check failed")
+				assert.Contains(t, results[6].Err.Error(), "18 cells")
+
+				assert.Equal(t, 7, summary.Puzzles)
+				assert.Equal(t, 3, summary.Solved)
+				assert.Equal(t, 1, summary.Multiple)
+				assert.Equal(t, 1, summary.NoSolution)
+				assert.Equal(t, 2, summary.Invalid)
+
+				nodes := 0
+				for _, result := range results {
+					nodes += result.Diagnostics.NodeVisitCount
+				}
+				assert.Equal(t, nodes, summary.NodeVisitCount)
+			})
+		}
+	}
+}
+
+func TestSolveEntryMatchesSolve(t *testing.T) {
+	contents, err := os.ReadFile("../samples/001.txt")
+	if err != nil {
+		panic("Unable to read file ../samples/001.txt")
+	}
+	oneLine := strings.ReplaceAll(strings.TrimSpace(string(contents)), "\n", "")
+
+	options := NewOptions(false, EnsureUnique, InOrder, nil)
+	result := SolveEntry(context.Background(), CollectionEntry{Line: 1, Puzzle: oneLine}, options)
+	_, _, expected, _ := Solve(context.Background(), mustParse(string(contents)), options)
+
+	assert.Equal(t, BatchSolved, result.Status)
+	assert.Equal(t, expected, result.Diagnostics)
+}
+
+func TestSolveEntryAlongsideOthers(t *testing.T) {
+	entry := CollectionEntry{Line: 2, Puzzle: "....8....8231.7496........8948..2..1.75...6..6.1.4982..8..1.9.2...763...51.928.74"}
+
+	t.Run("with debug output", func(t *testing.T) {
+		options := NewOptions(true, EnsureUnique, InOrder, nil)
+		options.Propagate = true
+
+		result := SolveEntry(context.Background(), entry, options)
+
+		assert.Equal(t, BatchSolved, result.Status)
+		assert.Contains(t, result.Log, "propagating")
+	})
+
+	t.Run("shuffled", func(t *testing.T) {
+		seed := int64(42)
+		options := NewOptions(false, FindFirst, Shuffled, &seed)
+
+		first := SolveEntry(context.Background(), entry, options)
+		second := SolveEntry(context.Background(), entry, options)
+
+		// each entry gets a generator of its own, seeded by its line
+		assert.Equal(t, first.Diagnostics, second.Diagnostics)
+		assert.Equal(t, seed, options.Seed)
+	})
+
+	t.Run("with a node limit", func(t *testing.T) {
+		options := NewOptions(false, EnsureUnique, InOrder, nil)
+		options.MaxNodes = 10
+
+		result := SolveEntry(context.Background(), entry, options)
+
+		assert.Equal(t, BatchAborted, result.Status)
+		assert.Equal(t, 10, result.Diagnostics.NodeVisitCount)
+
+		summary := BatchSummary{}
+		summary.Add(result)
+		assert.Equal(t, 1, summary.Aborted)
+	})
+
+	t.Run("with a SAT solver that can't be run", func(t *testing.T) {
+		options := NewOptions(false, EnsureUnique, InOrder, nil)
+		options.Solver = SAT
+		options.SATCommand = filepath.Join(t.TempDir(), "missing-solver")
+
+		result := SolveEntry(context.Background(), entry, options)
+
+		assert.Equal(t, BatchFailed, result.Status)
+		assert.Error(t, result.Err)
+	})
+}
@@ -0,0 +1,350 @@
+package solver
+
+import (
+	"fmt"
+
+	"github.com/jbranchaud/go-sudoku/sudoku"
+)
+
+// Sudoku as an exact cover problem for Knuth's Algorithm X, implemented with
+// Dancing Links. Each of the size^3 candidate placements (row, cell, value),
+// 729 on a 9x9 board, is a row of the matrix that covers four of the 4*size^2
+// constraint columns, 324 on a 9x9 board:
+//
+//   - the cell is filled
+//   - the row contains the value
+//   - the column contains the value
+//   - the sector contains the value
+//
+// A solution is a set of rows that covers every column exactly once.
+//
+// A Killer Sudoku's cage sums and a variant's constraints don't fit in the
+// matrix, so they're checked as the placements are made instead, see
+// solveWithDLX.
+
+const dlxConstraintGroups = 4
+
+type dlxNode struct {
+	left, right, up, down int
+	column                int
+	// the candidate placement this node belongs to, -1 for column headers
+	placement int
+}
+
+type dlxMatrix struct {
+	nodes []dlxNode
+	// number of nodes currently in each column, indexed by column header
+	sizes []int
+}
+
+// node 0 is the root, nodes 1 through columnCount are the column headers
+const dlxRoot = 0
+
+func newDLXMatrix(columnCount int, nodeCount int) *dlxMatrix {
+	matrix := &dlxMatrix{
+		nodes: make([]dlxNode, columnCount+1, columnCount+1+nodeCount),
+		sizes: make([]int, columnCount+1),
+	}
+
+	for i := range matrix.nodes {
+		matrix.nodes[i] = dlxNode{
+			left:      (i + columnCount) % (columnCount + 1),
+			right:     (i + 1) % (columnCount + 1),
+			up:        i,
+			down:      i,
+			column:    i,
+			placement: -1,
+		}
+	}
+
+	return matrix
+}
+
+func (matrix *dlxMatrix) addRow(placement int, columns []int) {
+	first := len(matrix.nodes)
+
+	for i, column := range columns {
+		index := len(matrix.nodes)
+		header := &matrix.nodes[column]
+
+		node := dlxNode{
+			left:      index - 1,
+			right:     index + 1,
+			up:        header.up,
+			down:      column,
+			column:    column,
+			placement: placement,
+		}
+		if i == 0 {
+			node.left = first + len(columns) - 1
+		}
+		if i == len(columns)-1 {
+			node.right = first
+		}
+
+		matrix.nodes = append(matrix.nodes, node)
+		matrix.nodes[matrix.nodes[column].up].down = index
+		matrix.nodes[column].up = index
+		matrix.sizes[column]++
+	}
+}
+
+func (matrix *dlxMatrix) cover(column int) {
+	nodes := matrix.nodes
+
+	nodes[nodes[column].right].left = nodes[column].left
+	nodes[nodes[column].left].right = nodes[column].right
+
+	for i := nodes[column].down; i != column; i = nodes[i].down {
+		for j := nodes[i].right; j != i; j = nodes[j].right {
+			nodes[nodes[j].down].up = nodes[j].up
+			nodes[nodes[j].up].down = nodes[j].down
+			matrix.sizes[nodes[j].column]--
+		}
+	}
+}
+
+func (matrix *dlxMatrix) uncover(column int) {
+	nodes := matrix.nodes
+
+	for i := nodes[column].up; i != column; i = nodes[i].up {
+		for j := nodes[i].left; j != i; j = nodes[j].left {
+			matrix.sizes[nodes[j].column]++
+			nodes[nodes[j].down].up = j
+			nodes[nodes[j].up].down = j
+		}
+	}
+
+	nodes[nodes[column].right].left = column
+	nodes[nodes[column].left].right = column
+}
+
+// Pick the column with the fewest remaining rows (Knuth's S heuristic),
+// ties go to the leftmost column.
+func (matrix *dlxMatrix) smallestColumn() int {
+	best := -1
+	for column := matrix.nodes[dlxRoot].right; column != dlxRoot; column = matrix.nodes[column].right {
+		if best == -1 || matrix.sizes[column] < matrix.sizes[best] {
+			best = column
+		}
+	}
+
+	return best
+}
+
+// Like smallestColumn, but only counting the rows of placements that are
+// allowed, so a column with none left is picked right away.
+func (matrix *dlxMatrix) smallestColumnWhere(allowed func(placement int) bool) int {
+	best, bestSize := -1, 0
+	for column := matrix.nodes[dlxRoot].right; column != dlxRoot; column = matrix.nodes[column].right {
+		size := 0
+		for i := matrix.nodes[column].down; i != column; i = matrix.nodes[i].down {
+			if allowed(matrix.nodes[i].placement) {
+				size++
+			}
+		}
+
+		if best == -1 || size < bestSize {
+			best, bestSize = column, size
+		}
+		if size == 0 {
+			break
+		}
+	}
+
+	return best
+}
+
+func dlxPlacementID(size int, row int, cell int, value int) int {
+	return (row*size+cell)*size + (value - 1)
+}
+
+func dlxPlacementFromID(size int, id int) (int, int, int) {
+	value := id%size + 1
+	cellIndex := id / size
+
+	return cellIndex / size, cellIndex % size, value
+}
+
+// The columns (1-indexed, after the root) covered by placing value at
+// (row, cell).
+func dlxColumnsFor(layout sudoku.Layout, row int, cell int, value int) []int {
+	size := layout.Size()
+	sector := layout.SectorIndexFor(row, cell)
+
+	return []int{
+		1 + row*size + cell,
+		1 + size*size + row*size + (value - 1),
+		1 + 2*size*size + cell*size + (value - 1),
+		1 + 3*size*size + sector*size + (value - 1),
+	}
+}
+
+func buildSudokuMatrix(layout sudoku.Layout) *dlxMatrix {
+	size := layout.Size()
+	matrix := newDLXMatrix(dlxConstraintGroups*size*size, dlxConstraintGroups*size*size*size)
+
+	for row := range size {
+		for cell := range size {
+			for value := 1; value <= size; value++ {
+				matrix.addRow(dlxPlacementID(size, row, cell, value), dlxColumnsFor(layout, row, cell, value))
+			}
+		}
+	}
+
+	return matrix
+}
+
+// Solve the puzzle with Dancing Links. This follows the same TraversalType
+// semantics as traversePuzzle and fills in the same Diagnostics, where a node
+// is a candidate placement that was tried and a validity check is a look for
+// the most constrained column.
+func solveWithDLX(puzzle sudoku.Puzzle, options Options) (PuzzleStatus, sudoku.Puzzle, Diagnostics) {
+	diagnostics := Diagnostics{}
+
+	status := checkPuzzleStatus(puzzle)
+	diagnostics.ValidityCheckCount++
+	if status == Invalid {
+		return Invalid, puzzle, diagnostics
+	}
+
+	layout := puzzle.Layout()
+	size := layout.Size()
+	matrix := buildSudokuMatrix(layout)
+
+	// the clues are already part of every solution, so take their columns
+	// out of the matrix before searching
+	for row := range size {
+		for cell := range size {
+			value := puzzle.ValueAt(row, cell)
+			if value == 0 {
+				continue
+			}
+
+			for _, column := range dlxColumnsFor(layout, row, cell, value) {
+				matrix.cover(column)
+			}
+		}
+	}
+
+	// the cages and variant constraints are tracked on a copy of the puzzle,
+	// whose candidates rule out any placement that breaks a constraint or
+	// leaves a cage without a way to add up to its sum
+	var working *sudoku.Puzzle
+	if len(puzzle.Cages) > 0 || len(puzzle.Constraints) > 0 {
+		clone := puzzle.Clone()
+		working = &clone
+	}
+	allowed := func(placement int) bool {
+		row, cell, value := dlxPlacementFromID(size, placement)
+		return working.Candidates(row, cell).Has(value)
+	}
+
+	var firstSolution []int
+	solution := []int{}
+
+	var search func(level int) bool
+	search = func(level int) bool {
+		column := matrix.smallestColumn()
+		if working != nil {
+			column = matrix.smallestColumnWhere(allowed)
+		}
+		diagnostics.ValidityCheckCount++
+
+		if column == -1 {
+			// every constraint is satisfied
+			diagnostics.SolutionsFound++
+			solved := puzzle.Clone()
+			for _, id := range solution {
+				solved.PlaceValue(dlxPlacementFromID(size, id))
+			}
+			diagnostics.Solutions = append(diagnostics.Solutions, solved.String())
+
+			if firstSolution == nil {
+				firstSolution = append([]int{}, solution...)
+			}
+
+			switch options.TraversalType {
+			case FindFirst:
+				return true
+			case EnsureUnique:
+				return diagnostics.SolutionsFound > 1
+			case FindAll:
+				return false
+			default:
+				panic(fmt.Sprintf("Error: unrecognized options.TraversalType %s", options.TraversalType))
+			}
+		}
+
+		rows := []int{}
+		for i := matrix.nodes[column].down; i != column; i = matrix.nodes[i].down {
+			rows = append(rows, i)
+		}
+		if options.SolveOrder == Shuffled {
+			Shuffle(rows, options.Rng)
+		}
+
+		matrix.cover(column)
+		defer matrix.uncover(column)
+
+		for _, i := range rows {
+			if working != nil && !allowed(matrix.nodes[i].placement) {
+				continue
+			}
+			if options.limits != nil && options.limits.visit() {
+				// unwind the same way as after the solution that ends the
+				// search
+				return true
+			}
+
+			row, cell, value := dlxPlacementFromID(size, matrix.nodes[i].placement)
+
+			diagnostics.NodeVisitCount++
+			solution = append(solution, matrix.nodes[i].placement)
+			if working != nil {
+				working.PlaceValue(row, cell, value)
+			}
+
+			if options.Debug {
+				options.debugf("%d) placing %d at (%d,%d)\n", level, value, row, cell)
+			}
+
+			for j := matrix.nodes[i].right; j != i; j = matrix.nodes[j].right {
+				matrix.cover(matrix.nodes[j].column)
+			}
+
+			done := search(level + 1)
+
+			for j := matrix.nodes[i].left; j != i; j = matrix.nodes[j].left {
+				matrix.uncover(matrix.nodes[j].column)
+			}
+			solution = solution[:len(solution)-1]
+			if working != nil {
+				working.UndoLastPlacement()
+			}
+
+			if done {
+				return true
+			}
+			diagnostics.BacktrackCount++
+		}
+
+		return false
+	}
+
+	search(1)
+
+	if options.aborted() {
+		return Aborted, puzzle, diagnostics
+	}
+	if diagnostics.SolutionsFound == 0 {
+		return Invalid, puzzle, diagnostics
+	}
+
+	solved := puzzle.Clone()
+	for _, id := range firstSolution {
+		solved.PlaceValue(dlxPlacementFromID(size, id))
+	}
+
+	return Solved, solved, diagnostics
+}
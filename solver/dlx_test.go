@@ -0,0 +1,99 @@
+package solver
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/jbranchaud/go-sudoku/sudoku"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSolveWithDLX(t *testing.T) {
+	tests := []struct {
+		filename       string
+		traversalType  TraversalType
+		expectedStatus PuzzleStatus
+	}{
+		{filename: "../samples/001.txt", traversalType: FindFirst, expectedStatus: Solved},
+		{filename: "../samples/001.txt", traversalType: EnsureUnique, expectedStatus: Solved},
+		{filename: "../samples/two_solutions.txt", traversalType: EnsureUnique, expectedStatus: Solved},
+		{filename: "../samples/four_solutions.txt", traversalType: EnsureUnique, expectedStatus: Solved},
+		{filename: "../samples/four_solutions.txt", traversalType: FindAll, expectedStatus: Solved},
+		{filename: "../samples/invalid_001.txt", traversalType: FindAll, expectedStatus: Invalid},
+		{filename: "../samples/invalid_sector.txt", traversalType: FindAll, expectedStatus: Invalid},
+		{filename: "../samples/4x4.txt", traversalType: FindAll, expectedStatus: Solved},
+		{filename: "../samples/6x6.txt", traversalType: EnsureUnique, expectedStatus: Solved},
+		{filename: "../samples/jigsaw.txt", traversalType: EnsureUnique, expectedStatus: Solved},
+		{filename: "../samples/killer.txt", traversalType: EnsureUnique, expectedStatus: Solved},
+		{filename: "../samples/killer_6x6.txt", traversalType: FindAll, expectedStatus: Solved},
+		{filename: "../samples/invalid_killer.txt", traversalType: FindAll, expectedStatus: Invalid},
+		{filename: "../samples/diagonal.txt", traversalType: EnsureUnique, expectedStatus: Solved},
+		{filename: "../samples/invalid_diagonal.txt", traversalType: FindAll, expectedStatus: Invalid},
+		{filename: "../samples/anti_king_6x6.txt", traversalType: FindAll, expectedStatus: Solved},
+		{filename: "../samples/thermo_arrow.txt", traversalType: EnsureUnique, expectedStatus: Solved},
+		{filename: "../samples/kropki_xv_6x6.txt", traversalType: EnsureUnique, expectedStatus: Solved},
+		{filename: "../samples/invalid_thermo.txt", traversalType: FindAll, expectedStatus: Invalid},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%s with %s", tt.filename, tt.traversalType), func(t *testing.T) {
+			contents, err := os.ReadFile(tt.filename)
+			if err != nil {
+				panic(fmt.Sprintf("Unable to read file %s", tt.filename))
+			}
+
+			puzzle := mustParse(string(contents))
+			options := NewOptions(false, tt.traversalType, InOrder, nil)
+
+			expectedStatus, _, expected := traversePuzzle(puzzle, 1, options, &Diagnostics{})
+			status, solved, diagnostics := solveWithDLX(puzzle, options)
+
+			assert.Equal(t, tt.expectedStatus, status)
+			assert.Equal(t, expectedStatus, status)
+			assert.Equal(t, expected.SolutionsFound, diagnostics.SolutionsFound)
+			if tt.traversalType == FindAll {
+				assert.ElementsMatch(t, expected.Solutions, diagnostics.Solutions)
+			}
+			if status == Solved {
+				assert.Equal(t, diagnostics.Solutions[0], solved.String())
+			}
+		})
+	}
+}
+
+func TestSolve16x16PuzzleWithDLX(t *testing.T) {
+	contents, err := os.ReadFile("../samples/16x16.txt")
+	if err != nil {
+		panic("Unable to read file ../samples/16x16.txt")
+	}
+
+	puzzle := mustParse(string(contents))
+	options := NewOptions(false, EnsureUnique, InOrder, nil)
+
+	status, solved, diagnostics := solveWithDLX(puzzle, options)
+
+	assert.Equal(t, Solved, status)
+	assert.Equal(t, 1, diagnostics.SolutionsFound)
+	assert.Equal(t, Solved, checkPuzzleStatus(solved))
+
+	// plain backtracking takes far too long on a board this big, it needs
+	// propagation and a better choice of cell
+	options.Propagate = true
+	options.Strategy = FewestCandidates
+	_, _, backtracked := traversePuzzle(puzzle, 1, options, &Diagnostics{})
+	assert.Equal(t, []string{solved.String()}, backtracked.Solutions)
+}
+
+func TestSolveEmptyPuzzleWithDLX(t *testing.T) {
+	seed := int64(42)
+	options := NewOptions(false, FindFirst, Shuffled, &seed)
+	options.Solver = DLX
+
+	status, puzzle, _, err := Solve(context.Background(), EmptyPuzzle(sudoku.StandardShape), options)
+
+	assert.NoError(t, err)
+	assert.Equal(t, Solved, status)
+	assert.Equal(t, Solved, checkPuzzleStatus(puzzle))
+}
@@ -0,0 +1,84 @@
+package solver
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jbranchaud/go-sudoku/sudoku"
+)
+
+// A puzzle that couldn't be read, see Parse and ParseGattai.
+type ParseError struct {
+	// the row of the board the puzzle couldn't be read at, 0 if it went
+	// wrong somewhere else, e.g. in a cage layout
+	Row int
+	Err error
+}
+
+func (err *ParseError) Error() string {
+	if err.Row == 0 {
+		return err.Err.Error()
+	}
+
+	return fmt.Sprintf("row %d: %v", err.Row, err.Err)
+}
+
+func (err *ParseError) Unwrap() error {
+	return err.Err
+}
+
+// A puzzle that breaks the rules, see Validate and ValidateGattai.
+type ValidationError struct {
+	// the grid of a gattai puzzle that breaks the rules, counting from 1,
+	// 0 for any other puzzle
+	Grid int
+	// the name of the constraint the puzzle breaks, e.g. "row" or
+	// "thermometer", empty if the board itself is malformed, e.g. it isn't
+	// square or has a value that's too big
+	Constraint string
+	Err        error
+}
+
+func (err *ValidationError) Error() string {
+	message := fmt.Sprintf("Validation check failed, %v", err.Err)
+	if err.Constraint != "" {
+		message = fmt.Sprintf("%s%s check failed: %v", strings.ToUpper(err.Constraint[:1]), err.Constraint[1:], err.Err)
+	}
+
+	if err.Grid > 0 {
+		return fmt.Sprintf("Grid %d: %s", err.Grid, message)
+	}
+
+	return message
+}
+
+func (err *ValidationError) Unwrap() error {
+	return err.Err
+}
+
+// The solver the options pick failed, rather than searching the puzzle, e.g.
+// an external SAT solver couldn't be run.
+type SolverError struct {
+	Solver SolverType
+	Err    error
+}
+
+func (err *SolverError) Error() string {
+	return err.Err.Error()
+}
+
+func (err *SolverError) Unwrap() error {
+	return err.Err
+}
+
+// There's no board of the shape that follows every one of the constraints,
+// see SolveEmpty.
+type UnsatisfiableError struct {
+	Shape       sudoku.Shape
+	Constraints []sudoku.Constraint
+}
+
+func (err *UnsatisfiableError) Error() string {
+	size := err.Shape.Size()
+	return fmt.Sprintf("There's no %dx%d board that follows %s", size, size, sudoku.ConstraintList(err.Constraints))
+}
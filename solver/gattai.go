@@ -0,0 +1,315 @@
+package solver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/jbranchaud/go-sudoku/sudoku"
+)
+
+const gattaiPrefix = "gattai:"
+
+// A gattai puzzle starts with its layout, e.g. `gattai: samurai`, see
+// sudoku.ParseGattaiLayout, rather than with the board's first row.
+func IsGattai(str string) bool {
+	for _, row := range strings.Split(str, "\n") {
+		if strings.TrimSpace(row) != "" {
+			return strings.HasPrefix(strings.ToLower(strings.TrimSpace(row)), gattaiPrefix)
+		}
+	}
+
+	return false
+}
+
+// After the layout line and a blank line comes the combined board, a symbol
+// per cell with a space for every cell outside the grids. Each grid is a
+// standard 9x9 one.
+func ParseGattai(str string) (sudoku.Gattai, error) {
+	lines := strings.Split(str, "\n")
+
+	// blank lines before the layout line are skipped, the way IsGattai
+	// skips them
+	for len(lines) > 0 && strings.TrimSpace(lines[0]) == "" {
+		lines = lines[1:]
+	}
+	if len(lines) == 0 || !IsGattai(lines[0]) {
+		return sudoku.Gattai{}, &ParseError{Err: fmt.Errorf("a gattai puzzle has to start with a '%s' line naming its layout", gattaiPrefix)}
+	}
+
+	offsets, err := sudoku.ParseGattaiLayout(strings.TrimSpace(lines[0])[len(gattaiPrefix):])
+	if err != nil {
+		return sudoku.Gattai{}, &ParseError{Err: err}
+	}
+
+	rows := []string{}
+	for _, line := range lines[1:] {
+		if strings.TrimSpace(line) == "" && len(rows) == 0 {
+			continue
+		}
+		rows = append(rows, line)
+	}
+
+	return gattaiFromRows(rows, offsets)
+}
+
+func gattaiFromRows(rows []string, offsets []sudoku.Position) (sudoku.Gattai, error) {
+	board := [][]int{}
+	for i, row := range rows {
+		cells := []int{}
+		for _, symbol := range strings.Split(strings.TrimRight(row, " \t\r"), "") {
+			if symbol == " " {
+				cells = append(cells, 0)
+				continue
+			}
+
+			cell, err := sudoku.ParseSymbol(symbol)
+			if err != nil {
+				return sudoku.Gattai{}, &ParseError{Row: i + 1, Err: err}
+			}
+			cells = append(cells, cell)
+		}
+		board = append(board, cells)
+	}
+
+	// a blank line can end the board, with trailing blank rows left off
+	for len(board) > 0 && len(board[len(board)-1]) == 0 {
+		board = board[:len(board)-1]
+	}
+
+	gattai, err := sudoku.NewGattai(board, sudoku.StandardShape, offsets)
+	if err != nil {
+		return sudoku.Gattai{}, &ParseError{Err: err}
+	}
+
+	return gattai, nil
+}
+
+// Each grid has to be valid on its own, the cells they share are the same
+// cells so there's nothing to check between them. The ValidationError of a
+// grid that breaks the rules says which grid it is.
+func ValidateGattai(gattai sudoku.Gattai) error {
+	for i, grid := range gattai.Grids {
+		err := Validate(grid)
+		if err != nil {
+			var validationErr *ValidationError
+			if errors.As(err, &validationErr) {
+				validationErr.Grid = i + 1
+			}
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Solve the puzzle the way Solve does with the backtracking solver, within
+// the same limits. A puzzle that breaks the rules is Invalid with the
+// ValidationError from ValidateGattai, the only other error is for options
+// that can't be used.
+func SolveGattai(ctx context.Context, gattai sudoku.Gattai, options Options) (PuzzleStatus, sudoku.Gattai, Diagnostics, error) {
+	options, err := options.withDefaults()
+	if err != nil {
+		return Invalid, gattai, Diagnostics{}, err
+	}
+
+	options, cancel := options.withLimits(ctx)
+	defer cancel()
+
+	diagnostics := Diagnostics{}
+	diagnostics.ValidityCheckCount++
+	err = ValidateGattai(gattai)
+	if err != nil {
+		return Invalid, gattai, diagnostics, err
+	}
+
+	working := gattai.Clone()
+	status := traverseGattai(&working, 1, options, &diagnostics)
+
+	return status, working, diagnostics, nil
+}
+
+// Backtrack over the combined board, always filling in the cell with the
+// fewest candidates, after propagating singles if that's turned on. Every
+// placement goes into each grid that has the cell, so the grids' candidates
+// already take the cells they share into account.
+func traverseGattai(gattai *sudoku.Gattai, level int, options Options, diagnostics *Diagnostics) PuzzleStatus {
+	rows, columns := gattai.Dimensions()
+
+	// fill in everything that is forced before branching, these placements
+	// belong to this level of the traversal and are undone along with it
+	propagatedCount := 0
+	if options.Propagate {
+		count, consistent := propagateGattaiSingles(gattai, level, options, diagnostics)
+		propagatedCount = count
+		if !consistent {
+			undoGattaiPlacements(gattai, propagatedCount)
+			return exhaustedStatus(level, diagnostics)
+		}
+	}
+
+	nextRow, nextCell, fewest := -1, -1, 0
+	for row := range rows {
+		for cell := range columns {
+			if !gattai.Contains(row, cell) || gattai.ValueAt(row, cell) != 0 {
+				continue
+			}
+
+			count := gattai.Candidates(row, cell).Count()
+			if nextRow == -1 || count < fewest {
+				nextRow, nextCell, fewest = row, cell, count
+			}
+		}
+	}
+
+	if nextRow == -1 {
+		(*diagnostics).SolutionsFound++
+		(*diagnostics).Solutions = append((*diagnostics).Solutions, gattai.String())
+
+		switch options.TraversalType {
+		case FindFirst:
+			return Solved
+		case EnsureUnique:
+			if (*diagnostics).SolutionsFound > 1 {
+				return Solved
+			}
+			undoGattaiPlacements(gattai, propagatedCount)
+			return exhaustedStatus(level, diagnostics)
+		case FindAll:
+			undoGattaiPlacements(gattai, propagatedCount)
+			return exhaustedStatus(level, diagnostics)
+		default:
+			panic(fmt.Sprintf("Error: unrecognized options.TraversalType %s", options.TraversalType))
+		}
+	}
+
+	possibleValues := gattai.Candidates(nextRow, nextCell).Values()
+	for _, value := range possibleValues {
+		if options.limits != nil && options.limits.visit() {
+			undoGattaiPlacements(gattai, propagatedCount)
+			return Aborted
+		}
+
+		(*diagnostics).NodeVisitCount++
+		gattai.PlaceValue(nextRow, nextCell, value)
+
+		if options.Debug {
+			options.debugf("%d) placing %d at (%d,%d) of %v\n", level, value, nextRow, nextCell, possibleValues)
+		}
+
+		switch traverseGattai(gattai, level+1, options, diagnostics) {
+		case Solved:
+			return Solved
+		case Aborted:
+			gattai.UndoLastPlacement()
+			undoGattaiPlacements(gattai, propagatedCount)
+			return Aborted
+		}
+
+		(*diagnostics).BacktrackCount++
+		gattai.UndoLastPlacement()
+	}
+
+	undoGattaiPlacements(gattai, propagatedCount)
+	return exhaustedStatus(level, diagnostics)
+}
+
+// The puzzle with one of its solutions filled in, from the solution's string.
+// A string that can't be read as the puzzle's board gives a ParseError.
+func GattaiSolutionOf(gattai sudoku.Gattai, solution string) (sudoku.Gattai, error) {
+	return gattaiFromRows(strings.Split(solution, "\n"), gattai.Offsets)
+}
+
+func undoGattaiPlacements(gattai *sudoku.Gattai, count int) {
+	for range count {
+		gattai.UndoLastPlacement()
+	}
+}
+
+// Like propagateSingles, over the combined board and the units of every grid.
+func propagateGattaiSingles(gattai *sudoku.Gattai, level int, options Options, diagnostics *Diagnostics) (int, bool) {
+	rows, columns := gattai.Dimensions()
+	units := gattai.Units()
+	allValues := gattai.Grids[0].Layout().AllValuesMask()
+	placements := 0
+
+	place := func(row int, cell int, value int, technique string) {
+		gattai.PlaceValue(row, cell, value)
+		placements++
+
+		if options.Debug {
+			options.debugf("%d) propagating %d at (%d,%d) as a %s\n", level, value, row, cell, technique)
+		}
+	}
+
+	for progress := true; progress; {
+		progress = false
+
+		for row := range rows {
+			for cell := range columns {
+				if !gattai.Contains(row, cell) || gattai.ValueAt(row, cell) != 0 {
+					continue
+				}
+
+				candidates := gattai.Candidates(row, cell)
+				switch candidates.Count() {
+				case 0:
+					return placements, false
+				case 1:
+					place(row, cell, candidates.Values()[0], "naked single")
+					(*diagnostics).NakedSingleCount++
+					progress = true
+				}
+			}
+		}
+
+		for _, unit := range units {
+			var placed, seenOnce, seenTwice sudoku.CandidateMask
+			for _, position := range unit {
+				value := gattai.ValueAt(position.Row, position.Cell)
+				if value != 0 {
+					placed |= sudoku.MaskFor(value)
+					continue
+				}
+
+				candidates := gattai.Candidates(position.Row, position.Cell)
+				seenTwice |= seenOnce & candidates
+				seenOnce |= candidates
+			}
+
+			if placed|seenOnce != allValues {
+				// some value has nowhere left to go in this unit
+				return placements, false
+			}
+
+			hiddenSingles := seenOnce &^ seenTwice &^ placed
+			for _, value := range hiddenSingles.Values() {
+				for _, position := range unit {
+					if gattai.ValueAt(position.Row, position.Cell) != 0 || !gattai.Candidates(position.Row, position.Cell).Has(value) {
+						continue
+					}
+
+					place(position.Row, position.Cell, value, "hidden single")
+					(*diagnostics).HiddenSingleCount++
+					progress = true
+					break
+				}
+			}
+
+			// two values that can only go in the same cell leave one of them
+			// without a home, so check the unit again after placing
+			for _, value := range hiddenSingles.Values() {
+				found := slices.ContainsFunc(unit, func(position sudoku.Position) bool {
+					return gattai.ValueAt(position.Row, position.Cell) == value
+				})
+				if !found {
+					return placements, false
+				}
+			}
+		}
+	}
+
+	return placements, true
+}
@@ -0,0 +1,105 @@
+package solver
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsGattai(t *testing.T) {
+	assert.True(t, IsGattai("\nGattai: samurai\n\n123\n"))
+	assert.False(t, IsGattai("123\n456\n"))
+	assert.False(t, IsGattai("0000\n\nconstraints: diagonal\n"))
+}
+
+func TestParseGattai(t *testing.T) {
+	t.Run("blank lines before the layout", func(t *testing.T) {
+		contents, err := os.ReadFile("../samples/samurai.txt")
+		if err != nil {
+			panic("Unable to read file ../samples/samurai.txt")
+		}
+
+		gattai, err := ParseGattai(" \n\t\n" + string(contents))
+
+		assert.NoError(t, err)
+		assert.Len(t, gattai.Grids, 5)
+	})
+
+	t.Run("without a layout", func(t *testing.T) {
+		for _, str := range []string{"", " \n \n", "123\n456\n"} {
+			_, err := ParseGattai(str)
+
+			var parseErr *ParseError
+			assert.ErrorAs(t, err, &parseErr)
+			assert.ErrorContains(t, err, "has to start with a 'gattai:' line")
+		}
+	})
+}
+
+func TestSolveGattai(t *testing.T) {
+	contents, err := os.ReadFile("../samples/samurai.txt")
+	if err != nil {
+		panic("Unable to read file ../samples/samurai.txt")
+	}
+	gattai, err := ParseGattai(string(contents))
+	assert.NoError(t, err)
+	assert.Len(t, gattai.Grids, 5)
+
+	options := NewOptions(false, EnsureUnique, InOrder, nil)
+	options.Propagate = true
+
+	status, _, diagnostics, _ := SolveGattai(context.Background(), gattai, options)
+
+	assert.Equal(t, Solved, status)
+	assert.Equal(t, 1, diagnostics.SolutionsFound)
+
+	solved, err := gattaiFromRows(strings.Split(diagnostics.Solutions[0], "\n"), gattai.Offsets)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, solved.EmptyCellCount())
+	for i, grid := range solved.Grids {
+		err := Validate(grid)
+		assert.NoError(t, err, "grid %d", i+1)
+	}
+	// the middle grid's top left box is the first grid's bottom right one
+	assert.Equal(t, solved.Grids[0].ValueAt(6, 6), solved.Grids[2].ValueAt(0, 0))
+
+	t.Run("without propagation", func(t *testing.T) {
+		// most of the solution back in, so plain backtracking is quick
+		rows := strings.Split(diagnostics.Solutions[0], "\n")
+		for i := 0; i < len(rows); i += 2 {
+			rows[i] = strings.ReplaceAll(rows[i], "5", "0")
+		}
+		partial, err := gattaiFromRows(rows, gattai.Offsets)
+		assert.NoError(t, err)
+
+		status, _, found, _ := SolveGattai(context.Background(), partial, NewOptions(false, FindAll, InOrder, nil))
+
+		assert.Equal(t, Solved, status)
+		assert.Equal(t, diagnostics.Solutions, found.Solutions)
+		assert.Zero(t, found.PropagatedCount())
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		contents, err := os.ReadFile("../samples/invalid_samurai.txt")
+		if err != nil {
+			panic("Unable to read file ../samples/invalid_samurai.txt")
+		}
+		invalid, err := ParseGattai(string(contents))
+		assert.NoError(t, err)
+
+		err = ValidateGattai(invalid)
+		assert.ErrorContains(t, err, "Grid 3: Row check failed")
+		var validationErr *ValidationError
+		if assert.ErrorAs(t, err, &validationErr) {
+			assert.Equal(t, 3, validationErr.Grid)
+			assert.Equal(t, "row", validationErr.Constraint)
+		}
+
+		status, _, _, err := SolveGattai(context.Background(), invalid, options)
+		assert.Equal(t, Invalid, status)
+		assert.ErrorAs(t, err, &validationErr)
+	})
+}
@@ -0,0 +1,254 @@
+package solver
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"slices"
+
+	"github.com/jbranchaud/go-sudoku/sudoku"
+)
+
+// A puzzle is only playable if there is exactly one way to solve it. A puzzle
+// that breaks the rules has none, and gives the ValidationError from Validate.
+func HasUniqueSolution(puzzle sudoku.Puzzle) (bool, error) {
+	err := Validate(puzzle)
+	if err != nil {
+		return false, err
+	}
+
+	return hasUniqueSolution(puzzle), nil
+}
+
+// Like HasUniqueSolution, for a puzzle that's known to follow the rules, such
+// as one made by taking clues out of a solution. The EnsureUnique traversal
+// stops as soon as it sees a second solution, so this stays cheap even for
+// puzzles with lots of solutions. Branching on the cell with the fewest
+// candidates keeps it cheap on boards bigger than 9x9 too.
+func hasUniqueSolution(puzzle sudoku.Puzzle) bool {
+	options := NewOptions(false, EnsureUnique, InOrder, nil)
+	options.Propagate = true
+	options.Strategy = FewestCandidates
+
+	status, _, diagnostics := traversePuzzle(puzzle, 1, options, &Diagnostics{})
+
+	return status == Solved && diagnostics.SolutionsFound == 1
+}
+
+// Starting from a fully solved board, visit every cell in a random order and
+// try blanking it out. The clue is put back whenever removing it would give
+// the puzzle more than one solution. Cells the symmetry maps onto each other
+// are blanked out and put back together, so the clues stay symmetric.
+func generatePuzzle(solution sudoku.Puzzle, symmetry Symmetry, rng *rand.Rand) sudoku.Puzzle {
+	board := solution.CurrentBoard()
+	solvedBoard := solution.CurrentBoard()
+
+	orbits := symmetry.orbits(solution.Size())
+	orbitIndexes := make([]int, len(orbits))
+	for i := range orbitIndexes {
+		orbitIndexes[i] = i
+	}
+	Shuffle(orbitIndexes, rng)
+
+	for _, orbitIndex := range orbitIndexes {
+		orbit := orbits[orbitIndex]
+
+		for _, position := range orbit {
+			board[position.Row][position.Cell] = 0
+		}
+
+		if !hasUniqueSolution(withBoard(solution, board)) {
+			restoreClues(board, solvedBoard, orbit)
+		}
+	}
+
+	return withBoard(solution, board)
+}
+
+// A copy of the puzzle with the board in place of its own, and without any
+// placements, which keeps everything else about it, e.g. a killer puzzle's
+// cages.
+func withBoard(puzzle sudoku.Puzzle, board [][]int) sudoku.Puzzle {
+	clone := puzzle.Clone()
+	clone.Board = board
+	clone.Solution = nil
+
+	return clone
+}
+
+func restoreClues(board [][]int, solvedBoard [][]int, positions []sudoku.Position) {
+	for _, position := range positions {
+		board[position.Row][position.Cell] = solvedBoard[position.Row][position.Cell]
+	}
+}
+
+// The cells of the puzzle that have a value.
+func CountClues(puzzle sudoku.Puzzle) int {
+	clues := 0
+	for _, row := range puzzle.CurrentBoard() {
+		for _, cell := range row {
+			if cell != 0 {
+				clues++
+			}
+		}
+	}
+
+	return clues
+}
+
+// How many fresh puzzles generatePuzzleWithDifficulty tries before giving up.
+const maxDifficultyAttempts = 100
+
+type GeneratedPuzzle struct {
+	Puzzle   sudoku.Puzzle
+	Symmetry Symmetry
+	// the zero Grade for a puzzle the logic solver can't grade, see
+	// sudoku.CheckLogicSupport
+	Grade sudoku.Grade
+	// how many puzzles were generated to find one with the right difficulty
+	Attempts int
+}
+
+// Generate a puzzle from the solution with the symmetry, difficulty, and
+// minimality asked for in the options. The clues are removed in an order
+// picked by the options' generator, or their seed if they don't have one. A
+// difficulty can only be asked for when the logic solver can grade puzzles
+// like the solution, see sudoku.CheckLogicSupport.
+func Generate(solution sudoku.Puzzle, options Options) (GeneratedPuzzle, error) {
+	if options.Rng == nil {
+		options.Rng = rand.New(rand.NewSource(options.Seed))
+	}
+
+	if options.Minimal && options.Symmetry != NoSymmetry && options.Symmetry != "" {
+		return GeneratedPuzzle{}, fmt.Errorf("A minimal puzzle can't keep %s symmetry, removing its redundant clues would break it", options.Symmetry)
+	}
+
+	if options.Difficulty != "" {
+		return generatePuzzleWithDifficulty(solution, options)
+	}
+
+	puzzle := generatePuzzle(solution, options.Symmetry, options.Rng)
+	if options.Minimal {
+		puzzle = Minimize(puzzle, options.Rng)
+	}
+
+	generated := GeneratedPuzzle{Puzzle: puzzle, Symmetry: options.Symmetry, Attempts: 1}
+	if grade, err := sudoku.GradePuzzle(puzzle); err == nil {
+		generated.Grade = grade
+	}
+
+	return generated, nil
+}
+
+// Generate puzzles until one is graded at the given difficulty. Removing
+// every clue it can leaves a puzzle as hard as it's going to get, so a puzzle
+// that's too easy is thrown away and the next attempt removes clues in a
+// different order. A puzzle that's too hard gets clues from the solution put
+// back, one orbit of the symmetry at a time, until it's easy enough. Those
+// clues are redundant, so a puzzle that has to be minimal is thrown away
+// instead.
+func generatePuzzleWithDifficulty(solution sudoku.Puzzle, options Options) (GeneratedPuzzle, error) {
+	difficulty := options.Difficulty
+	target := slices.Index(sudoku.Difficulties, difficulty)
+	if target == -1 {
+		return GeneratedPuzzle{}, fmt.Errorf("Unrecognized difficulty '%s', expected one of %v", difficulty, sudoku.Difficulties)
+	}
+
+	// every attempt is graded, so a template the logic solver can't grade,
+	// e.g. a killer one, is turned away before any of them
+	if err := sudoku.CheckLogicSupport(solution); err != nil {
+		return GeneratedPuzzle{}, fmt.Errorf("Can't generate a puzzle of a given difficulty from this template, %w", err)
+	}
+
+	solvedBoard := solution.CurrentBoard()
+	orbits := options.Symmetry.orbits(solution.Size())
+
+	for attempt := 1; attempt <= maxDifficultyAttempts; attempt++ {
+		puzzle := generatePuzzle(solution, options.Symmetry, options.Rng)
+		if options.Minimal {
+			puzzle = Minimize(puzzle, options.Rng)
+		}
+		grade, err := sudoku.GradePuzzle(puzzle)
+		if err != nil {
+			return GeneratedPuzzle{}, err
+		}
+
+		board := puzzle.CurrentBoard()
+		emptyOrbits := []int{}
+		for i, orbit := range orbits {
+			if board[orbit[0].Row][orbit[0].Cell] == 0 {
+				emptyOrbits = append(emptyOrbits, i)
+			}
+		}
+		Shuffle(emptyOrbits, options.Rng)
+
+		for _, orbitIndex := range emptyOrbits {
+			if options.Minimal || slices.Index(sudoku.Difficulties, grade.Difficulty) <= target {
+				break
+			}
+
+			restoreClues(board, solvedBoard, orbits[orbitIndex])
+
+			puzzle = withBoard(solution, board)
+			grade, err = sudoku.GradePuzzle(puzzle)
+			if err != nil {
+				return GeneratedPuzzle{}, err
+			}
+		}
+
+		if grade.Difficulty == difficulty {
+			return GeneratedPuzzle{Puzzle: puzzle, Symmetry: options.Symmetry, Grade: grade, Attempts: attempt}, nil
+		}
+	}
+
+	return GeneratedPuzzle{}, fmt.Errorf("Couldn't generate a %s puzzle in %d attempts", difficulty, maxDifficultyAttempts)
+}
+
+// A board of the shape with every cell empty.
+func EmptyPuzzle(shape sudoku.Shape) sudoku.Puzzle {
+	size := shape.Size()
+	board := make([][]int, size)
+	for i := range size {
+		board[i] = make([]int, size)
+	}
+
+	return sudoku.Puzzle{Board: board, Shape: shape}
+}
+
+// Fill in an empty board of the options' shape that follows their
+// constraints, in an order picked by their seed, see NewOptions. Some
+// constraints can't all be followed on a board of the shape, which is an
+// UnsatisfiableError.
+func SolveEmpty(options Options) (sudoku.Puzzle, error) {
+	options, err := options.withDefaults()
+	if err != nil {
+		return sudoku.Puzzle{}, err
+	}
+
+	shape := options.Shape
+	if shape == (sudoku.Shape{}) {
+		shape = sudoku.StandardShape
+	}
+
+	// without propagation, guessing through an empty board bigger than 9x9,
+	// or one with a variant's constraints, can take forever, plain 9x9 boards
+	// leave it off so seeds keep filling them in the same way they always have
+	if shape.Size() > sudoku.StandardShape.Size() || len(options.Constraints) > 0 {
+		options.Propagate = true
+	}
+
+	puzzle := EmptyPuzzle(shape)
+	puzzle.Constraints = options.Constraints
+	status, puzzle, _ := traversePuzzle(puzzle, 1, options, &Diagnostics{})
+
+	if status != Solved && len(options.Constraints) > 0 {
+		// some combinations of constraints can't all hold on a board of
+		// this size
+		return sudoku.Puzzle{}, &UnsatisfiableError{Shape: shape, Constraints: options.Constraints}
+	}
+	if status != Solved {
+		return sudoku.Puzzle{}, errors.New("Something went wrong with puzzle generation")
+	}
+
+	return puzzle, nil
+}
@@ -0,0 +1,200 @@
+package solver
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"os"
+	"testing"
+
+	"github.com/jbranchaud/go-sudoku/sudoku"
+	"github.com/stretchr/testify/assert"
+)
+
+func mustSolveEmpty(options Options) sudoku.Puzzle {
+	puzzle, err := SolveEmpty(options)
+	if err != nil {
+		panic(fmt.Sprintf("Unable to solve an empty board: %v", err))
+	}
+
+	return puzzle
+}
+
+func TestHasUniqueSolution(t *testing.T) {
+	tests := []struct {
+		name                  string
+		filename              string
+		expectedUnique        bool
+		expectedErrorContains string
+	}{
+		{
+			name:           "single solution",
+			filename:       "../samples/001.txt",
+			expectedUnique: true,
+		},
+		{
+			name:           "two solutions",
+			filename:       "../samples/two_solutions.txt",
+			expectedUnique: false,
+		},
+		{
+			name:           "four solutions",
+			filename:       "../samples/four_solutions.txt",
+			expectedUnique: false,
+		},
+		{
+			name:                  "breaks the rules",
+			filename:              "../samples/invalid_row.txt",
+			expectedUnique:        false,
+			expectedErrorContains: "Row check failed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			contents, err := os.ReadFile(tt.filename)
+			if err != nil {
+				panic(fmt.Sprintf("Unable to read file %s", tt.filename))
+			}
+
+			puzzle := mustParse(string(contents))
+
+			unique, err := HasUniqueSolution(puzzle)
+
+			assert.Equal(t, tt.expectedUnique, unique)
+			if tt.expectedErrorContains == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, tt.expectedErrorContains)
+			}
+		})
+	}
+}
+
+func TestGeneratePuzzle(t *testing.T) {
+	solution := mustParse(`346571289
+128439657
+579268314
+631842795
+782695143
+495317826
+214753968
+853926471
+967184532`)
+
+	puzzle := generatePuzzle(solution, NoSymmetry, rand.New(rand.NewSource(42)))
+
+	assert.True(t, hasUniqueSolution(puzzle))
+	assert.Less(t, CountClues(puzzle), 81)
+
+	// every remaining clue comes from the solved board
+	solvedBoard := solution.CurrentBoard()
+	for i, row := range puzzle.CurrentBoard() {
+		for j, cell := range row {
+			if cell != 0 {
+				assert.Equal(t, solvedBoard[i][j], cell)
+			}
+		}
+	}
+}
+
+func TestGeneratePuzzleWithDifficulty(t *testing.T) {
+	solution := mustParse(`346571289
+128439657
+579268314
+631842795
+782695143
+495317826
+214753968
+853926471
+967184532`)
+
+	for _, difficulty := range []sudoku.Difficulty{sudoku.Easy, sudoku.Hard, sudoku.Diabolical} {
+		t.Run(string(difficulty), func(t *testing.T) {
+			generated, err := generatePuzzleWithDifficulty(solution, Options{Symmetry: NoSymmetry, Difficulty: difficulty, Rng: rand.New(rand.NewSource(42))})
+
+			assert.NoError(t, err)
+			assert.Equal(t, difficulty, generated.Grade.Difficulty)
+			grade, err := sudoku.GradePuzzle(generated.Puzzle)
+			assert.NoError(t, err)
+			assert.Equal(t, grade, generated.Grade)
+			assert.GreaterOrEqual(t, generated.Attempts, 1)
+			assert.True(t, hasUniqueSolution(generated.Puzzle))
+
+			again, _ := generatePuzzleWithDifficulty(solution, Options{Symmetry: NoSymmetry, Difficulty: difficulty, Rng: rand.New(rand.NewSource(42))})
+			assert.Equal(t, generated.Puzzle.String(), again.Puzzle.String())
+		})
+	}
+
+	t.Run("unknown difficulty", func(t *testing.T) {
+		_, err := generatePuzzleWithDifficulty(solution, Options{Symmetry: NoSymmetry, Difficulty: sudoku.Difficulty("impossible"), Rng: rand.New(rand.NewSource(42))})
+
+		assert.Error(t, err)
+	})
+}
+
+func TestGenerateKillerPuzzle(t *testing.T) {
+	contents, err := os.ReadFile("../samples/killer_6x6.txt")
+	if err != nil {
+		panic("Unable to read file ../samples/killer_6x6.txt")
+	}
+	killer := mustParse(string(contents))
+	_, solved, _, err := Solve(context.Background(), killer, Options{})
+	assert.NoError(t, err)
+
+	generated, err := Generate(solved, Options{Seed: 42})
+
+	assert.NoError(t, err)
+	assert.Equal(t, killer.Cages, generated.Puzzle.Cages)
+	assert.Empty(t, generated.Puzzle.Solution)
+	assert.True(t, hasUniqueSolution(generated.Puzzle))
+	// the logic solver can't grade a killer puzzle
+	assert.Equal(t, sudoku.Grade{}, generated.Grade)
+
+	_, err = Generate(solved, Options{Difficulty: sudoku.Easy, Seed: 42})
+	assert.ErrorIs(t, err, sudoku.ErrCagesBeyondLogic)
+	assert.ErrorContains(t, err, "Can't generate a puzzle of a given difficulty from this template")
+}
+
+func TestGeneratePuzzleOfEachSize(t *testing.T) {
+	shapes := []sudoku.Shape{
+		{BoxRows: 2, BoxColumns: 2},
+		{BoxRows: 2, BoxColumns: 3},
+		{BoxRows: 3, BoxColumns: 2},
+		{BoxRows: 3, BoxColumns: 4},
+	}
+
+	for _, shape := range shapes {
+		t.Run(shape.String(), func(t *testing.T) {
+			seed := int64(42)
+			options := NewOptions(false, FindFirst, Shuffled, &seed)
+			options.Shape = shape
+
+			solution := mustSolveEmpty(options)
+			assert.Equal(t, shape, solution.Shape)
+			assert.Equal(t, Solved, checkPuzzleStatus(solution))
+
+			puzzle := generatePuzzle(solution, Rotational180, options.Rng)
+
+			assert.Equal(t, shape, puzzle.Shape)
+			assert.Less(t, CountClues(puzzle), shape.Size()*shape.Size())
+			assert.True(t, IsSymmetric(puzzle, Rotational180))
+			assert.True(t, hasUniqueSolution(puzzle))
+		})
+	}
+}
+
+func TestSolveEmptyWithUnsatisfiableConstraints(t *testing.T) {
+	seed := int64(42)
+	options := NewOptions(false, FindFirst, Shuffled, &seed)
+	options.Shape = sudoku.Shape{BoxRows: 2, BoxColumns: 2}
+	options.Constraints = []sudoku.Constraint{sudoku.Diagonal{}, sudoku.AntiKing{}}
+
+	_, err := SolveEmpty(options)
+
+	var unsatisfiableErr *UnsatisfiableError
+	if assert.ErrorAs(t, err, &unsatisfiableErr) {
+		assert.Equal(t, options.Shape, unsatisfiableErr.Shape)
+	}
+	assert.EqualError(t, err, "There's no 4x4 board that follows diagonal, anti-king")
+}
@@ -0,0 +1,70 @@
+package solver
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+)
+
+// How many nodes a search visits between checks of its context, which are
+// too slow to make on every node.
+const contextCheckInterval = 256
+
+// The error a SAT solver stops with when it runs out of decisions, see
+// Options.MaxNodes.
+var ErrNodeLimit = errors.New("reached the most nodes the search can visit")
+
+// The bounds on how much work a search can do, shared by every worker of a
+// parallel search. A search checks them before visiting each node and, once
+// they're exceeded, unwinds and reports that it was Aborted.
+type searchLimits struct {
+	ctx      context.Context
+	maxNodes int64
+	nodes    atomic.Int64
+	aborted  atomic.Bool
+}
+
+// The options with limits from the context and from the options' Timeout and
+// MaxNodes, and a function to release the context's resources once the
+// search is done. A search without any limits doesn't check for them at all.
+func (options Options) withLimits(ctx context.Context) (Options, context.CancelFunc) {
+	cancel := context.CancelFunc(func() {})
+	if options.Timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, options.Timeout)
+	}
+
+	if ctx.Done() != nil || options.MaxNodes > 0 {
+		options.limits = &searchLimits{ctx: ctx, maxNodes: int64(options.MaxNodes)}
+	}
+
+	return options, cancel
+}
+
+// The context the search runs in, which is never done for a search without
+// limits.
+func (options Options) context() context.Context {
+	if options.limits == nil {
+		return context.Background()
+	}
+
+	return options.limits.ctx
+}
+
+// Count a node the search is about to visit. Reports whether the search has
+// to stop instead, because it has visited as many nodes as it can, its
+// context is done, or another worker has already stopped it.
+func (limits *searchLimits) visit() bool {
+	nodes := limits.nodes.Add(1)
+	if limits.maxNodes > 0 && nodes > limits.maxNodes {
+		limits.aborted.Store(true)
+	} else if (nodes-1)%contextCheckInterval == 0 && limits.ctx.Err() != nil {
+		limits.aborted.Store(true)
+	}
+
+	return limits.aborted.Load()
+}
+
+// Whether the search was stopped by its limits.
+func (options Options) aborted() bool {
+	return options.limits != nil && options.limits.aborted.Load()
+}
@@ -0,0 +1,112 @@
+package solver
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/jbranchaud/go-sudoku/sudoku"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSolveWithLimits(t *testing.T) {
+	tests := []struct {
+		solver  SolverType
+		workers int
+	}{
+		{solver: Backtracking, workers: 1},
+		{solver: Backtracking, workers: 4},
+		{solver: DLX, workers: 1},
+		{solver: SAT, workers: 1},
+	}
+
+	for _, tt := range tests {
+		// an empty board has more solutions than could ever all be found
+		puzzle := EmptyPuzzle(sudoku.StandardShape)
+		options := NewOptions(false, FindAll, InOrder, nil)
+		options.Solver = tt.solver
+		options.Workers = tt.workers
+
+		t.Run(fmt.Sprintf("%s with %d workers and max nodes", tt.solver, tt.workers), func(t *testing.T) {
+			options := options
+			options.MaxNodes = 5000
+
+			status, solved, diagnostics, err := Solve(context.Background(), puzzle, options)
+
+			assert.NoError(t, err)
+			assert.Equal(t, Aborted, status)
+			assert.Equal(t, 5000, diagnostics.NodeVisitCount)
+			// the solutions found before it stopped are kept
+			assert.Len(t, diagnostics.Solutions, diagnostics.SolutionsFound)
+			assert.Equal(t, puzzle.String(), solved.String())
+		})
+
+		t.Run(fmt.Sprintf("%s with %d workers and a timeout", tt.solver, tt.workers), func(t *testing.T) {
+			options := options
+			options.Timeout = 20 * time.Millisecond
+
+			start := time.Now()
+			status, _, diagnostics, err := Solve(context.Background(), puzzle, options)
+
+			assert.NoError(t, err)
+			assert.Equal(t, Aborted, status)
+			assert.Positive(t, diagnostics.NodeVisitCount)
+			assert.Less(t, time.Since(start), time.Second)
+		})
+
+		t.Run(fmt.Sprintf("%s with %d workers and a cancelled context", tt.solver, tt.workers), func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+
+			status, _, _, err := Solve(ctx, puzzle, options)
+
+			assert.NoError(t, err)
+			assert.Equal(t, Aborted, status)
+		})
+	}
+}
+
+func TestSolveWithinLimits(t *testing.T) {
+	contents, err := os.ReadFile("../samples/001.txt")
+	if err != nil {
+		panic("Unable to read file ../samples/001.txt")
+	}
+	puzzle := mustParse(string(contents))
+
+	for _, solver := range SolverTypes {
+		t.Run(string(solver), func(t *testing.T) {
+			options := NewOptions(false, EnsureUnique, InOrder, nil)
+			options.Solver = solver
+			_, _, expected, _ := Solve(context.Background(), puzzle, options)
+
+			// limits the search doesn't reach don't change anything
+			options.Timeout = time.Minute
+			options.MaxNodes = expected.NodeVisitCount + 1
+			status, _, diagnostics, err := Solve(context.Background(), puzzle, options)
+
+			assert.NoError(t, err)
+			assert.Equal(t, Solved, status)
+			assert.Equal(t, expected, diagnostics)
+		})
+	}
+}
+
+func TestSolveGattaiWithLimits(t *testing.T) {
+	contents, err := os.ReadFile("../samples/samurai.txt")
+	if err != nil {
+		panic("Unable to read file ../samples/samurai.txt")
+	}
+	gattai, err := ParseGattai(string(contents))
+	assert.NoError(t, err)
+
+	options := NewOptions(false, EnsureUnique, InOrder, nil)
+	options.MaxNodes = 3
+
+	status, solved, diagnostics, _ := SolveGattai(context.Background(), gattai, options)
+
+	assert.Equal(t, Aborted, status)
+	assert.Equal(t, 3, diagnostics.NodeVisitCount)
+	assert.Equal(t, gattai.String(), solved.String())
+}
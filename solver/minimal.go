@@ -0,0 +1,69 @@
+package solver
+
+import (
+	"math/rand"
+
+	"github.com/jbranchaud/go-sudoku/sudoku"
+)
+
+// The clues that could each be removed on their own without the puzzle losing
+// its unique solution. Removing one of them can make another one necessary,
+// so they can't always all be removed together.
+func RedundantClues(puzzle sudoku.Puzzle) []sudoku.Position {
+	board := puzzle.CurrentBoard()
+	size := puzzle.Size()
+	redundant := []sudoku.Position{}
+
+	for row := range size {
+		for cell := range size {
+			value := board[row][cell]
+			if value == 0 {
+				continue
+			}
+
+			board[row][cell] = 0
+			if hasUniqueSolution(withBoard(puzzle, board)) {
+				redundant = append(redundant, sudoku.Position{Row: row, Cell: cell})
+			}
+			board[row][cell] = value
+		}
+	}
+
+	return redundant
+}
+
+// A puzzle is minimal when it has a unique solution and every one of its
+// clues is needed to keep it that way.
+func IsMinimal(puzzle sudoku.Puzzle) bool {
+	return hasUniqueSolution(puzzle) && len(RedundantClues(puzzle)) == 0
+}
+
+// Remove redundant clues, in a random order, until none are left. A clue that
+// is needed stays needed as more clues are removed, so one pass over the clues
+// is enough.
+func Minimize(puzzle sudoku.Puzzle, rng *rand.Rand) sudoku.Puzzle {
+	board := puzzle.CurrentBoard()
+	size := puzzle.Size()
+
+	cellIndexes := []int{}
+	for i := range size * size {
+		if board[i/size][i%size] != 0 {
+			cellIndexes = append(cellIndexes, i)
+		}
+	}
+	Shuffle(cellIndexes, rng)
+
+	for _, cellIndex := range cellIndexes {
+		row := cellIndex / size
+		cell := cellIndex % size
+
+		removedValue := board[row][cell]
+		board[row][cell] = 0
+
+		if !hasUniqueSolution(withBoard(puzzle, board)) {
+			board[row][cell] = removedValue
+		}
+	}
+
+	return withBoard(puzzle, board)
+}
@@ -0,0 +1,107 @@
+package solver
+
+import (
+	"fmt"
+	"math/rand"
+	"os"
+	"testing"
+
+	"github.com/jbranchaud/go-sudoku/sudoku"
+	"github.com/stretchr/testify/assert"
+)
+
+func readSample(filename string) sudoku.Puzzle {
+	contents, err := os.ReadFile(filename)
+	if err != nil {
+		panic(fmt.Sprintf("Unable to read file %s", filename))
+	}
+
+	return mustParse(string(contents))
+}
+
+func TestRedundantClues(t *testing.T) {
+	tests := []struct {
+		name              string
+		filename          string
+		expectedRedundant int
+		expectedMinimal   bool
+	}{
+		{
+			name:              "has redundant clues",
+			filename:          "../samples/001.txt",
+			expectedRedundant: 29,
+			expectedMinimal:   false,
+		},
+		{
+			name:              "minimal",
+			filename:          "../samples/chains.txt",
+			expectedRedundant: 0,
+			expectedMinimal:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			puzzle := readSample(tt.filename)
+
+			assert.Len(t, RedundantClues(puzzle), tt.expectedRedundant)
+			assert.Equal(t, tt.expectedMinimal, IsMinimal(puzzle))
+		})
+	}
+
+	t.Run("not unique", func(t *testing.T) {
+		assert.False(t, IsMinimal(readSample("../samples/two_solutions.txt")))
+	})
+}
+
+func TestMinimizePuzzle(t *testing.T) {
+	puzzle := readSample("../samples/001.txt")
+
+	minimized := Minimize(puzzle, rand.New(rand.NewSource(42)))
+
+	assert.True(t, IsMinimal(minimized))
+	assert.Less(t, CountClues(minimized), CountClues(puzzle))
+
+	// every remaining clue comes from the original puzzle
+	board := puzzle.CurrentBoard()
+	for i, row := range minimized.CurrentBoard() {
+		for j, cell := range row {
+			if cell != 0 {
+				assert.Equal(t, board[i][j], cell)
+			}
+		}
+	}
+}
+
+func TestGenerateMinimalPuzzle(t *testing.T) {
+	solution := mustParse(`346571289
+128439657
+579268314
+631842795
+782695143
+495317826
+214753968
+853926471
+967184532`)
+
+	t.Run("without a difficulty", func(t *testing.T) {
+		generated, err := Generate(solution, Options{Symmetry: NoSymmetry, Minimal: true, Rng: rand.New(rand.NewSource(42))})
+
+		assert.NoError(t, err)
+		assert.True(t, IsMinimal(generated.Puzzle))
+	})
+
+	t.Run("with a difficulty", func(t *testing.T) {
+		generated, err := Generate(solution, Options{Symmetry: NoSymmetry, Difficulty: sudoku.Medium, Minimal: true, Rng: rand.New(rand.NewSource(42))})
+
+		assert.NoError(t, err)
+		assert.Equal(t, sudoku.Medium, generated.Grade.Difficulty)
+		assert.True(t, IsMinimal(generated.Puzzle))
+	})
+
+	t.Run("with a symmetry", func(t *testing.T) {
+		_, err := Generate(solution, Options{Symmetry: Rotational180, Minimal: true, Rng: rand.New(rand.NewSource(42))})
+
+		assert.Error(t, err)
+	})
+}
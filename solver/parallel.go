@@ -0,0 +1,260 @@
+package solver
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+	"sync/atomic"
+
+	"github.com/jbranchaud/go-sudoku/sudoku"
+)
+
+// The levels of the traversal whose branches are handed out to the workers of
+// a parallel search, rather than searched by the worker that reached them.
+// Every level below these is searched as a whole by one worker.
+const parallelSplitLevels = 3
+
+// A branch of the traversal for a worker to search.
+type subproblem struct {
+	puzzle sudoku.Puzzle
+	level  int
+	// the index of the value placed at each level on the way to this branch,
+	// which orders the branches the same way the sequential traversal visits
+	// them
+	path []int
+}
+
+// The solutions found in a branch, and the solved puzzle if the branch ended
+// the search.
+type branchResult struct {
+	path      []int
+	solutions []string
+	solved    *sudoku.Puzzle
+}
+
+// The state the workers of a parallel search share. Each worker has a queue of
+// branches, which it takes the newest one from to keep going depth first, and
+// which the other workers steal the oldest one from when theirs is empty,
+// since the oldest are the nearest the top and have the most left to search.
+type parallelSearch struct {
+	traversalType TraversalType
+	// read on every step of the traversal, so it's kept out of the mutex
+	stopped   atomic.Bool
+	solutions atomic.Int64
+
+	mu   sync.Mutex
+	cond *sync.Cond
+	// the branches that have been handed out but not searched yet
+	outstanding int
+	queues      [][]subproblem
+}
+
+// One of the goroutines of a parallel search.
+type searchWorker struct {
+	search *parallelSearch
+	index  int
+	// the branch the worker is on
+	path        []int
+	diagnostics Diagnostics
+	results     []branchResult
+}
+
+// Search the puzzle the way traversePuzzle does, with the given number of
+// workers each searching a branch at a time. The top levels of the traversal
+// are split into branches that the workers share out between them, and once
+// one of them finds what the TraversalType is looking for, e.g. a second
+// solution for EnsureUnique, the rest stop.
+//
+// The solutions are put back in the order the sequential traversal finds
+// them, so for FindAll the result is the same as traversePuzzle's. For
+// FindFirst and EnsureUnique, which solutions are found first depends on how
+// the workers happen to run, and the counts can include a little work that was
+// already under way when the search stopped. Any worker that runs into the
+// search's limits stops the rest, and the search is Aborted.
+func solveInParallel(puzzle sudoku.Puzzle, options Options, workers int) (PuzzleStatus, sudoku.Puzzle, Diagnostics) {
+	search := &parallelSearch{traversalType: options.TraversalType, queues: make([][]subproblem, workers)}
+	search.cond = sync.NewCond(&search.mu)
+	search.push(0, subproblem{puzzle: puzzle, level: 1})
+
+	searchWorkers := make([]*searchWorker, workers)
+	var wg sync.WaitGroup
+	for i := range workers {
+		worker := &searchWorker{search: search, index: i}
+		searchWorkers[i] = worker
+
+		workerOptions := options.reseeded(options.Seed + int64(i))
+		workerOptions.worker = worker
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			worker.run(workerOptions)
+		}()
+	}
+	wg.Wait()
+
+	diagnostics := Diagnostics{}
+	results := []branchResult{}
+	for _, worker := range searchWorkers {
+		diagnostics.addCounts(worker.diagnostics)
+		results = append(results, worker.results...)
+	}
+
+	slices.SortFunc(results, func(a branchResult, b branchResult) int {
+		return slices.Compare(a.path, b.path)
+	})
+	solved := puzzle
+	foundSolved := false
+	for _, result := range results {
+		diagnostics.Solutions = append(diagnostics.Solutions, result.solutions...)
+		if result.solved != nil && !foundSolved {
+			solved = *result.solved
+			foundSolved = true
+		}
+	}
+
+	// workers that found a solution at the same time can go past what the
+	// sequential traversal would have stopped at
+	switch options.TraversalType {
+	case FindFirst:
+		diagnostics.Solutions = diagnostics.Solutions[:min(len(diagnostics.Solutions), 1)]
+	case EnsureUnique:
+		diagnostics.Solutions = diagnostics.Solutions[:min(len(diagnostics.Solutions), 2)]
+	}
+	diagnostics.SolutionsFound = len(diagnostics.Solutions)
+
+	if options.aborted() {
+		return Aborted, puzzle, diagnostics
+	}
+	if diagnostics.SolutionsFound == 0 {
+		return Invalid, puzzle, diagnostics
+	}
+
+	return Solved, solved, diagnostics
+}
+
+func (search *parallelSearch) push(index int, branch subproblem) {
+	search.mu.Lock()
+	defer search.mu.Unlock()
+
+	search.outstanding++
+	search.queues[index] = append(search.queues[index], branch)
+	search.cond.Broadcast()
+}
+
+// The next branch for the worker with the given index, from its own queue or
+// stolen from another's, waiting for one if every queue is empty but some
+// branch is still being searched and could hand out more. There's nothing
+// left once every branch has been searched or the search has stopped.
+func (search *parallelSearch) next(index int) (subproblem, bool) {
+	search.mu.Lock()
+	defer search.mu.Unlock()
+
+	for {
+		if search.stopped.Load() || search.outstanding == 0 {
+			return subproblem{}, false
+		}
+
+		own := search.queues[index]
+		if len(own) > 0 {
+			branch := own[len(own)-1]
+			search.queues[index] = own[:len(own)-1]
+			return branch, true
+		}
+
+		for i := 1; i < len(search.queues); i++ {
+			other := (index + i) % len(search.queues)
+			if len(search.queues[other]) > 0 {
+				branch := search.queues[other][0]
+				search.queues[other] = search.queues[other][1:]
+				return branch, true
+			}
+		}
+
+		search.cond.Wait()
+	}
+}
+
+func (search *parallelSearch) done() {
+	search.mu.Lock()
+	defer search.mu.Unlock()
+
+	search.outstanding--
+	if search.outstanding == 0 {
+		search.cond.Broadcast()
+	}
+}
+
+// Count a solution a worker has found, and stop the search if it was the one
+// the TraversalType is looking for. Reports whether the search is over.
+func (search *parallelSearch) found() bool {
+	count := search.solutions.Add(1)
+
+	var over bool
+	switch search.traversalType {
+	case FindFirst:
+		over = count >= 1
+	case EnsureUnique:
+		over = count > 1
+	case FindAll:
+		over = false
+	default:
+		panic(fmt.Sprintf("Error: unrecognized options.TraversalType %s", search.traversalType))
+	}
+
+	if over {
+		search.stop()
+	}
+
+	return over
+}
+
+// End the search, waking up the workers that are waiting for a branch.
+func (search *parallelSearch) stop() {
+	search.stopped.Store(true)
+
+	search.mu.Lock()
+	defer search.mu.Unlock()
+	search.cond.Broadcast()
+}
+
+func (worker *searchWorker) run(options Options) {
+	for {
+		branch, ok := worker.search.next(worker.index)
+		if !ok {
+			return
+		}
+
+		worker.path = branch.path
+		diagnostics := Diagnostics{}
+		status, solved, _ := traversePuzzle(branch.puzzle, branch.level, options, &diagnostics)
+
+		// the traversal that reached this branch would have backtracked
+		// out of it, unless it ended the search
+		if status == Invalid && branch.level > 1 {
+			diagnostics.BacktrackCount++
+		}
+		if status == Aborted {
+			worker.search.stop()
+		}
+
+		result := branchResult{path: branch.path, solutions: diagnostics.Solutions}
+		if status == Solved && checkPlacementStatus(solved) == Solved {
+			result.solved = &solved
+		}
+		if len(result.solutions) > 0 {
+			worker.results = append(worker.results, result)
+		}
+
+		worker.diagnostics.addCounts(diagnostics)
+
+		worker.search.done()
+	}
+}
+
+// Hand out the branch for the value that was just placed, the index-th of its
+// cell's values, instead of searching it.
+func (worker *searchWorker) spawn(puzzle *sudoku.Puzzle, level int, index int) {
+	path := append(slices.Clone(worker.path), index)
+	worker.search.push(worker.index, subproblem{puzzle: puzzle.Clone(), level: level, path: path})
+}
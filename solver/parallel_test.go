@@ -0,0 +1,79 @@
+package solver
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/jbranchaud/go-sudoku/sudoku"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSolveInParallel(t *testing.T) {
+	tests := []struct {
+		filename      string
+		traversalType TraversalType
+		strategy      CellStrategy
+		propagate     bool
+	}{
+		{filename: "../samples/001.txt", traversalType: FindAll, strategy: FirstEmpty, propagate: false},
+		{filename: "../samples/four_solutions.txt", traversalType: FindAll, strategy: FirstEmpty, propagate: false},
+		{filename: "../samples/four_solutions.txt", traversalType: FindAll, strategy: FewestCandidates, propagate: true},
+		{filename: "../samples/invalid_001.txt", traversalType: FindAll, strategy: FirstEmpty, propagate: false},
+		{filename: "../samples/6x6.txt", traversalType: FindAll, strategy: FirstEmpty, propagate: true},
+		{filename: "../samples/killer_6x6.txt", traversalType: FindAll, strategy: MostConstrained, propagate: true},
+		{filename: "../samples/thermo_arrow.txt", traversalType: FindAll, strategy: FirstEmpty, propagate: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%s with %s", tt.filename, tt.strategy), func(t *testing.T) {
+			contents, err := os.ReadFile(tt.filename)
+			if err != nil {
+				panic(fmt.Sprintf("Unable to read file %s", tt.filename))
+			}
+
+			puzzle := mustParse(string(contents))
+			options := NewOptions(false, tt.traversalType, InOrder, nil)
+			options.Strategy = tt.strategy
+			options.Propagate = tt.propagate
+
+			expectedStatus, _, expected := traversePuzzle(puzzle, 1, options, &Diagnostics{})
+			status, _, diagnostics := solveInParallel(puzzle, options, 4)
+
+			// every branch is searched, so it all adds up to the same as
+			// searching them one after the other
+			assert.Equal(t, expectedStatus, status)
+			assert.Equal(t, expected, diagnostics)
+		})
+	}
+}
+
+func TestSolveInParallelStopsEarly(t *testing.T) {
+	tests := []struct {
+		traversalType     TraversalType
+		expectedSolutions int
+	}{
+		{traversalType: FindFirst, expectedSolutions: 1},
+		{traversalType: EnsureUnique, expectedSolutions: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.traversalType), func(t *testing.T) {
+			// an empty board has more solutions than could ever all be found
+			options := NewOptions(false, tt.traversalType, InOrder, nil)
+			options.Workers = 4
+
+			status, solved, diagnostics, err := Solve(context.Background(), EmptyPuzzle(sudoku.StandardShape), options)
+
+			assert.NoError(t, err)
+			assert.Equal(t, Solved, status)
+			assert.Equal(t, tt.expectedSolutions, diagnostics.SolutionsFound)
+			assert.Len(t, diagnostics.Solutions, tt.expectedSolutions)
+			if tt.traversalType == FindFirst {
+				assert.Equal(t, diagnostics.Solutions[0], solved.String())
+				assert.Equal(t, Solved, checkPuzzleStatus(solved))
+			}
+		})
+	}
+}
@@ -0,0 +1,236 @@
+package solver
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/jbranchaud/go-sudoku/sudoku"
+)
+
+// Each line of the puzzle is a row. Boards up to 25x25 can be written with a
+// symbol per cell, e.g. 0-9 and A-P, with 0, . or _ for an empty cell, while a
+// board of numbers split by spaces, e.g. 10 11 12, is read as numbers when
+// that gives every row a number per cell. Grid lines, `|`, `-` and `+` or the
+// box drawing characters of a printed board, are skipped, so are spaces
+// between symbols, and blank lines between bands of rows until the board is
+// square. The whole board can also be on a single line, e.g. the 81 symbols
+// of a 9x9 board.
+//
+// After the rows, separated by blank lines, can come a jigsaw puzzle's region
+// layout, see sudoku.ParseRegions, a Killer Sudoku's cage layout, see
+// sudoku.ParseCages, which is told apart by its label=sum lines, and a
+// variant's constraints, see sudoku.ParseConstraintLines, which start with
+// their kind and a colon, e.g. `constraints: diagonal, anti-knight` or
+// `thermo: r1c1 r1c2 r1c3`.
+//
+// Reading a puzzle doesn't check it follows the rules, see Validate, and one
+// that can't be read at all gives a ParseError.
+func Parse(str string) (sudoku.Puzzle, error) {
+	var puzzle sudoku.Puzzle
+
+	blocks := [][]string{}
+	blankLine := true
+	for _, row := range strings.Split(str, "\n") {
+		row = strings.TrimRight(row, "\r")
+		if strings.TrimSpace(row) == "" {
+			blankLine = true
+			continue
+		}
+
+		if blankLine {
+			blocks = append(blocks, []string{})
+			blankLine = false
+		}
+		blocks[len(blocks)-1] = append(blocks[len(blocks)-1], row)
+	}
+
+	board, blocks, err := readBoard(blocks)
+	if err != nil {
+		return sudoku.Puzzle{}, err
+	}
+	puzzle.Board = board
+
+	if len(puzzle.Board) == 0 {
+		return sudoku.Puzzle{}, &ParseError{Err: errors.New("the puzzle is empty, it doesn't have any rows")}
+	}
+
+	if len(puzzle.Board) == 1 && len(puzzle.Board[0]) > 1 {
+		board, err := unfoldRow(puzzle.Board[0])
+		if err != nil {
+			return sudoku.Puzzle{}, &ParseError{Err: err}
+		}
+		puzzle.Board = board
+	}
+
+	for _, block := range blocks {
+		// layouts are made up of labels, a colon starts a constraint line
+		if strings.Contains(block[0], ":") {
+			constraints, err := sudoku.ParseConstraintLines(block)
+			if err != nil {
+				return sudoku.Puzzle{}, &ParseError{Err: err}
+			}
+			puzzle.Constraints = append(puzzle.Constraints, constraints...)
+			continue
+		}
+
+		isCageLayout := slices.ContainsFunc(block, func(line string) bool {
+			return strings.Contains(line, "=")
+		})
+		if !isCageLayout {
+			puzzle.Regions = sudoku.ParseRegions(block)
+			continue
+		}
+
+		cages, err := sudoku.ParseCages(block)
+		if err != nil {
+			return sudoku.Puzzle{}, &ParseError{Err: err}
+		}
+		puzzle.Cages = cages
+	}
+
+	return puzzle, nil
+}
+
+// The board at the start of the blocks, and the blocks after it. Whether it's
+// written in numbers or symbols is decided for the whole board, so `12 04` is
+// two cells of a board of numbers, but four of a 4x4 board with a space
+// between its sectors.
+func readBoard(blocks [][]string) ([][]int, [][]string, error) {
+	board, rest, err := readBoardAs(blocks, true)
+	if err == nil && isNumberBoard(board) {
+		return board, rest, nil
+	}
+
+	return readBoardAs(blocks, false)
+}
+
+// The board at the start of the blocks read in numbers or symbols. A board
+// pasted with blank lines between its bands of rows goes on until it's
+// square, anything after it is a layout or constraints.
+func readBoardAs(blocks [][]string, asNumbers bool) ([][]int, [][]string, error) {
+	if len(blocks) == 0 {
+		return [][]int{}, blocks, nil
+	}
+
+	board, err := parseBoardRows(blocks[0], asNumbers)
+	if err != nil {
+		return nil, nil, err
+	}
+	blocks = blocks[1:]
+
+	for len(blocks) > 0 && !strings.Contains(blocks[0][0], ":") {
+		band, err := parseBoardRows(blocks[0], asNumbers)
+		if err != nil || !continuesBoard(board, band) {
+			break
+		}
+
+		board = append(board, band...)
+		blocks = blocks[1:]
+	}
+
+	return board, blocks, nil
+}
+
+// Whether a board read in numbers is one, which takes a number per cell of a
+// square board, or of a board on a single line, and no number bigger than the
+// board.
+func isNumberBoard(board [][]int) bool {
+	if len(board) == 1 {
+		unfolded, err := unfoldRow(board[0])
+		if err != nil {
+			return false
+		}
+		board = unfolded
+	}
+
+	return len(board) > 0 && !slices.ContainsFunc(board, func(cells []int) bool {
+		return len(cells) != len(board) || slices.Max(cells) > len(board)
+	})
+}
+
+// The rows of the board in a block of lines, with the lines of the grid
+// between sectors left out.
+func parseBoardRows(lines []string, asNumbers bool) ([][]int, error) {
+	board := [][]int{}
+	for i, row := range lines {
+		cells, err := parseRow(row, asNumbers)
+		if err != nil {
+			return nil, &ParseError{Row: i + 1, Err: err}
+		}
+		if len(cells) == 0 {
+			// a line of the grid between sectors
+			continue
+		}
+
+		board = append(board, cells)
+	}
+
+	return board, nil
+}
+
+// Whether a band of rows goes under the board, which takes a board that
+// isn't square yet and rows as wide as it that don't make it too tall.
+func continuesBoard(board [][]int, band [][]int) bool {
+	if len(board) == 0 || len(board)+len(band) > len(board[0]) {
+		return false
+	}
+
+	return !slices.ContainsFunc(band, func(cells []int) bool {
+		return len(cells) != len(board[0])
+	})
+}
+
+// Grid lines between the cells of a row, and whole lines of them between the
+// rows. Any box drawing character counts, so a printed board can be read back.
+func isGridCharacter(r rune) bool {
+	return r == '|' || r == '-' || r == '+' || (r >= '\u2500' && r <= '\u257f')
+}
+
+// The cells of a row, with the grid lines taken out. In numbers, everything
+// between its spaces is a value on its own, and otherwise it's a symbol per
+// cell, e.g. `53. .7. ...` is still nine cells.
+func parseRow(row string, asNumbers bool) ([]int, error) {
+	fields := strings.Fields(strings.Map(func(r rune) rune {
+		if isGridCharacter(r) {
+			return ' '
+		}
+		return r
+	}, row))
+
+	unparsedCells := fields
+	if !asNumbers {
+		unparsedCells = strings.Split(strings.Join(fields, ""), "")
+	}
+
+	cells := []int{}
+	for _, unparsedCell := range unparsedCells {
+		cell, err := sudoku.ParseSymbol(unparsedCell)
+		if err != nil {
+			return nil, err
+		}
+		cells = append(cells, cell)
+	}
+
+	return cells, nil
+}
+
+// A board written on a single line is split back into rows, which takes a
+// square number of cells, e.g. 81 for a 9x9 board.
+func unfoldRow(cells []int) ([][]int, error) {
+	size := 1
+	for size*size < len(cells) {
+		size++
+	}
+	if size*size != len(cells) {
+		return nil, fmt.Errorf("a puzzle on one line has %d cells, which isn't the square number of cells of a board, like the 81 of a 9x9 one", len(cells))
+	}
+
+	board := [][]int{}
+	for row := range size {
+		board = append(board, cells[row*size:(row+1)*size])
+	}
+
+	return board, nil
+}
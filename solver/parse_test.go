@@ -0,0 +1,189 @@
+package solver
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/jbranchaud/go-sudoku/sudoku"
+	"github.com/stretchr/testify/assert"
+)
+
+func mustParse(str string) sudoku.Puzzle {
+	puzzle, err := Parse(str)
+	if err != nil {
+		panic(fmt.Sprintf("Unable to read puzzle: %v", err))
+	}
+
+	return puzzle
+}
+
+func TestParse(t *testing.T) {
+	t.Run("symbols", func(t *testing.T) {
+		puzzle := mustParse("1.3\nab0\nP0G\n")
+
+		assert.Equal(t, [][]int{{1, 0, 3}, {10, 11, 0}, {25, 0, 16}}, puzzle.Board)
+	})
+
+	t.Run("numbers split on spaces", func(t *testing.T) {
+		puzzle := mustParse("1 0 3\n 2  3 .\n3 . 2\n")
+
+		assert.Equal(t, [][]int{{1, 0, 3}, {2, 3, 0}, {3, 0, 2}}, puzzle.Board)
+		assert.Nil(t, puzzle.Regions)
+
+		board := [][]int{}
+		rows := []string{}
+		for row := range 16 {
+			cells := []int{}
+			fields := []string{}
+			for cell := range 16 {
+				value := (row*4+row/4+cell)%16 + 1
+				cells = append(cells, value)
+				fields = append(fields, fmt.Sprint(value))
+			}
+			board = append(board, cells)
+			rows = append(rows, strings.Join(fields, " "))
+		}
+
+		assert.Equal(t, board, mustParse(strings.Join(rows, "\n")).Board)
+	})
+
+	t.Run("symbols split by sector", func(t *testing.T) {
+		puzzle := mustParse("12 04\n00 00\n\n00 00\n10 00\n")
+
+		assert.Equal(t, [][]int{{1, 2, 0, 4}, {0, 0, 0, 0}, {0, 0, 0, 0}, {1, 0, 0, 0}}, puzzle.Board)
+
+		contents, err := os.ReadFile("../samples/16x16.txt")
+		if err != nil {
+			panic("Unable to read file ../samples/16x16.txt")
+		}
+		expected := mustParse(string(contents))
+
+		rows := []string{}
+		for i, row := range strings.Split(strings.TrimSpace(string(contents)), "\n") {
+			if i > 0 && i%4 == 0 {
+				rows = append(rows, "")
+			}
+			rows = append(rows, strings.Join([]string{row[0:4], row[4:8], row[8:12], row[12:16]}, " "))
+		}
+
+		assert.Equal(t, expected.Board, mustParse(strings.Join(rows, "\n")).Board)
+	})
+
+	t.Run("the same puzzle written other ways", func(t *testing.T) {
+		contents, err := os.ReadFile("../samples/001.txt")
+		if err != nil {
+			panic("Unable to read file ../samples/001.txt")
+		}
+		expected := mustParse(string(contents))
+
+		for _, filename := range []string{"../samples/001_one_line.txt", "../samples/001_grid.txt"} {
+			contents, err := os.ReadFile(filename)
+			if err != nil {
+				panic(fmt.Sprintf("Unable to read file %s", filename))
+			}
+
+			assert.Equal(t, expected.Board, mustParse(string(contents)).Board, filename)
+		}
+
+		// a printed board can be read back in
+		assert.Equal(t, expected.Board, mustParse(expected.PrettyString()).Board)
+		assert.Equal(t, expected.Board, mustParse("\r\n"+strings.ReplaceAll(expected.String(), "\n", "\r\n")).Board)
+	})
+
+	t.Run("symbols grouped by sector", func(t *testing.T) {
+		puzzle := mustParse("12 __\n_4 2.\n+--+--+\n|AB|cd|\n")
+
+		assert.Equal(t, [][]int{{1, 2, 0, 0}, {0, 4, 2, 0}, {10, 11, 12, 13}}, puzzle.Board)
+	})
+
+	t.Run("one line with a blank line and constraints after it", func(t *testing.T) {
+		puzzle := mustParse("1..4 .... .... 4..1\n\nconstraints: diagonal\n")
+
+		assert.Equal(t, [][]int{{1, 0, 0, 4}, {0, 0, 0, 0}, {0, 0, 0, 0}, {4, 0, 0, 1}}, puzzle.Board)
+		assert.Equal(t, []sudoku.Constraint{sudoku.Diagonal{}}, puzzle.Constraints)
+	})
+
+	t.Run("bands of rows split by blank lines", func(t *testing.T) {
+		contents, err := os.ReadFile("../samples/jigsaw.txt")
+		if err != nil {
+			panic("Unable to read file ../samples/jigsaw.txt")
+		}
+		expected := mustParse(string(contents))
+
+		rows := strings.Split(string(contents), "\n")
+		banded := strings.Join(rows[0:3], "\n") + "\n\n" + strings.Join(rows[3:6], "\n") + "\n\n" + strings.Join(rows[6:], "\n")
+		puzzle := mustParse(banded)
+
+		assert.Equal(t, expected.Board, puzzle.Board)
+		assert.Equal(t, expected.Regions, puzzle.Regions)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		tests := []struct {
+			contents              string
+			expectedRow           int
+			expectedErrorContains string
+		}{
+			{contents: "", expectedErrorContains: "the puzzle is empty"},
+			{contents: " \n\n", expectedErrorContains: "the puzzle is empty"},
+			{contents: "123\n4*6\n", expectedRow: 2, expectedErrorContains: "row 2: Unrecognized value '*'"},
+			{contents: "1234567\n", expectedErrorContains: "a puzzle on one line has 7 cells"},
+			{contents: "1234\n\nthermo: r1c1\n", expectedErrorContains: "the thermo line 'thermo: r1c1' needs at least 2 cells"},
+			{contents: "1234\n\naa..\na=3 b\n", expectedErrorContains: "invalid cage sum 'b', expected label=sum"},
+		}
+
+		for _, tt := range tests {
+			_, err := Parse(tt.contents)
+
+			assert.ErrorContains(t, err, tt.expectedErrorContains, tt.contents)
+			var parseErr *ParseError
+			if assert.ErrorAs(t, err, &parseErr, tt.contents) {
+				assert.Equal(t, tt.expectedRow, parseErr.Row, tt.contents)
+			}
+		}
+	})
+
+	t.Run("regions after a blank line", func(t *testing.T) {
+		puzzle := mustParse("\n1200\n0000\n0000\n0021\n\naabb\nabbb\nacdd\ncccd\n")
+
+		assert.Equal(t, [][]int{{1, 2, 0, 0}, {0, 0, 0, 0}, {0, 0, 0, 0}, {0, 0, 2, 1}}, puzzle.Board)
+		assert.Equal(t, [][]int{{0, 0, 1, 1}, {0, 1, 1, 1}, {0, 2, 3, 3}, {2, 2, 2, 3}}, puzzle.Regions)
+	})
+
+	t.Run("cages after a blank line", func(t *testing.T) {
+		puzzle := mustParse("1000\n0000\n0000\n0000\n\naab.\nccb.\n....\n....\na=3 b=7\nc=5\n")
+
+		assert.Nil(t, puzzle.Regions)
+		assert.Equal(t, []sudoku.Cage{
+			{Sum: 3, Cells: []sudoku.Position{{Row: 0, Cell: 0}, {Row: 0, Cell: 1}}, Label: "a"},
+			{Sum: 7, Cells: []sudoku.Position{{Row: 0, Cell: 2}, {Row: 1, Cell: 2}}, Label: "b"},
+			{Sum: 5, Cells: []sudoku.Position{{Row: 1, Cell: 0}, {Row: 1, Cell: 1}}, Label: "c"},
+		}, puzzle.Cages)
+	})
+
+	t.Run("regions and cages", func(t *testing.T) {
+		puzzle := mustParse("0000\n0000\n0000\n0000\n\naabb\nabbb\nacdd\ncccd\n\nxx..\n....\n....\n....\nx=3\n")
+
+		assert.Equal(t, [][]int{{0, 0, 1, 1}, {0, 1, 1, 1}, {0, 2, 3, 3}, {2, 2, 2, 3}}, puzzle.Regions)
+		assert.Len(t, puzzle.Cages, 1)
+	})
+
+	t.Run("constraints after a blank line", func(t *testing.T) {
+		puzzle := mustParse("0000\n0000\n0000\n0000\n\nconstraints: diagonal, anti-king\n")
+
+		assert.Nil(t, puzzle.Regions)
+		assert.Equal(t, []sudoku.Constraint{sudoku.Diagonal{}, sudoku.AntiKing{}}, puzzle.Constraints)
+	})
+
+	t.Run("constraint lines after regions", func(t *testing.T) {
+		puzzle := mustParse("0000\n0000\n0000\n0000\n\naabb\naabb\nccdd\nccdd\n\nthermo: r1c1 r2c2\nwhite: r4c3 r4c4\n")
+
+		assert.Len(t, puzzle.Regions, 4)
+		assert.Equal(t, []sudoku.Constraint{
+			sudoku.Thermometer{Path: []sudoku.Position{{Row: 0, Cell: 0}, {Row: 1, Cell: 1}}},
+			sudoku.KropkiDot{Pair: [2]sudoku.Position{{Row: 3, Cell: 2}, {Row: 3, Cell: 3}}},
+		}, puzzle.Constraints)
+	})
+}
@@ -0,0 +1,103 @@
+package solver
+
+import (
+	"github.com/jbranchaud/go-sudoku/sudoku"
+)
+
+// Repeatedly fill in naked singles (a cell with only one possible value) and
+// hidden singles (a value with only one possible cell in a row, column, or
+// sector) until nothing else is forced. Returns the number of placements made
+// and whether the puzzle is still consistent, i.e. every empty cell still has
+// a possible value and every missing value still has a possible cell.
+func propagateSingles(puzzle *sudoku.Puzzle, level int, options Options, diagnostics *Diagnostics) (int, bool) {
+	layout := puzzle.Layout()
+	size := layout.Size()
+	units := layout.Units()
+	placements := 0
+
+	place := func(row int, cell int, value int, technique string) {
+		puzzle.PlaceValue(row, cell, value)
+		placements++
+
+		if options.Debug {
+			options.debugf("%d) propagating %d at (%d,%d) as a %s\n", level, value, row, cell, technique)
+		}
+	}
+
+	for progress := true; progress; {
+		progress = false
+
+		for row := range size {
+			for cell := range size {
+				if puzzle.ValueAt(row, cell) != 0 {
+					continue
+				}
+
+				candidates := puzzle.Candidates(row, cell)
+				switch candidates.Count() {
+				case 0:
+					return placements, false
+				case 1:
+					place(row, cell, candidates.Values()[0], "naked single")
+					(*diagnostics).NakedSingleCount++
+					progress = true
+				}
+			}
+		}
+
+		for _, unit := range units {
+			var placed, seenOnce, seenTwice sudoku.CandidateMask
+			for _, position := range unit {
+				value := puzzle.ValueAt(position.Row, position.Cell)
+				if value != 0 {
+					placed |= sudoku.MaskFor(value)
+					continue
+				}
+
+				candidates := puzzle.Candidates(position.Row, position.Cell)
+				seenTwice |= seenOnce & candidates
+				seenOnce |= candidates
+			}
+
+			if placed|seenOnce != layout.AllValuesMask() {
+				// some value has nowhere left to go in this unit
+				return placements, false
+			}
+
+			hiddenSingles := seenOnce &^ seenTwice &^ placed
+			for _, value := range hiddenSingles.Values() {
+				for _, position := range unit {
+					if puzzle.ValueAt(position.Row, position.Cell) != 0 {
+						continue
+					}
+					if !puzzle.Candidates(position.Row, position.Cell).Has(value) {
+						continue
+					}
+
+					place(position.Row, position.Cell, value, "hidden single")
+					(*diagnostics).HiddenSingleCount++
+					progress = true
+					break
+				}
+			}
+
+			// two values that can only go in the same cell leave one of them
+			// without a home, so check the unit again after placing
+			for _, value := range hiddenSingles.Values() {
+				found := false
+				for _, position := range unit {
+					if puzzle.ValueAt(position.Row, position.Cell) == value {
+						found = true
+						break
+					}
+				}
+
+				if !found {
+					return placements, false
+				}
+			}
+		}
+	}
+
+	return placements, true
+}
@@ -0,0 +1,75 @@
+package solver
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPropagateSingles(t *testing.T) {
+	t.Run("fills in a puzzle that only needs singles", func(t *testing.T) {
+		contents, err := os.ReadFile("../samples/001.txt")
+		if err != nil {
+			panic("Unable to read file ../samples/001.txt")
+		}
+
+		puzzle := mustParse(string(contents))
+		diagnostics := Diagnostics{}
+		placements, consistent := propagateSingles(&puzzle, 1, Options{}, &diagnostics)
+
+		assert.True(t, consistent)
+		assert.Equal(t, 0, puzzle.EmptyCellCount())
+		assert.Equal(t, placements, diagnostics.PropagatedCount())
+		assert.Equal(t, Solved, checkPuzzleStatus(puzzle))
+	})
+
+	t.Run("reports a cell with no possible values", func(t *testing.T) {
+		puzzle := mustParse(`123456780
+000000009
+000000000
+000000000
+000000000
+000000000
+000000000
+000000000
+000000000`)
+
+		_, consistent := propagateSingles(&puzzle, 1, Options{}, &Diagnostics{})
+
+		assert.False(t, consistent)
+	})
+}
+
+func TestTraversePuzzleWithPropagation(t *testing.T) {
+	tests := []struct {
+		filename          string
+		expectedSolutions int
+	}{
+		{filename: "../samples/001.txt", expectedSolutions: 1},
+		{filename: "../samples/two_solutions.txt", expectedSolutions: 2},
+		{filename: "../samples/four_solutions.txt", expectedSolutions: 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.filename, func(t *testing.T) {
+			contents, err := os.ReadFile(tt.filename)
+			if err != nil {
+				panic(fmt.Sprintf("Unable to read file %s", tt.filename))
+			}
+
+			puzzle := mustParse(string(contents))
+
+			options := NewOptions(false, FindAll, InOrder, nil)
+			options.Propagate = true
+			_, _, diagnostics := traversePuzzle(puzzle, 1, options, &Diagnostics{})
+
+			withoutPropagation := NewOptions(false, FindAll, InOrder, nil)
+			_, _, expected := traversePuzzle(puzzle, 1, withoutPropagation, &Diagnostics{})
+
+			assert.Equal(t, tt.expectedSolutions, diagnostics.SolutionsFound)
+			assert.ElementsMatch(t, expected.Solutions, diagnostics.Solutions)
+		})
+	}
+}
@@ -0,0 +1,475 @@
+package solver
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/jbranchaud/go-sudoku/sudoku"
+)
+
+type SATResult struct {
+	Satisfiable bool
+	// indexed by variable, index 0 is unused
+	Model []bool
+
+	Decisions    int
+	Conflicts    int
+	Propagations int
+}
+
+// A solver stops with the context's error once the context is done, and
+// reports the work it did up until then.
+type SATSolver interface {
+	Solve(ctx context.Context, cnf CNF) (SATResult, error)
+}
+
+// A DPLL solver with two watched literals per clause for unit propagation.
+// Branching picks the unsatisfied clause with the fewest unassigned literals
+// and tries its first unassigned positive literal as true, then as false.
+type DPLLSolver struct {
+	// stop with ErrNodeLimit instead of making more decisions than this, no
+	// limit if unset
+	MaxDecisions int
+}
+
+type dpllState struct {
+	cnf CNF
+	// 1 for true, -1 for false, 0 for unassigned, indexed by variable
+	assignments []int8
+	// clause indexes watching each literal, see literalIndex
+	watches [][]int
+	trail   []int
+	// trail position of each decision, and whether its negation was already
+	// tried
+	decisionPositions []int
+	decisionFlipped   []bool
+	propagateFrom     int
+
+	result SATResult
+}
+
+func literalIndex(literal int) int {
+	if literal > 0 {
+		return 2 * literal
+	}
+
+	return -2*literal + 1
+}
+
+func (state *dpllState) valueOf(literal int) int8 {
+	value := state.assignments[max(literal, -literal)]
+	if literal < 0 {
+		return -value
+	}
+
+	return value
+}
+
+func (state *dpllState) assign(literal int) {
+	if literal > 0 {
+		state.assignments[literal] = 1
+	} else {
+		state.assignments[-literal] = -1
+	}
+	state.trail = append(state.trail, literal)
+}
+
+// Make every assignment implied by the trail so far. Returns false when some
+// clause has all of its literals false.
+func (state *dpllState) propagate() bool {
+	for state.propagateFrom < len(state.trail) {
+		falseLiteral := -state.trail[state.propagateFrom]
+		state.propagateFrom++
+		state.result.Propagations++
+
+		watching := state.watches[literalIndex(falseLiteral)]
+		kept := watching[:0]
+
+		for i, clauseIndex := range watching {
+			clause := state.cnf.Clauses[clauseIndex]
+
+			// keep the false literal in the second watched slot
+			if clause[0] == falseLiteral {
+				clause[0], clause[1] = clause[1], clause[0]
+			}
+
+			if state.valueOf(clause[0]) == 1 {
+				kept = append(kept, clauseIndex)
+				continue
+			}
+
+			moved := false
+			for k := 2; k < len(clause); k++ {
+				if state.valueOf(clause[k]) != -1 {
+					clause[1], clause[k] = clause[k], clause[1]
+					state.watches[literalIndex(clause[1])] = append(state.watches[literalIndex(clause[1])], clauseIndex)
+					moved = true
+					break
+				}
+			}
+			if moved {
+				continue
+			}
+
+			kept = append(kept, clauseIndex)
+			switch state.valueOf(clause[0]) {
+			case -1:
+				// conflict, keep the rest of the watches before bailing
+				kept = append(kept, watching[i+1:]...)
+				state.watches[literalIndex(falseLiteral)] = kept
+				return false
+			case 0:
+				state.assign(clause[0])
+			}
+		}
+
+		state.watches[literalIndex(falseLiteral)] = kept
+	}
+
+	return true
+}
+
+func (state *dpllState) chooseLiteral() int {
+	best := 0
+	bestUnassigned := 0
+	fallback := 0
+
+	for _, clause := range state.cnf.Clauses {
+		unassigned := 0
+		firstPositive := 0
+		satisfied := false
+		for _, literal := range clause {
+			value := state.valueOf(literal)
+			if value == 1 {
+				satisfied = true
+				break
+			}
+			if value == 0 {
+				unassigned++
+				if literal > 0 && firstPositive == 0 {
+					firstPositive = literal
+				}
+				if fallback == 0 {
+					fallback = literal
+				}
+			}
+		}
+
+		// setting a variable true says a lot more than setting it false
+		// (placing a value vs ruling one out), so only clauses with a
+		// positive literal left are worth branching on
+		if satisfied || firstPositive == 0 {
+			continue
+		}
+		if best == 0 || unassigned < bestUnassigned {
+			best = firstPositive
+			bestUnassigned = unassigned
+		}
+	}
+
+	if best != 0 {
+		return best
+	}
+	if fallback != 0 {
+		return fallback
+	}
+
+	// every clause is satisfied, any leftover variable can be anything
+	for variable := 1; variable <= state.cnf.VariableCount; variable++ {
+		if state.assignments[variable] == 0 {
+			return -variable
+		}
+	}
+
+	return 0
+}
+
+// Undo the most recent decision that hasn't had its negation tried yet, and
+// try the negation. Returns false once every decision has been flipped.
+func (state *dpllState) backtrack() bool {
+	for len(state.decisionPositions) > 0 {
+		last := len(state.decisionPositions) - 1
+		position := state.decisionPositions[last]
+		decision := state.trail[position]
+		flipped := state.decisionFlipped[last]
+
+		for _, literal := range state.trail[position:] {
+			state.assignments[max(literal, -literal)] = 0
+		}
+		state.trail = state.trail[:position]
+		state.propagateFrom = position
+
+		if flipped {
+			state.decisionPositions = state.decisionPositions[:last]
+			state.decisionFlipped = state.decisionFlipped[:last]
+			continue
+		}
+
+		state.decisionFlipped[last] = true
+		state.assign(-decision)
+		return true
+	}
+
+	return false
+}
+
+func (solver DPLLSolver) Solve(ctx context.Context, cnf CNF) (SATResult, error) {
+	state := &dpllState{
+		cnf:         CNF{VariableCount: cnf.VariableCount},
+		assignments: make([]int8, cnf.VariableCount+1),
+		watches:     make([][]int, 2*cnf.VariableCount+2),
+	}
+
+	// the watched literals get reordered, so work on a copy of the clauses
+	units := []int{}
+	for _, clause := range cnf.Clauses {
+		switch len(clause) {
+		case 0:
+			return state.result, nil
+		case 1:
+			units = append(units, clause[0])
+		default:
+			clauseIndex := len(state.cnf.Clauses)
+			state.cnf.Clauses = append(state.cnf.Clauses, append([]int{}, clause...))
+			state.watches[literalIndex(clause[0])] = append(state.watches[literalIndex(clause[0])], clauseIndex)
+			state.watches[literalIndex(clause[1])] = append(state.watches[literalIndex(clause[1])], clauseIndex)
+		}
+	}
+
+	for _, literal := range units {
+		switch state.valueOf(literal) {
+		case -1:
+			return state.result, nil
+		case 0:
+			state.assign(literal)
+		}
+	}
+
+	for {
+		if !state.propagate() {
+			state.result.Conflicts++
+			if !state.backtrack() {
+				return state.result, nil
+			}
+			continue
+		}
+
+		literal := state.chooseLiteral()
+		if literal == 0 {
+			break
+		}
+
+		if solver.MaxDecisions > 0 && state.result.Decisions >= solver.MaxDecisions {
+			return state.result, ErrNodeLimit
+		}
+		if state.result.Decisions%contextCheckInterval == 0 && ctx.Err() != nil {
+			return state.result, ctx.Err()
+		}
+
+		state.result.Decisions++
+		state.decisionPositions = append(state.decisionPositions, len(state.trail))
+		state.decisionFlipped = append(state.decisionFlipped, false)
+		state.assign(literal)
+	}
+
+	state.result.Satisfiable = true
+	state.result.Model = make([]bool, cnf.VariableCount+1)
+	for variable := 1; variable <= cnf.VariableCount; variable++ {
+		state.result.Model[variable] = state.assignments[variable] == 1
+	}
+
+	return state.result, nil
+}
+
+// Hands the CNF to a separate SAT solver program, e.g. kissat or cadical. The
+// program is run with the path to a DIMACS file as its final argument and is
+// expected to report its answer on stdout in the SAT competition format:
+//
+//	s SATISFIABLE
+//	v 1 -2 3 ... 0
+type ExternalSATSolver struct {
+	Command []string
+}
+
+// The program is killed once the context is done.
+func (solver ExternalSATSolver) Solve(ctx context.Context, cnf CNF) (SATResult, error) {
+	if len(solver.Command) == 0 {
+		return SATResult{}, fmt.Errorf("No external SAT solver command given")
+	}
+
+	file, err := os.CreateTemp("", "go-sudoku-*.cnf")
+	if err != nil {
+		return SATResult{}, err
+	}
+	defer os.Remove(file.Name())
+
+	err = cnf.WriteDIMACS(file)
+	file.Close()
+	if err != nil {
+		return SATResult{}, err
+	}
+
+	args := append(append([]string{}, solver.Command[1:]...), file.Name())
+	var stdout bytes.Buffer
+	cmd := exec.CommandContext(ctx, solver.Command[0], args...)
+	cmd.Stdout = &stdout
+
+	// SAT solvers conventionally exit with 10 (satisfiable) or 20
+	// (unsatisfiable), so the exit code alone isn't an error
+	runErr := cmd.Run()
+	if ctx.Err() != nil {
+		return SATResult{}, ctx.Err()
+	}
+
+	result, err := parseSATCompetitionOutput(stdout.String(), cnf.VariableCount)
+	if err != nil {
+		if runErr != nil {
+			return SATResult{}, fmt.Errorf("%v (%v)", err, runErr)
+		}
+		return SATResult{}, err
+	}
+
+	return result, nil
+}
+
+func parseSATCompetitionOutput(output string, variableCount int) (SATResult, error) {
+	result := SATResult{}
+	status := ""
+
+	model := make([]bool, variableCount+1)
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+
+		switch fields[0] {
+		case "s":
+			status = strings.Join(fields[1:], " ")
+		case "v":
+			for _, field := range fields[1:] {
+				literal, err := strconv.Atoi(field)
+				if err != nil {
+					return SATResult{}, fmt.Errorf("Invalid literal '%s' in SAT solver output", field)
+				}
+				if literal > 0 && literal <= variableCount {
+					model[literal] = true
+				}
+			}
+		}
+	}
+
+	switch status {
+	case "SATISFIABLE":
+		result.Satisfiable = true
+		result.Model = model
+	case "UNSATISFIABLE":
+		result.Satisfiable = false
+	default:
+		return SATResult{}, fmt.Errorf("SAT solver output is missing an `s SATISFIABLE` or `s UNSATISFIABLE` line")
+	}
+
+	return result, nil
+}
+
+// The SAT solver the options pick, with the decisions a built-in one can make.
+// There's no telling an external one how many it can make, only how long it
+// can run for.
+func satSolverFor(options Options, maxDecisions int) SATSolver {
+	if len(options.SATCommand) > 0 {
+		return ExternalSATSolver{Command: strings.Fields(options.SATCommand)}
+	}
+
+	return DPLLSolver{MaxDecisions: maxDecisions}
+}
+
+// Solve the puzzle by encoding it as CNF. This follows the same TraversalType
+// semantics as traversePuzzle: after each solution, a clause ruling out that
+// exact solution is added and the solver runs again. Nodes are the SAT
+// solver's decisions, backtracks are its conflicts, and validity checks are
+// its unit propagations. An error comes back if the SAT solver itself fails,
+// e.g. an external one that can't be run. The solver is stopped once the
+// search runs into its limits, see Solve.
+func solveWithSAT(puzzle sudoku.Puzzle, options Options) (PuzzleStatus, sudoku.Puzzle, Diagnostics, error) {
+	diagnostics := Diagnostics{}
+
+	status := checkPuzzleStatus(puzzle)
+	diagnostics.ValidityCheckCount++
+	if status == Invalid {
+		return Invalid, puzzle, diagnostics, nil
+	}
+
+	cnf := EncodeCNF(puzzle)
+	var firstSolution sudoku.Puzzle
+
+	for {
+		// each run of the solver gets what's left of the node budget
+		maxDecisions := 0
+		if options.MaxNodes > 0 {
+			maxDecisions = options.MaxNodes - diagnostics.NodeVisitCount
+			if maxDecisions <= 0 {
+				return Aborted, puzzle, diagnostics, nil
+			}
+		}
+		solver := satSolverFor(options, maxDecisions)
+
+		result, err := solver.Solve(options.context(), cnf)
+		diagnostics.NodeVisitCount += result.Decisions
+		diagnostics.BacktrackCount += result.Conflicts
+		diagnostics.ValidityCheckCount += result.Propagations
+
+		if errors.Is(err, ErrNodeLimit) || (err != nil && options.context().Err() != nil) {
+			return Aborted, puzzle, diagnostics, nil
+		}
+		if err != nil {
+			return Invalid, puzzle, diagnostics, &SolverError{Solver: SAT, Err: err}
+		}
+
+		if !result.Satisfiable {
+			break
+		}
+
+		solved := puzzleFromModel(puzzle, result.Model)
+		if diagnostics.SolutionsFound == 0 {
+			firstSolution = solved
+		}
+		diagnostics.SolutionsFound++
+		diagnostics.Solutions = append(diagnostics.Solutions, solved.String())
+
+		if options.Debug {
+			options.debugf("found solution %d after %d decisions\n", diagnostics.SolutionsFound, result.Decisions)
+		}
+
+		if options.TraversalType == FindFirst {
+			break
+		}
+		if options.TraversalType == EnsureUnique && diagnostics.SolutionsFound > 1 {
+			break
+		}
+
+		// rule out this solution by requiring a different value in at least
+		// one of the cells that was empty
+		blockingClause := []int{}
+		for _, placement := range solved.Solution[len(puzzle.Solution):] {
+			blockingClause = append(blockingClause, -cnfVariable(puzzle.Size(), placement.Row, placement.Cell, placement.Value))
+		}
+		if len(blockingClause) == 0 {
+			break
+		}
+		cnf.Clauses = append(cnf.Clauses, blockingClause)
+	}
+
+	if diagnostics.SolutionsFound == 0 {
+		return Invalid, puzzle, diagnostics, nil
+	}
+
+	return Solved, firstSolution, diagnostics, nil
+}
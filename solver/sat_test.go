@@ -0,0 +1,114 @@
+package solver
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDPLLSolver(t *testing.T) {
+	t.Run("satisfiable", func(t *testing.T) {
+		cnf := CNF{VariableCount: 3, Clauses: [][]int{{1, 2}, {-1, 3}, {-3, -2}, {-1, -2}}}
+
+		result, err := DPLLSolver{}.Solve(context.Background(), cnf)
+		assert.NoError(t, err)
+		assert.True(t, result.Satisfiable)
+
+		for _, clause := range cnf.Clauses {
+			satisfied := false
+			for _, literal := range clause {
+				if (literal > 0) == result.Model[max(literal, -literal)] {
+					satisfied = true
+				}
+			}
+			assert.True(t, satisfied, "clause %v is not satisfied", clause)
+		}
+	})
+
+	t.Run("unsatisfiable", func(t *testing.T) {
+		cnf := CNF{VariableCount: 2, Clauses: [][]int{{1, 2}, {-1, 2}, {1, -2}, {-1, -2}}}
+
+		result, err := DPLLSolver{}.Solve(context.Background(), cnf)
+		assert.NoError(t, err)
+		assert.False(t, result.Satisfiable)
+	})
+}
+
+func TestSolveWithSAT(t *testing.T) {
+	tests := []struct {
+		filename       string
+		traversalType  TraversalType
+		expectedStatus PuzzleStatus
+	}{
+		{filename: "../samples/001.txt", traversalType: EnsureUnique, expectedStatus: Solved},
+		{filename: "../samples/two_solutions.txt", traversalType: EnsureUnique, expectedStatus: Solved},
+		{filename: "../samples/four_solutions.txt", traversalType: FindAll, expectedStatus: Solved},
+		{filename: "../samples/invalid_column.txt", traversalType: FindAll, expectedStatus: Invalid},
+		{filename: "../samples/4x4.txt", traversalType: FindAll, expectedStatus: Solved},
+		{filename: "../samples/6x6.txt", traversalType: EnsureUnique, expectedStatus: Solved},
+		{filename: "../samples/jigsaw.txt", traversalType: EnsureUnique, expectedStatus: Solved},
+		{filename: "../samples/killer_6x6.txt", traversalType: FindAll, expectedStatus: Solved},
+		{filename: "../samples/invalid_killer.txt", traversalType: FindAll, expectedStatus: Invalid},
+		{filename: "../samples/diagonal.txt", traversalType: EnsureUnique, expectedStatus: Solved},
+		{filename: "../samples/invalid_diagonal.txt", traversalType: FindAll, expectedStatus: Invalid},
+		{filename: "../samples/anti_king_6x6.txt", traversalType: FindAll, expectedStatus: Solved},
+		{filename: "../samples/thermo_arrow.txt", traversalType: EnsureUnique, expectedStatus: Solved},
+		{filename: "../samples/kropki_xv_6x6.txt", traversalType: EnsureUnique, expectedStatus: Solved},
+		{filename: "../samples/invalid_thermo.txt", traversalType: FindAll, expectedStatus: Invalid},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%s with %s", tt.filename, tt.traversalType), func(t *testing.T) {
+			contents, err := os.ReadFile(tt.filename)
+			if err != nil {
+				panic(fmt.Sprintf("Unable to read file %s", tt.filename))
+			}
+
+			puzzle := mustParse(string(contents))
+			options := NewOptions(false, tt.traversalType, InOrder, nil)
+
+			_, _, expected := traversePuzzle(puzzle, 1, options, &Diagnostics{})
+			status, solved, diagnostics, err := solveWithSAT(puzzle, options)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedStatus, status)
+			assert.Equal(t, expected.SolutionsFound, diagnostics.SolutionsFound)
+			if tt.traversalType == FindAll {
+				assert.ElementsMatch(t, expected.Solutions, diagnostics.Solutions)
+			}
+			if status == Solved {
+				assert.Equal(t, Solved, checkPuzzleStatus(solved))
+			}
+		})
+	}
+}
+
+func TestParseSATCompetitionOutput(t *testing.T) {
+	result, err := parseSATCompetitionOutput("c some solver chatter\ns SATISFIABLE\nv 1 -2\nv 3 0\n", 3)
+	assert.NoError(t, err)
+	assert.True(t, result.Satisfiable)
+	assert.Equal(t, []bool{false, true, false, true}, result.Model)
+
+	result, err = parseSATCompetitionOutput("s UNSATISFIABLE\n", 3)
+	assert.NoError(t, err)
+	assert.False(t, result.Satisfiable)
+
+	_, err = parseSATCompetitionOutput("c nothing useful\n", 3)
+	assert.Error(t, err)
+}
+
+func TestExternalSATSolver(t *testing.T) {
+	script := filepath.Join(t.TempDir(), "fake-solver")
+	err := os.WriteFile(script, []byte("#!/bin/sh\necho 's UNSATISFIABLE'\nexit 20\n"), 0o755)
+	if err != nil {
+		panic(err)
+	}
+
+	result, err := ExternalSATSolver{Command: []string{script}}.Solve(context.Background(), CNF{VariableCount: 1, Clauses: [][]int{{1}, {-1}}})
+	assert.NoError(t, err)
+	assert.False(t, result.Satisfiable)
+}
@@ -0,0 +1,490 @@
+// Package solver reads, checks, solves, and generates sudoku puzzles, see the
+// sudoku package for the puzzles themselves.
+//
+// Nothing here prints or exits: each function returns what it found, with a
+// ParseError, ValidationError, or SolverError for a puzzle that can't be
+// read, breaks the rules, or that a SAT solver failed on. Debug output, if
+// it's turned on with Options.Debug, goes to Options.Log, or to stdout if
+// that's unset.
+package solver
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math/rand"
+	"slices"
+	"time"
+
+	"github.com/jbranchaud/go-sudoku/sudoku"
+)
+
+// What a search is looking for, and so when it can stop.
+type TraversalType string
+
+const (
+	FindFirst    TraversalType = "FindFirst"
+	EnsureUnique TraversalType = "EnsureUnique"
+	FindAll      TraversalType = "FindAll"
+)
+
+var TraversalTypes = []TraversalType{FindFirst, EnsureUnique, FindAll}
+
+// How a puzzle is solved, or generated. The zero value finds the first
+// solution of a puzzle with the backtracking solver, see NewOptions for
+// options that search in a random order.
+type Options struct {
+	Debug         bool
+	TraversalType TraversalType
+	SolveOrder    Order
+	Strategy      CellStrategy
+	Solver        SolverType
+	SATCommand    string
+	Propagate     bool
+	Seed          int64
+	Rng           *rand.Rand
+	// where debug output goes, stdout if unset
+	Log io.Writer
+	// how many goroutines the backtracking solver searches with, one if
+	// unset, see solveInParallel
+	Workers int
+	// the worker of a parallel search that this traversal belongs to
+	worker *searchWorker
+	// how long a search can run, and how many nodes it can visit, before
+	// it's aborted, no limit if unset
+	Timeout  time.Duration
+	MaxNodes int
+	// the limits of the search that this traversal belongs to, see
+	// Solve
+	limits *searchLimits
+	// the difficulty a generated puzzle has to be graded at, any if empty
+	Difficulty sudoku.Difficulty
+	// which clues of a generated puzzle are removed together
+	Symmetry Symmetry
+	// whether every clue of a generated puzzle has to be needed
+	Minimal bool
+	// the sector shape of a board that's generated from scratch, a standard
+	// 9x9 board if unset
+	Shape sudoku.Shape
+	// the variant constraints a board generated from scratch has to follow
+	Constraints []sudoku.Constraint
+}
+
+func NewOptions(debug bool, traversalType TraversalType, solveOrder Order, seedFromFlag *int64) Options {
+	options := Options{
+		Debug:         debug,
+		SolveOrder:    solveOrder,
+		Strategy:      FirstEmpty,
+		Solver:        Backtracking,
+		TraversalType: traversalType,
+	}
+
+	if solveOrder == Shuffled {
+		var seed int64
+
+		if seedFromFlag == nil {
+			seed = rand.Int63()
+		} else {
+			seed = *seedFromFlag
+		}
+
+		options.Seed = seed
+		options.Rng = rand.New(rand.NewSource(seed))
+	}
+
+	return options
+}
+
+// A copy of the options with its own random number generator from the given
+// seed, so that it can be used alongside the original one, e.g. by another
+// goroutine, without the two sharing any state. Options that don't shuffle
+// don't have a generator to copy.
+func (options Options) reseeded(seed int64) Options {
+	if options.Rng != nil {
+		options.Seed = seed
+		options.Rng = rand.New(rand.NewSource(seed))
+	}
+
+	return options
+}
+
+func (options Options) debugf(format string, args ...any) {
+	if options.Log == nil {
+		fmt.Printf(format, args...)
+		return
+	}
+
+	fmt.Fprintf(options.Log, format, args...)
+}
+
+// The options with whatever was left empty filled in, e.g. the first
+// solution for an unset TraversalType and a generator seeded from Seed for a
+// Shuffled search without one, or an error for a value that isn't one of the
+// ones there are.
+func (options Options) withDefaults() (Options, error) {
+	if options.TraversalType == "" {
+		options.TraversalType = FindFirst
+	}
+	if !slices.Contains(TraversalTypes, options.TraversalType) {
+		return options, fmt.Errorf("Unrecognized traversal type '%s', expected one of %v", options.TraversalType, TraversalTypes)
+	}
+
+	if options.Solver == "" {
+		options.Solver = Backtracking
+	}
+	_, err := ParseSolverType(string(options.Solver))
+	if err != nil {
+		return options, err
+	}
+
+	if options.Strategy == "" {
+		options.Strategy = FirstEmpty
+	}
+	_, err = ParseCellStrategy(string(options.Strategy))
+	if err != nil {
+		return options, err
+	}
+
+	switch options.SolveOrder {
+	case "":
+		options.SolveOrder = InOrder
+	case InOrder:
+	case Shuffled:
+		if options.Rng == nil {
+			options.Rng = rand.New(rand.NewSource(options.Seed))
+		}
+	default:
+		return options, fmt.Errorf("Unrecognized solve order '%s', expected one of %v", options.SolveOrder, []Order{InOrder, Shuffled})
+	}
+
+	return options, nil
+}
+
+type SolverType string
+
+const (
+	Backtracking SolverType = "backtracking"
+	DLX          SolverType = "dlx"
+	SAT          SolverType = "sat"
+)
+
+var SolverTypes = []SolverType{Backtracking, DLX, SAT}
+
+func ParseSolverType(value string) (SolverType, error) {
+	for _, solver := range SolverTypes {
+		if string(solver) == value {
+			return solver, nil
+		}
+	}
+
+	return "", fmt.Errorf("Unrecognized solver '%s', expected one of %v", value, SolverTypes)
+}
+
+// Solve the puzzle with the solver the options pick, which can be any
+// options, even ones left empty, see Options.withDefaults. A puzzle that
+// breaks the rules is Invalid with the ValidationError from Validate, one
+// that has no solution is Invalid without an error, and one that's solved
+// comes back with its solution(s) in the diagnostics.
+//
+// A search that runs out of time, the context is done, or visits
+// options.MaxNodes nodes is Aborted, with the diagnostics and any solutions
+// from before it stopped. Only the SAT solver can fail, with a SolverError
+// from the SAT solver itself.
+func Solve(ctx context.Context, puzzle sudoku.Puzzle, options Options) (PuzzleStatus, sudoku.Puzzle, Diagnostics, error) {
+	options, err := options.withDefaults()
+	if err != nil {
+		return Invalid, puzzle, Diagnostics{}, err
+	}
+
+	err = Validate(puzzle)
+	if err != nil {
+		return Invalid, puzzle, Diagnostics{ValidityCheckCount: 1}, err
+	}
+
+	options, cancel := options.withLimits(ctx)
+	defer cancel()
+
+	switch options.Solver {
+	case Backtracking:
+		if options.Workers > 1 {
+			status, solved, diagnostics := solveInParallel(puzzle, options, options.Workers)
+			return status, solved, diagnostics, nil
+		}
+		status, solved, diagnostics := traversePuzzle(puzzle, 1, options, &Diagnostics{})
+		return status, solved, diagnostics, nil
+	case DLX:
+		status, solved, diagnostics := solveWithDLX(puzzle, options)
+		return status, solved, diagnostics, nil
+	default:
+		return solveWithSAT(puzzle, options)
+	}
+}
+
+type PuzzleStatus string
+
+const (
+	Invalid PuzzleStatus = "Invalid"
+	Valid   PuzzleStatus = "Valid"
+	Solved  PuzzleStatus = "Solved"
+	// the search ran into its limits before it could finish, see
+	// Options.Timeout and Options.MaxNodes
+	Aborted PuzzleStatus = "Aborted"
+)
+
+// How much work a search did, and the solutions it found, each written the
+// way the puzzle's String method writes it.
+type Diagnostics struct {
+	BacktrackCount     int
+	NodeVisitCount     int
+	ValidityCheckCount int
+	NakedSingleCount   int
+	HiddenSingleCount  int
+	SolutionsFound     int
+	Solutions          []string
+}
+
+// Placements that were forced by propagation rather than found by searching.
+func (diagnostics Diagnostics) PropagatedCount() int {
+	return diagnostics.NakedSingleCount + diagnostics.HiddenSingleCount
+}
+
+// Add the counts of another search to these, but not its solutions.
+func (diagnostics *Diagnostics) addCounts(other Diagnostics) {
+	diagnostics.BacktrackCount += other.BacktrackCount
+	diagnostics.NodeVisitCount += other.NodeVisitCount
+	diagnostics.ValidityCheckCount += other.ValidityCheckCount
+	diagnostics.NakedSingleCount += other.NakedSingleCount
+	diagnostics.HiddenSingleCount += other.HiddenSingleCount
+}
+
+func traversePuzzle(puzzle sudoku.Puzzle, level int, options Options, diagnostics *Diagnostics) (PuzzleStatus, sudoku.Puzzle, Diagnostics) {
+	// this is a recursive function, so:
+	// initial pass => puzzle should be Valid
+	// cell is filled in =>
+	// - if the value makes the puzzle invalid, Invalid
+	// - if the value is a valid value, Valid
+	// final cell is filled in =>
+	// - if the value makes the puzzle invalid, Invalid
+	// - if the value solves the puzzle, Solved
+	var status PuzzleStatus
+	if level == 1 {
+		status = checkPuzzleStatus(puzzle)
+	} else {
+		// every placement comes from the candidate masks, so the puzzle can't
+		// have become invalid since the initial check
+		status = checkPlacementStatus(puzzle)
+	}
+	// every level fills in at least one empty cell, so the traversal never
+	// goes deeper than the number of cells on the board, which Validate
+	// has checked against the board's size
+	(*diagnostics).ValidityCheckCount++
+
+	// fill in everything that is forced before branching, these placements
+	// belong to this level of the traversal and are undone along with it
+	propagatedCount := 0
+	if status == Valid && options.Propagate {
+		count, consistent := propagateSingles(&puzzle, level, options, diagnostics)
+		propagatedCount = count
+
+		if !consistent {
+			status = Invalid
+		} else {
+			status = checkPlacementStatus(puzzle)
+		}
+	}
+	undoPropagation := func() {
+		for range propagatedCount {
+			puzzle.UndoLastPlacement()
+		}
+	}
+
+	switch status {
+	case Solved:
+		// record solution in diagnostics
+		(*diagnostics).SolutionsFound++
+		(*diagnostics).Solutions = append((*diagnostics).Solutions, puzzle.String())
+
+		// the other workers of a parallel search can have found solutions
+		// of their own
+		if options.worker != nil && options.worker.search.found() {
+			return Solved, puzzle, *diagnostics
+		}
+
+		switch options.TraversalType {
+		case FindFirst:
+			return Solved, puzzle, *diagnostics
+		case EnsureUnique:
+			if (*diagnostics).SolutionsFound > 1 {
+				// essentially return early as soon as we've seen multiple solutions
+				return Solved, puzzle, *diagnostics
+			} else {
+				undoPropagation()
+				return exhaustedStatus(level, diagnostics), puzzle, *diagnostics
+			}
+		case FindAll:
+			undoPropagation()
+			return exhaustedStatus(level, diagnostics), puzzle, *diagnostics
+		default:
+			panic(fmt.Sprintf("Error: unrecognized options.TraversalType %s", options.TraversalType))
+		}
+	case Valid:
+		nextRow, nextCell, err := selectNextCell(puzzle, options)
+		if err != nil {
+			panic(fmt.Sprintf("Shouldn't reach here for valid puzzle: %v", err))
+		}
+
+		possibleValues := findPossibleValues(puzzle, nextRow, nextCell, options)
+
+		// make another puzzle placement
+		for i, value := range possibleValues {
+			if options.worker != nil && options.worker.search.stopped.Load() {
+				// another worker of a parallel search has ended it
+				break
+			}
+			if options.limits != nil && options.limits.visit() {
+				undoPropagation()
+				return Aborted, puzzle, *diagnostics
+			}
+
+			(*diagnostics).NodeVisitCount++
+			puzzle.PlaceValue(nextRow, nextCell, value)
+
+			if options.Debug {
+				options.debugf("%d) placing %d at (%d,%d) of %v\n", level, value, nextRow, nextCell, possibleValues)
+			}
+
+			if options.worker != nil && level <= parallelSplitLevels {
+				options.worker.spawn(&puzzle, level+1, i)
+				puzzle.UndoLastPlacement()
+				continue
+			}
+
+			latestStatus, latestPuzzle, _ := traversePuzzle(puzzle, level+1, options, diagnostics)
+			switch latestStatus {
+			case Solved:
+				return Solved, latestPuzzle, *diagnostics
+			case Invalid:
+				// undo latest placement, continue
+				(*diagnostics).BacktrackCount++
+				puzzle.UndoLastPlacement()
+				continue
+			case Aborted:
+				puzzle.UndoLastPlacement()
+				undoPropagation()
+				return Aborted, puzzle, *diagnostics
+			default:
+				// we shouldn't get here, something went wrong
+				panic("traversePuzzle returned an unrecognized status")
+			}
+		}
+
+		// if we haven't found a solution at this point, then we'll need to backtrack
+		undoPropagation()
+		return exhaustedStatus(level, diagnostics), puzzle, *diagnostics
+	case Invalid:
+		undoPropagation()
+		return exhaustedStatus(level, diagnostics), puzzle, *diagnostics
+	default:
+		panic("Should not have reached here when traversing puzzle")
+	}
+}
+
+// Once a branch of the traversal has nothing left to try, it needs to
+// backtrack, unless we're at the top and some solution(s) have been found.
+func exhaustedStatus(level int, diagnostics *Diagnostics) PuzzleStatus {
+	if level == 1 && (*diagnostics).SolutionsFound > 0 {
+		return Solved
+	}
+
+	return Invalid
+}
+
+func checkPuzzleStatus(puzzle sudoku.Puzzle) PuzzleStatus {
+	err := Validate(puzzle)
+	if err != nil {
+		return Invalid
+	}
+
+	_, _, err = findNextEmptyCell(puzzle)
+	if err != nil {
+		// we are valid, but there are no more empty cells
+		return Solved
+	} else {
+		return Valid
+	}
+}
+
+func checkPlacementStatus(puzzle sudoku.Puzzle) PuzzleStatus {
+	if puzzle.EmptyCellCount() == 0 {
+		return Solved
+	}
+
+	return Valid
+}
+
+func findNextEmptyCell(puzzle sudoku.Puzzle) (int, int, error) {
+	size := puzzle.Size()
+	for row := range size {
+		for cell := range size {
+			if puzzle.ValueAt(row, cell) == 0 {
+				return row, cell, nil
+			}
+		}
+	}
+
+	return -1, -1, fmt.Errorf("No more empty cells in the puzzle")
+}
+
+type Order string
+
+const (
+	InOrder  Order = "InOrder"
+	Shuffled Order = "Shuffled"
+)
+
+func findPossibleValues(puzzle sudoku.Puzzle, row int, cell int, options Options) []int {
+	possibleValues := puzzle.Candidates(row, cell).Values()
+
+	if options.SolveOrder == Shuffled {
+		Shuffle(possibleValues, options.Rng)
+	}
+
+	return possibleValues
+}
+
+func listMissingValues(section []int) []int {
+	missingValues := []int{}
+
+	for i := range len(section) {
+		val := i + 1
+		seen := false
+		for _, cell := range section {
+			if cell == val {
+				seen = true
+				break
+			}
+		}
+
+		if !seen {
+			missingValues = append(missingValues, val)
+		}
+	}
+
+	return missingValues
+}
+
+// The puzzle with one of its solutions filled in, from the solution's string,
+// e.g. the first of Diagnostics.Solutions. A string that can't be read as a
+// board gives a ParseError.
+func SolutionOf(puzzle sudoku.Puzzle, solution string) (sudoku.Puzzle, error) {
+	solvedPuzzle, err := Parse(solution)
+	if err != nil {
+		return sudoku.Puzzle{}, err
+	}
+	solvedPuzzle.Shape = puzzle.Shape
+	solvedPuzzle.Regions = puzzle.Regions
+	solvedPuzzle.Cages = puzzle.Cages
+	solvedPuzzle.Constraints = puzzle.Constraints
+
+	return solvedPuzzle, nil
+}
@@ -0,0 +1,106 @@
+package solver
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSolve(t *testing.T) {
+	contents, err := os.ReadFile("../samples/001.txt")
+	if err != nil {
+		panic("Unable to read file ../samples/001.txt")
+	}
+	puzzle := mustParse(string(contents))
+
+	t.Run("options left empty", func(t *testing.T) {
+		status, solved, diagnostics, err := Solve(context.Background(), puzzle, Options{})
+
+		assert.NoError(t, err)
+		assert.Equal(t, Solved, status)
+		assert.Equal(t, 1, diagnostics.SolutionsFound)
+		assert.Equal(t, diagnostics.Solutions[0], solved.String())
+		solution, err := SolutionOf(puzzle, diagnostics.Solutions[0])
+		assert.NoError(t, err)
+		assert.Equal(t, solved.String(), solution.String())
+	})
+
+	t.Run("leaves the puzzle it was given alone", func(t *testing.T) {
+		puzzle := mustParse(string(contents))
+		clues := CountClues(puzzle)
+		before := puzzle.String()
+
+		for _, solverType := range SolverTypes {
+			options := NewOptions(false, FindFirst, InOrder, nil)
+			options.Solver = solverType
+			status, _, _, err := Solve(context.Background(), puzzle, options)
+
+			assert.NoError(t, err)
+			assert.Equal(t, Solved, status)
+			assert.Equal(t, clues, CountClues(puzzle))
+			assert.Equal(t, before, puzzle.String())
+			assert.Empty(t, puzzle.Solution)
+		}
+	})
+
+	t.Run("breaks the rules", func(t *testing.T) {
+		contents, err := os.ReadFile("../samples/invalid_row.txt")
+		if err != nil {
+			panic("Unable to read file ../samples/invalid_row.txt")
+		}
+		invalid := mustParse(string(contents))
+
+		for _, solverType := range SolverTypes {
+			options := NewOptions(false, FindFirst, InOrder, nil)
+			options.Solver = solverType
+			status, _, _, err := Solve(context.Background(), invalid, options)
+
+			assert.Equal(t, Invalid, status)
+			var validationErr *ValidationError
+			if assert.ErrorAs(t, err, &validationErr) {
+				assert.Equal(t, "row", validationErr.Constraint)
+			}
+		}
+	})
+
+	t.Run("shuffled without a generator", func(t *testing.T) {
+		status, _, _, err := Solve(context.Background(), puzzle, Options{SolveOrder: Shuffled, Seed: 42})
+
+		assert.NoError(t, err)
+		assert.Equal(t, Solved, status)
+	})
+
+	t.Run("unrecognized options", func(t *testing.T) {
+		tests := []struct {
+			options               Options
+			expectedErrorContains string
+		}{
+			{options: Options{TraversalType: "FindSome"}, expectedErrorContains: "Unrecognized traversal type 'FindSome'"},
+			{options: Options{Solver: "guess"}, expectedErrorContains: "Unrecognized solver 'guess'"},
+			{options: Options{Strategy: "last-empty"}, expectedErrorContains: "Unrecognized strategy 'last-empty'"},
+			{options: Options{SolveOrder: "Backwards"}, expectedErrorContains: "Unrecognized solve order 'Backwards'"},
+		}
+
+		for _, tt := range tests {
+			status, _, _, err := Solve(context.Background(), puzzle, tt.options)
+
+			assert.ErrorContains(t, err, tt.expectedErrorContains)
+			assert.Equal(t, Invalid, status)
+		}
+	})
+
+	t.Run("failing SAT solver", func(t *testing.T) {
+		options := NewOptions(false, EnsureUnique, InOrder, nil)
+		options.Solver = SAT
+		options.SATCommand = "false"
+
+		_, _, _, err := Solve(context.Background(), puzzle, options)
+
+		var solverErr *SolverError
+		assert.True(t, errors.As(err, &solverErr))
+		assert.Equal(t, SAT, solverErr.Solver)
+	})
+}
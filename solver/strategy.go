@@ -0,0 +1,117 @@
+package solver
+
+import (
+	"fmt"
+
+	"github.com/jbranchaud/go-sudoku/sudoku"
+)
+
+// How the traversal picks the next empty cell to branch on.
+type CellStrategy string
+
+const (
+	// the first empty cell in row-major order
+	FirstEmpty CellStrategy = "first-empty"
+	// the empty cell with the fewest possible values (minimum remaining
+	// values), ties go to the first in row-major order
+	FewestCandidates CellStrategy = "fewest-candidates"
+	// the empty cell with the fewest possible values, ties go to the cell
+	// with the most empty peers, and then to the first in row-major order
+	MostConstrained CellStrategy = "most-constrained"
+)
+
+var CellStrategies = []CellStrategy{FirstEmpty, FewestCandidates, MostConstrained}
+
+func ParseCellStrategy(value string) (CellStrategy, error) {
+	for _, strategy := range CellStrategies {
+		if string(strategy) == value {
+			return strategy, nil
+		}
+	}
+
+	return "", fmt.Errorf("Unrecognized strategy '%s', expected one of %v", value, CellStrategies)
+}
+
+// Every tie is broken by row-major order, so a given strategy always visits
+// cells in the same order for the same board. This keeps seeded output
+// reproducible per strategy.
+func selectNextCell(puzzle sudoku.Puzzle, options Options) (int, int, error) {
+	switch options.Strategy {
+	case FirstEmpty, "":
+		return findNextEmptyCell(puzzle)
+	case FewestCandidates:
+		return findFewestCandidatesCell(puzzle, false)
+	case MostConstrained:
+		return findFewestCandidatesCell(puzzle, true)
+	default:
+		panic(fmt.Sprintf("Error: unrecognized options.Strategy %s", options.Strategy))
+	}
+}
+
+func findFewestCandidatesCell(puzzle sudoku.Puzzle, breakTiesByDegree bool) (int, int, error) {
+	bestRow, bestCell := -1, -1
+	size := puzzle.Size()
+	bestCount := size + 1
+	bestDegree := -1
+
+	for row := range size {
+		for cell := range size {
+			if puzzle.ValueAt(row, cell) != 0 {
+				continue
+			}
+
+			count := puzzle.Candidates(row, cell).Count()
+			if count > bestCount {
+				continue
+			}
+
+			degree := 0
+			if breakTiesByDegree {
+				degree = countEmptyPeers(puzzle, row, cell)
+			}
+
+			if count < bestCount || degree > bestDegree {
+				bestRow, bestCell = row, cell
+				bestCount = count
+				bestDegree = degree
+			}
+
+			if bestCount == 0 {
+				// a cell with no possible values is a dead end, there's no
+				// need to look any further
+				return bestRow, bestCell, nil
+			}
+		}
+	}
+
+	if bestRow == -1 {
+		return -1, -1, fmt.Errorf("No more empty cells in the puzzle")
+	}
+
+	return bestRow, bestCell, nil
+}
+
+// The number of other empty cells that share a row, column, or sector with
+// this cell, i.e. the cells that a placement here would constrain.
+func countEmptyPeers(puzzle sudoku.Puzzle, row int, cell int) int {
+	layout := puzzle.Layout()
+	sectorNum := layout.SectorIndexFor(row, cell)
+
+	count := 0
+	for r := range layout.Size() {
+		for c := range layout.Size() {
+			if r == row && c == cell {
+				continue
+			}
+			if puzzle.ValueAt(r, c) != 0 {
+				continue
+			}
+
+			if r == row || c == cell || layout.SectorIndexFor(r, c) == sectorNum {
+				count++
+			}
+		}
+	}
+
+	return count
+}
@@ -0,0 +1,108 @@
+package solver
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseCellStrategy(t *testing.T) {
+	strategy, err := ParseCellStrategy("fewest-candidates")
+	assert.NoError(t, err)
+	assert.Equal(t, FewestCandidates, strategy)
+
+	_, err = ParseCellStrategy("fewest")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "Unrecognized strategy 'fewest'")
+}
+
+func TestSelectNextCell(t *testing.T) {
+	puzzle := mustParse(`000000000
+000000000
+000000000
+000000000
+000000000
+000000000
+000000000
+000000000
+123456780`)
+
+	t.Run("first empty", func(t *testing.T) {
+		row, cell, err := selectNextCell(puzzle, Options{Strategy: FirstEmpty})
+		assert.NoError(t, err)
+		assert.Equal(t, []int{0, 0}, []int{row, cell})
+	})
+
+	t.Run("fewest candidates", func(t *testing.T) {
+		row, cell, err := selectNextCell(puzzle, Options{Strategy: FewestCandidates})
+		assert.NoError(t, err)
+		assert.Equal(t, []int{8, 8}, []int{row, cell})
+	})
+
+	t.Run("most constrained breaks ties by empty peers", func(t *testing.T) {
+		tied := mustParse(`120000000
+000003000
+000000000
+000300000
+000000000
+000000000
+000000000
+000000000
+000000000`)
+
+		// (0,3) and (0,4) both have six candidates, but the two 3s around
+		// (0,3) leave it with one fewer empty peer
+		row, cell, err := selectNextCell(tied, Options{Strategy: FewestCandidates})
+		assert.NoError(t, err)
+		assert.Equal(t, []int{0, 3}, []int{row, cell})
+
+		row, cell, err = selectNextCell(tied, Options{Strategy: MostConstrained})
+		assert.NoError(t, err)
+		assert.Equal(t, []int{0, 4}, []int{row, cell})
+	})
+}
+
+func TestStrategiesFindTheSameSolutions(t *testing.T) {
+	filenames := []string{"../samples/001.txt", "../samples/two_solutions.txt", "../samples/four_solutions.txt"}
+
+	for _, filename := range filenames {
+		contents, err := os.ReadFile(filename)
+		if err != nil {
+			panic(fmt.Sprintf("Unable to read file %s", filename))
+		}
+		puzzle := mustParse(string(contents))
+
+		options := NewOptions(false, FindAll, InOrder, nil)
+		_, _, expected := traversePuzzle(puzzle, 1, options, &Diagnostics{})
+
+		for _, strategy := range CellStrategies {
+			t.Run(fmt.Sprintf("%s with %s", filename, strategy), func(t *testing.T) {
+				options := NewOptions(false, FindAll, InOrder, nil)
+				options.Strategy = strategy
+				_, _, diagnostics := traversePuzzle(puzzle, 1, options, &Diagnostics{})
+
+				assert.ElementsMatch(t, expected.Solutions, diagnostics.Solutions)
+			})
+		}
+	}
+}
+
+func TestSeededSolveIsReproduciblePerStrategy(t *testing.T) {
+	seed := int64(42)
+
+	for _, strategy := range CellStrategies {
+		t.Run(string(strategy), func(t *testing.T) {
+			first := NewOptions(false, FindFirst, Shuffled, &seed)
+			first.Strategy = strategy
+			second := NewOptions(false, FindFirst, Shuffled, &seed)
+			second.Strategy = strategy
+
+			firstPuzzle := mustSolveEmpty(first)
+			secondPuzzle := mustSolveEmpty(second)
+
+			assert.Equal(t, firstPuzzle.String(), secondPuzzle.String())
+		})
+	}
+}
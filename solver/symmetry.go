@@ -0,0 +1,121 @@
+package solver
+
+import (
+	"fmt"
+
+	"github.com/jbranchaud/go-sudoku/sudoku"
+)
+
+// Which cells of a generated puzzle have their clues kept or removed together,
+// so the clues that are left form a symmetric pattern.
+type Symmetry string
+
+const (
+	// every clue is removed on its own
+	NoSymmetry Symmetry = "none"
+	// a cell goes with the cell it lands on after a half turn of the board
+	Rotational180 Symmetry = "rotational-180"
+	// a cell goes with the cells it lands on after each quarter turn
+	Rotational90 Symmetry = "rotational-90"
+	// mirrored across the middle row, top to bottom
+	Horizontal Symmetry = "horizontal"
+	// mirrored across the middle column, left to right
+	Vertical Symmetry = "vertical"
+	// mirrored across the diagonal from the top-left to the bottom-right
+	Diagonal Symmetry = "diagonal"
+	// mirrored across the diagonal from the top-right to the bottom-left
+	AntiDiagonal Symmetry = "anti-diagonal"
+)
+
+var SymmetryTypes = []Symmetry{NoSymmetry, Rotational180, Rotational90, Horizontal, Vertical, Diagonal, AntiDiagonal}
+
+func ParseSymmetry(value string) (Symmetry, error) {
+	for _, symmetry := range SymmetryTypes {
+		if string(symmetry) == value {
+			return symmetry, nil
+		}
+	}
+
+	return "", fmt.Errorf("Unrecognized symmetry '%s', expected one of %v", value, SymmetryTypes)
+}
+
+// The cells a position on a board of the given size is mapped onto by the
+// symmetry, not including the position itself.
+func (symmetry Symmetry) images(size int, position sudoku.Position) []sudoku.Position {
+	last := size - 1
+	row, cell := position.Row, position.Cell
+
+	switch symmetry {
+	case NoSymmetry, "":
+		return []sudoku.Position{}
+	case Rotational180:
+		return []sudoku.Position{{Row: last - row, Cell: last - cell}}
+	case Rotational90:
+		return []sudoku.Position{
+			{Row: cell, Cell: last - row},
+			{Row: last - row, Cell: last - cell},
+			{Row: last - cell, Cell: row},
+		}
+	case Horizontal:
+		return []sudoku.Position{{Row: last - row, Cell: cell}}
+	case Vertical:
+		return []sudoku.Position{{Row: row, Cell: last - cell}}
+	case Diagonal:
+		return []sudoku.Position{{Row: cell, Cell: row}}
+	case AntiDiagonal:
+		return []sudoku.Position{{Row: last - cell, Cell: last - row}}
+	default:
+		panic(fmt.Sprintf("Error: unrecognized symmetry %s", symmetry))
+	}
+}
+
+// Split the board into groups of cells that the symmetry maps onto each other,
+// in row-major order of each group's first cell. Cells on an axis or at the
+// center of a turn are in a group of their own.
+func (symmetry Symmetry) orbits(size int) [][]sudoku.Position {
+	orbits := [][]sudoku.Position{}
+	seen := map[sudoku.Position]bool{}
+
+	for row := range size {
+		for cell := range size {
+			position := sudoku.Position{Row: row, Cell: cell}
+			if seen[position] {
+				continue
+			}
+
+			orbit := []sudoku.Position{position}
+			seen[position] = true
+			for _, image := range symmetry.images(size, position) {
+				if !seen[image] {
+					orbit = append(orbit, image)
+					seen[image] = true
+				}
+			}
+
+			orbits = append(orbits, orbit)
+		}
+	}
+
+	return orbits
+}
+
+// Whether every clue of the puzzle has its symmetric counterparts as clues too.
+func IsSymmetric(puzzle sudoku.Puzzle, symmetry Symmetry) bool {
+	size := puzzle.Size()
+
+	for row := range size {
+		for cell := range size {
+			if puzzle.ValueAt(row, cell) == 0 {
+				continue
+			}
+
+			for _, image := range symmetry.images(size, sudoku.Position{Row: row, Cell: cell}) {
+				if puzzle.ValueAt(image.Row, image.Cell) == 0 {
+					return false
+				}
+			}
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,98 @@
+package solver
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/jbranchaud/go-sudoku/sudoku"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSymmetryOrbits(t *testing.T) {
+	tests := []struct {
+		symmetry       Symmetry
+		expectedOrbits int
+	}{
+		{symmetry: NoSymmetry, expectedOrbits: 81},
+		// the center stays put and every other cell pairs up
+		{symmetry: Rotational180, expectedOrbits: 41},
+		// the center stays put and every other cell is in a group of four
+		{symmetry: Rotational90, expectedOrbits: 21},
+		// the cells on the axis stay put and every other cell pairs up
+		{symmetry: Horizontal, expectedOrbits: 45},
+		{symmetry: Vertical, expectedOrbits: 45},
+		{symmetry: Diagonal, expectedOrbits: 45},
+		{symmetry: AntiDiagonal, expectedOrbits: 45},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.symmetry), func(t *testing.T) {
+			orbits := tt.symmetry.orbits(9)
+
+			assert.Len(t, orbits, tt.expectedOrbits)
+
+			cells := 0
+			for _, orbit := range orbits {
+				cells += len(orbit)
+
+				// every cell of an orbit maps onto the rest of it
+				for _, position := range orbit {
+					for _, image := range tt.symmetry.images(9, position) {
+						assert.Contains(t, orbit, image)
+					}
+				}
+			}
+			assert.Equal(t, 81, cells)
+		})
+	}
+}
+
+func TestSymmetryImages(t *testing.T) {
+	position := sudoku.Position{Row: 0, Cell: 1}
+
+	assert.Equal(t, []sudoku.Position{{Row: 8, Cell: 7}}, Rotational180.images(9, position))
+	assert.Equal(t, []sudoku.Position{{Row: 1, Cell: 8}, {Row: 8, Cell: 7}, {Row: 7, Cell: 0}}, Rotational90.images(9, position))
+	assert.Equal(t, []sudoku.Position{{Row: 8, Cell: 1}}, Horizontal.images(9, position))
+	assert.Equal(t, []sudoku.Position{{Row: 0, Cell: 7}}, Vertical.images(9, position))
+	assert.Equal(t, []sudoku.Position{{Row: 1, Cell: 0}}, Diagonal.images(9, position))
+	assert.Equal(t, []sudoku.Position{{Row: 7, Cell: 8}}, AntiDiagonal.images(9, position))
+}
+
+func TestGenerateSymmetricPuzzle(t *testing.T) {
+	solution := mustParse(`346571289
+128439657
+579268314
+631842795
+782695143
+495317826
+214753968
+853926471
+967184532`)
+
+	for _, symmetry := range SymmetryTypes {
+		t.Run(string(symmetry), func(t *testing.T) {
+			puzzle := generatePuzzle(solution, symmetry, rand.New(rand.NewSource(42)))
+
+			assert.True(t, IsSymmetric(puzzle, symmetry))
+			assert.True(t, hasUniqueSolution(puzzle))
+		})
+	}
+
+	t.Run("with a difficulty", func(t *testing.T) {
+		generated, err := generatePuzzleWithDifficulty(solution, Options{Symmetry: Rotational180, Difficulty: sudoku.Hard, Rng: rand.New(rand.NewSource(42))})
+
+		assert.NoError(t, err)
+		assert.Equal(t, Rotational180, generated.Symmetry)
+		assert.Equal(t, sudoku.Hard, generated.Grade.Difficulty)
+		assert.True(t, IsSymmetric(generated.Puzzle, Rotational180))
+	})
+}
+
+func TestParseSymmetry(t *testing.T) {
+	symmetry, err := ParseSymmetry("rotational-90")
+	assert.NoError(t, err)
+	assert.Equal(t, Rotational90, symmetry)
+
+	_, err = ParseSymmetry("spiral")
+	assert.Error(t, err)
+}
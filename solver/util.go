@@ -0,0 +1,24 @@
+package solver
+
+import (
+	"cmp"
+	"math/rand"
+)
+
+// Fisher-Yates shuffle, with a generator seeded at random if none is given
+func Shuffle[T cmp.Ordered](slice []T, rng *rand.Rand) ([]T, error) {
+	if rng == nil {
+		rng = rand.New(rand.NewSource(rand.Int63()))
+	}
+
+	if len(slice) == 0 {
+		return slice, nil
+	}
+
+	for i := range len(slice) - 1 {
+		j := rng.Intn(len(slice) - 1)
+		slice[i], slice[j] = slice[j], slice[i]
+	}
+
+	return slice, nil
+}
@@ -0,0 +1,97 @@
+package solver
+
+import (
+	"fmt"
+
+	"github.com/jbranchaud/go-sudoku/sudoku"
+)
+
+// Check that the puzzle follows the rules, with a ValidationError for the
+// first one it breaks. An empty cell never breaks a rule, so a puzzle that's
+// valid can still have no solution.
+func Validate(puzzle sudoku.Puzzle) error {
+	err := checkBoardShape(puzzle)
+	if err != nil {
+		// early exit, the board can't be read cell by cell
+		return &ValidationError{Err: err}
+	}
+
+	size := puzzle.Size()
+	err = checkForInvalidValues(puzzle.CurrentBoard(), size)
+	if err != nil {
+		// early exit
+		return &ValidationError{Err: err}
+	}
+
+	// check every row, column, and sector, and then any cages and variant
+	// constraints the puzzle has
+	for _, constraint := range puzzle.AllConstraints() {
+		err := constraint.Validate(&puzzle)
+		if err != nil {
+			return &ValidationError{Constraint: constraint.Name(), Err: err}
+		}
+	}
+
+	return nil
+}
+
+// The board has to be square, with a row for each value of a supported sector
+// shape and a region layout that fits it, before any of its cells can be
+// looked at.
+func checkBoardShape(puzzle sudoku.Puzzle) error {
+	size := len(puzzle.Board)
+
+	shape := puzzle.Shape
+	if shape == (sudoku.Shape{}) && puzzle.Regions != nil {
+		// a jigsaw puzzle's regions take the place of its boxes, so any size
+		// there are enough symbols for will do
+		if size < 4 || size > sudoku.MaxSize {
+			return fmt.Errorf("the board has %d rows, expected between 4 and %d", size, sudoku.MaxSize)
+		}
+		shape = puzzle.GridShape()
+	} else if shape == (sudoku.Shape{}) {
+		var err error
+		shape, err = sudoku.ShapeForSize(size)
+		if err != nil {
+			return err
+		}
+	}
+
+	if shape.Size() != size {
+		return fmt.Errorf("the board has %d rows but %s sectors need %d", size, shape, shape.Size())
+	}
+
+	for i, row := range puzzle.Board {
+		if len(row) != size {
+			return fmt.Errorf("row %d has %d cells, expected %d", i+1, len(row), size)
+		}
+	}
+
+	if puzzle.Regions != nil {
+		err := sudoku.CheckRegions(puzzle.Regions, size)
+		if err != nil {
+			return err
+		}
+	}
+
+	if puzzle.Cages != nil {
+		err := sudoku.CheckCages(puzzle.Cages, size)
+		if err != nil {
+			return err
+		}
+	}
+
+	return sudoku.CheckConstraints(puzzle.Constraints, puzzle.Layout())
+}
+
+func checkForInvalidValues(puzzle [][]int, size int) error {
+	for i, row := range puzzle {
+		for j, cell := range row {
+			if cell < 0 || cell > size {
+				return fmt.Errorf("value '%d' at (%d,%d) is not between 0 and %d", cell, i+1, j+1, size)
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,256 @@
+package solver
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/jbranchaud/go-sudoku/sudoku"
+	"github.com/stretchr/testify/assert"
+)
+
+// +-----------------+-----------------+-----------------+
+// | 0,0   0,1   0,2 | 0,3   0,4   0,5 | 0,6   0,7   0,8 |
+// | 1,0   1,1   1,2 | 1,3   1,4   1,5 | 1,6   1,7   1,8 |
+// | 2,0   2,1   2,2 | 2,3   2,4   2,5 | 2,6   2,7   2,8 |
+// +-----------------+-----------------+-----------------+
+// | 3,0   3,1   3,2 | 3,3   3,4   3,5 | 3,6   3,7   3,8 |
+// | 4,0   4,1   4,2 | 4,3   4,4   4,5 | 4,6   4,7   4,8 |
+// | 5,0   5,1   5,2 | 5,3   5,4   5,5 | 5,6   5,7   5,8 |
+// +-----------------+-----------------+-----------------+
+// | 6,0   6,1   6,2 | 6,3   6,4   6,5 | 6,6   6,7   6,8 |
+// | 7,0   7,1   7,2 | 7,3   7,4   7,5 | 7,6   7,7   7,8 |
+// | 8,0   8,1   8,2 | 8,3   8,4   8,5 | 8,6   8,7   8,8 |
+// +-----------------+-----------------+-----------------+
+
+func TestValidatePuzzle(t *testing.T) {
+	tests := []struct {
+		name                  string
+		filename              string
+		expectedValid         bool
+		expectedErrorContains string
+	}{
+		{
+			name:                  "valid puzzle",
+			filename:              "../samples/001.txt",
+			expectedValid:         true,
+			expectedErrorContains: "",
+		},
+		{
+			name:                  "invalid row",
+			filename:              "../samples/invalid_row.txt",
+			expectedValid:         false,
+			expectedErrorContains: "Row check failed",
+		},
+		{
+			name:                  "invalid column",
+			filename:              "../samples/invalid_column.txt",
+			expectedValid:         false,
+			expectedErrorContains: "Column check failed",
+		},
+		{
+			name:                  "invalid sector",
+			filename:              "../samples/invalid_sector.txt",
+			expectedValid:         false,
+			expectedErrorContains: "Sector check failed",
+		},
+		{
+			name:                  "valid 6x6 puzzle",
+			filename:              "../samples/6x6.txt",
+			expectedValid:         true,
+			expectedErrorContains: "",
+		},
+		{
+			name:                  "valid 16x16 puzzle",
+			filename:              "../samples/16x16.txt",
+			expectedValid:         true,
+			expectedErrorContains: "",
+		},
+		{
+			name:                  "valid jigsaw puzzle",
+			filename:              "../samples/jigsaw.txt",
+			expectedValid:         true,
+			expectedErrorContains: "",
+		},
+		{
+			name:                  "invalid jigsaw region",
+			filename:              "../samples/invalid_jigsaw.txt",
+			expectedValid:         false,
+			expectedErrorContains: "Sector check failed",
+		},
+		{
+			name:                  "valid killer puzzle",
+			filename:              "../samples/killer.txt",
+			expectedValid:         true,
+			expectedErrorContains: "",
+		},
+		{
+			name:                  "invalid killer cage",
+			filename:              "../samples/invalid_killer.txt",
+			expectedValid:         false,
+			expectedErrorContains: "Cage check failed: Duplicate check failed, value '5' in cage 'e'",
+		},
+		{
+			name:                  "valid diagonal puzzle",
+			filename:              "../samples/diagonal.txt",
+			expectedValid:         true,
+			expectedErrorContains: "",
+		},
+		{
+			name:                  "invalid diagonal",
+			filename:              "../samples/invalid_diagonal.txt",
+			expectedValid:         false,
+			expectedErrorContains: "Diagonal check failed: Duplicate check failed, value '1' in diagonal 1",
+		},
+		{
+			name:                  "valid thermometer and arrow puzzle",
+			filename:              "../samples/thermo_arrow.txt",
+			expectedValid:         true,
+			expectedErrorContains: "",
+		},
+		{
+			name:                  "valid kropki and XV puzzle",
+			filename:              "../samples/kropki_xv_6x6.txt",
+			expectedValid:         true,
+			expectedErrorContains: "",
+		},
+		{
+			name:                  "invalid thermometer",
+			filename:              "../samples/invalid_thermo.txt",
+			expectedValid:         false,
+			expectedErrorContains: "Thermometer check failed: the thermometer from r2c2 doesn't increase, the '5' in r3c3 comes after the '8' in r2c3",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			contents, err := os.ReadFile(tt.filename)
+			if err != nil {
+				panic(fmt.Sprintf("Unable to read file %s", tt.filename))
+			}
+
+			puzzle := mustParse(string(contents))
+			err = Validate(puzzle)
+
+			assert.Equal(t, err == nil, tt.expectedValid)
+			if !tt.expectedValid {
+				assert.Contains(t, err.Error(), tt.expectedErrorContains)
+			}
+		})
+	}
+}
+
+func TestValidatePuzzleShape(t *testing.T) {
+	tests := []struct {
+		name                  string
+		puzzle                sudoku.Puzzle
+		expectedErrorContains string
+	}{
+		{
+			name:                  "unsupported size",
+			puzzle:                sudoku.Puzzle{Board: [][]int{{0, 0, 0}, {0, 0, 0}, {0, 0, 0}}},
+			expectedErrorContains: "Unsupported board size 3",
+		},
+		{
+			name:                  "prime size",
+			puzzle:                sudoku.Puzzle{Board: EmptyPuzzle(sudoku.Shape{BoxRows: 1, BoxColumns: 7}).Board},
+			expectedErrorContains: "Unsupported board size 7",
+		},
+		{
+			name:                  "short row",
+			puzzle:                sudoku.Puzzle{Board: [][]int{{0, 0, 0, 0}, {0, 0, 0}, {0, 0, 0, 0}, {0, 0, 0, 0}}},
+			expectedErrorContains: "row 2 has 3 cells, expected 4",
+		},
+		{
+			name:                  "shape doesn't match the board",
+			puzzle:                sudoku.Puzzle{Board: EmptyPuzzle(sudoku.StandardShape).Board, Shape: sudoku.Shape{BoxRows: 2, BoxColumns: 3}},
+			expectedErrorContains: "the board has 9 rows but 2x3 sectors need 6",
+		},
+		{
+			name:                  "regions that don't fit the board",
+			puzzle:                sudoku.Puzzle{Board: EmptyPuzzle(sudoku.Shape{BoxRows: 2, BoxColumns: 2}).Board, Regions: [][]int{{0, 0, 1, 1}, {0, 0, 1, 1}}},
+			expectedErrorContains: "the region layout has 2 rows, expected 4",
+		},
+		{
+			name:                  "regions of different sizes",
+			puzzle:                sudoku.Puzzle{Board: EmptyPuzzle(sudoku.Shape{BoxRows: 2, BoxColumns: 2}).Board, Regions: [][]int{{0, 0, 0, 1}, {0, 0, 1, 1}, {2, 2, 3, 3}, {2, 2, 3, 3}}},
+			expectedErrorContains: "region 1 has 5 cells, expected 4",
+		},
+		{
+			name: "cage off the board",
+			puzzle: sudoku.Puzzle{Board: EmptyPuzzle(sudoku.Shape{BoxRows: 2, BoxColumns: 2}).Board, Cages: []sudoku.Cage{
+				{Sum: 3, Cells: []sudoku.Position{{Row: 3, Cell: 3}, {Row: 3, Cell: 4}}},
+			}},
+			expectedErrorContains: "cage 1 has cell r4c5, which isn't on the board",
+		},
+		{
+			name: "cage with an impossible sum",
+			puzzle: sudoku.Puzzle{Board: EmptyPuzzle(sudoku.Shape{BoxRows: 2, BoxColumns: 2}).Board, Cages: []sudoku.Cage{
+				{Sum: 8, Cells: []sudoku.Position{{Row: 0, Cell: 0}, {Row: 0, Cell: 1}}},
+			}},
+			expectedErrorContains: "cage 1 has a sum of 8, which 2 different values can't add up to",
+		},
+		{
+			name: "thermometer off the board",
+			puzzle: sudoku.Puzzle{Board: EmptyPuzzle(sudoku.Shape{BoxRows: 2, BoxColumns: 2}).Board, Constraints: []sudoku.Constraint{
+				sudoku.Thermometer{Path: []sudoku.Position{{Row: 3, Cell: 3}, {Row: 4, Cell: 4}}},
+			}},
+			expectedErrorContains: "Validation check failed, the thermometer constraint has cell r5c5, which isn't on the board",
+		},
+		{
+			name: "X between cells that don't touch",
+			puzzle: sudoku.Puzzle{Board: EmptyPuzzle(sudoku.Shape{BoxRows: 2, BoxColumns: 2}).Board, Constraints: []sudoku.Constraint{
+				sudoku.XVPair{Pair: [2]sudoku.Position{{Row: 0, Cell: 0}, {Row: 0, Cell: 2}}, Sum: 10},
+			}},
+			expectedErrorContains: "the X between r1c1 and r1c3 has to be between cells that share a side",
+		},
+		{
+			name:                  "value too big for the board",
+			puzzle:                sudoku.Puzzle{Board: [][]int{{5, 0, 0, 0}, {0, 0, 0, 0}, {0, 0, 0, 0}, {0, 0, 0, 0}}},
+			expectedErrorContains: "is not between 0 and 4",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Validate(tt.puzzle)
+
+			assert.ErrorContains(t, err, tt.expectedErrorContains)
+			var validationErr *ValidationError
+			if assert.ErrorAs(t, err, &validationErr) {
+				assert.Empty(t, validationErr.Constraint)
+			}
+		})
+	}
+
+	t.Run("cage sums", func(t *testing.T) {
+		cages := []sudoku.Cage{{Sum: 5, Cells: []sudoku.Position{{Row: 0, Cell: 0}, {Row: 1, Cell: 0}}}}
+
+		err := Validate(sudoku.Puzzle{Board: [][]int{{4, 0, 0, 0}, {0, 0, 0, 0}, {0, 0, 0, 0}, {0, 0, 0, 0}}, Cages: cages})
+		assert.NoError(t, err)
+
+		err = Validate(sudoku.Puzzle{Board: [][]int{{4, 0, 0, 0}, {3, 0, 0, 0}, {0, 0, 0, 0}, {0, 0, 0, 0}}, Cages: cages})
+		var validationErr *ValidationError
+		if assert.ErrorAs(t, err, &validationErr) {
+			assert.Equal(t, "cage", validationErr.Constraint)
+		}
+		assert.ErrorContains(t, err, "Sum check failed, values in cage 1 add up to 7, more than its sum of 5")
+
+		cages[0].Sum = 7
+		err = Validate(sudoku.Puzzle{Board: [][]int{{4, 0, 0, 0}, {2, 0, 0, 0}, {0, 0, 0, 0}, {0, 0, 0, 0}}, Cages: cages})
+		assert.ErrorContains(t, err, "Sum check failed, values in cage 1 add up to 6 instead of its sum of 7")
+	})
+
+	t.Run("rectangular sectors", func(t *testing.T) {
+		// valid with 3x2 sectors, but the 1 and 2 share a 2x3 sector
+		board := EmptyPuzzle(sudoku.Shape{BoxRows: 3, BoxColumns: 2}).Board
+		board[0][0] = 1
+		board[1][2] = 1
+
+		err := Validate(sudoku.Puzzle{Board: board, Shape: sudoku.Shape{BoxRows: 3, BoxColumns: 2}})
+		assert.NoError(t, err)
+
+		err = Validate(sudoku.Puzzle{Board: board})
+		assert.Error(t, err)
+	})
+}
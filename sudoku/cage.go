@@ -0,0 +1,328 @@
+package sudoku
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// A Killer Sudoku cage, the values in its cells have to add up to its sum
+// without repeating.
+type Cage struct {
+	Sum int
+	// in row-major order, so the first cell is where the sum is written
+	Cells []Position
+	// The label of the cage in the layout it was read from, which errors
+	// name it by. A cage without one is named by its number.
+	Label string
+}
+
+// How errors refer to the cage, the index is its place among the puzzle's
+// cages.
+func (cage Cage) name(index int) string {
+	if cage.Label != "" {
+		return fmt.Sprintf("cage '%s'", cage.Label)
+	}
+
+	return fmt.Sprintf("cage %d", index+1)
+}
+
+// Parse a cage layout with a line per row and a label per cell, followed by
+// the sum of every cage as label=sum, e.g. a=12 b=7. Any symbol but . can be
+// a label, a . is a cell that isn't in any cage. The cages are numbered in the
+// order their labels first show up. Like a puzzle's rows, a row with spaces in
+// it is split on them.
+func ParseCages(lines []string) ([]Cage, error) {
+	labels := map[string]int{}
+	cages := []Cage{}
+	sums := map[string]int{}
+	// the labels of the sums in the order they're written
+	sumLabels := []string{}
+
+	row := 0
+	for _, line := range lines {
+		if strings.Contains(line, "=") {
+			for _, field := range strings.Fields(line) {
+				label, unparsedSum, found := strings.Cut(field, "=")
+				sum, err := strconv.Atoi(unparsedSum)
+				if !found || label == "" || err != nil {
+					return nil, fmt.Errorf("invalid cage sum '%s', expected label=sum", field)
+				}
+				if _, ok := sums[label]; !ok {
+					sumLabels = append(sumLabels, label)
+				}
+				sums[label] = sum
+			}
+			continue
+		}
+
+		unparsedCells := strings.Split(line, "")
+		if strings.ContainsAny(line, " \t") {
+			unparsedCells = strings.Fields(line)
+		}
+
+		for cell, label := range unparsedCells {
+			if label == "." {
+				continue
+			}
+
+			cage, ok := labels[label]
+			if !ok {
+				cage = len(cages)
+				labels[label] = cage
+				cages = append(cages, Cage{Label: label})
+			}
+			cages[cage].Cells = append(cages[cage].Cells, Position{Row: row, Cell: cell})
+		}
+		row++
+	}
+
+	for i, cage := range cages {
+		sum, ok := sums[cage.Label]
+		if !ok {
+			return nil, fmt.Errorf("%s doesn't have a sum", cage.name(i))
+		}
+		cages[i].Sum = sum
+	}
+	for _, label := range sumLabels {
+		if _, ok := labels[label]; !ok {
+			return nil, fmt.Errorf("there's a sum for cage '%s' but none of the cells are in it", label)
+		}
+	}
+
+	return cages, nil
+}
+
+// Check that every cage fits on a board of the given size, that no cell is in
+// more than one of them, and that each one is connected and has a sum that
+// its cells could add up to.
+func CheckCages(cages []Cage, size int) error {
+	caged := map[Position]int{}
+
+	for i, cage := range cages {
+		if len(cage.Cells) == 0 {
+			return fmt.Errorf("%s doesn't have any cells", cage.name(i))
+		}
+
+		for _, position := range cage.Cells {
+			if position.Row < 0 || position.Row >= size || position.Cell < 0 || position.Cell >= size {
+				return fmt.Errorf("%s has cell %s, which isn't on the board", cage.name(i), position)
+			}
+			if other, ok := caged[position]; ok {
+				return fmt.Errorf("cell %s is in both %s and %s", position, cages[other].name(other), cage.name(i))
+			}
+			caged[position] = i
+		}
+
+		if len(cage.Cells) > size {
+			return fmt.Errorf("%s has %d cells, more than the %d values that can go in them", cage.name(i), len(cage.Cells), size)
+		}
+
+		if !connected(cage.Cells) {
+			return fmt.Errorf("%s isn't connected, its cells have to be next to each other", cage.name(i))
+		}
+
+		if len(sumCombinations(allValues(size), len(cage.Cells), cage.Sum)) == 0 {
+			return fmt.Errorf("%s has a sum of %d, which %d different values can't add up to", cage.name(i), cage.Sum, len(cage.Cells))
+		}
+	}
+
+	return nil
+}
+
+// Every set of values, from 1 to size, that could fill the cage.
+func CageCombinations(cage Cage, size int) []CandidateMask {
+	return sumCombinations(allValues(size), len(cage.Cells), cage.Sum)
+}
+
+func allValues(size int) CandidateMask {
+	return CandidateMask(1)<<size - 1
+}
+
+// Every set of count different values from available that adds up to sum.
+func sumCombinations(available CandidateMask, count int, sum int) []CandidateMask {
+	combinations := []CandidateMask{}
+
+	var search func(remaining CandidateMask, count int, sum int, chosen CandidateMask)
+	search = func(remaining CandidateMask, count int, sum int, chosen CandidateMask) {
+		if count == 0 {
+			if sum == 0 {
+				combinations = append(combinations, chosen)
+			}
+			return
+		}
+
+		values := remaining.Values()
+		if len(values) < count {
+			return
+		}
+
+		// the smallest and largest sums the remaining values can make
+		lowest, highest := 0, 0
+		for i := range count {
+			lowest += values[i]
+			highest += values[len(values)-1-i]
+		}
+		if sum < lowest || sum > highest {
+			return
+		}
+
+		// either the smallest value is in the combination or it isn't
+		smallest := values[0]
+		search(remaining&^MaskFor(smallest), count-1, sum-smallest, chosen|MaskFor(smallest))
+		search(remaining&^MaskFor(smallest), count, sum, chosen)
+	}
+	search(available, count, sum, 0)
+
+	return combinations
+}
+
+type cageCandidatesKey struct {
+	available CandidateMask
+	count     int
+	sum       int
+}
+
+// The same few partly filled cages come up over and over while searching, so
+// the values that can complete them are only worked out once.
+var cageCandidatesCache sync.Map
+
+// The values that are part of some way of filling count cells with different
+// values from available that add up to sum.
+func cageCandidates(available CandidateMask, count int, sum int) CandidateMask {
+	key := cageCandidatesKey{available: available, count: count, sum: sum}
+	if candidates, ok := cageCandidatesCache.Load(key); ok {
+		return candidates.(CandidateMask)
+	}
+
+	var candidates CandidateMask
+	for _, combination := range sumCombinations(available, count, sum) {
+		candidates |= combination
+	}
+	cageCandidatesCache.Store(key, candidates)
+
+	return candidates
+}
+
+// The index of the cage the cell is in, -1 if it isn't in one.
+func (puz *Puzzle) CageIndexFor(row int, cell int) int {
+	return puz.state().cageOf[row][cell]
+}
+
+// The values of the cage's cells in the order of its cells, 0 for an empty
+// one.
+func (puz *Puzzle) CageAt(cageIndex int) []int {
+	grid := puz.state().grid
+
+	cage := make([]int, 0, len(puz.Cages[cageIndex].Cells))
+	for _, position := range puz.Cages[cageIndex].Cells {
+		cage = append(cage, grid[position.Row][position.Cell])
+	}
+
+	return cage
+}
+
+func (puz *Puzzle) ValuesInCage(cageIndex int) []int {
+	return removeBlanks(puz.CageAt(cageIndex))
+}
+
+// The cages of a Killer Sudoku: the values in a cage don't repeat, and add up
+// to the cage's sum once it's filled in.
+type cageConstraint struct {
+	cages []Cage
+}
+
+func (cageConstraint) Name() string { return "cage" }
+
+func (constraint cageConstraint) Cells(layout Layout) []Position {
+	cells := []Position{}
+	for _, cage := range constraint.cages {
+		cells = append(cells, cage.Cells...)
+	}
+
+	return cells
+}
+
+func (cageConstraint) Prune(puz *Puzzle, row int, cell int, candidates CandidateMask) CandidateMask {
+	search := puz.state()
+	cage := search.cageOf[row][cell]
+	if cage < 0 {
+		return candidates
+	}
+
+	available := search.layout.AllValuesMask() &^ search.cageMasks[cage]
+	return candidates & cageCandidates(available, search.cageEmpty[cage], search.cageTotals[cage]-search.cageSums[cage])
+}
+
+func (constraint cageConstraint) Validate(puz *Puzzle) error {
+	for cageIndex, cage := range constraint.cages {
+		err := checkForDuplicates(puz, cage.Cells, cage.name(cageIndex))
+		if err != nil {
+			return err
+		}
+
+		total, filled := 0, 0
+		for _, position := range cage.Cells {
+			value := puz.ValueAt(position.Row, position.Cell)
+			if value != 0 {
+				total += value
+				filled++
+			}
+		}
+
+		if total > cage.Sum {
+			return Violation{
+				Cells:       cage.Cells,
+				Description: fmt.Sprintf("Sum check failed, values in %s add up to %d, more than its sum of %d", cage.name(cageIndex), total, cage.Sum),
+			}
+		}
+		if filled == len(cage.Cells) && total != cage.Sum {
+			return Violation{
+				Cells:       cage.Cells,
+				Description: fmt.Sprintf("Sum check failed, values in %s add up to %d instead of its sum of %d", cage.name(cageIndex), total, cage.Sum),
+			}
+		}
+	}
+
+	return nil
+}
+
+// Like a jigsaw puzzle's regions, the cages are drawn as lines between the
+// cells, while the sectors they cross are dotted. The sum of a cage is in its
+// first cell.
+func (puz *Puzzle) killerString(marks boardMarks) string {
+	size := puz.Size()
+	board := puz.CurrentBoard()
+	layout := puz.Layout()
+
+	sums := map[Position]string{}
+	sumWidth := 1
+	for _, cage := range puz.Cages {
+		sums[cage.Cells[0]] = strconv.Itoa(cage.Sum)
+		sumWidth = max(sumWidth, len(sums[cage.Cells[0]]))
+	}
+
+	edge := func(a Position, b Position) outlineEdge {
+		cage := puz.CageIndexFor(a.Row, a.Cell)
+		if cage == -1 || cage != puz.CageIndexFor(b.Row, b.Cell) {
+			// a cell that isn't in a cage gets an outline of its own
+			return solidEdge
+		}
+		if layout.SectorIndexFor(a.Row, a.Cell) != layout.SectorIndexFor(b.Row, b.Cell) {
+			return dottedEdge
+		}
+
+		return noEdge
+	}
+	cellText := func(row int, cell int) string {
+		symbol := "_"
+		if board[row][cell] != 0 {
+			symbol = Symbol(board[row][cell])
+		}
+
+		return fmt.Sprintf("%-*s %s ", sumWidth, sums[Position{Row: row, Cell: cell}], symbol)
+	}
+
+	return outlineString(size, size, sumWidth+3, edge, cellText, marks)
+}
@@ -0,0 +1,181 @@
+package sudoku
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// a killer layout for this 4x4 board
+//
+//	1 2 | 3 4
+//	3 4 | 1 2
+//	----+----
+//	2 1 | 4 3
+//	4 3 | 2 1
+var killerRows = []string{
+	"aabb",
+	"cdbe",
+	"cdfe",
+	"ggff",
+	"a=3 b=8 c=5",
+	"d=5 e=5 f=7 g=7",
+}
+
+func TestParseCages(t *testing.T) {
+	cages, err := ParseCages(killerRows)
+
+	assert.NoError(t, err)
+	assert.Len(t, cages, 7)
+	assert.Equal(t, Cage{Sum: 3, Cells: []Position{{Row: 0, Cell: 0}, {Row: 0, Cell: 1}}, Label: "a"}, cages[0])
+	assert.Equal(t, Cage{Sum: 8, Cells: []Position{{Row: 0, Cell: 2}, {Row: 0, Cell: 3}, {Row: 1, Cell: 2}}, Label: "b"}, cages[1])
+	assert.Equal(t, Cage{Sum: 7, Cells: []Position{{Row: 2, Cell: 2}, {Row: 3, Cell: 2}, {Row: 3, Cell: 3}}, Label: "f"}, cages[5])
+
+	t.Run("cells outside of any cage", func(t *testing.T) {
+		cages, err := ParseCages([]string{"a.", ".a", "a=3"})
+
+		assert.NoError(t, err)
+		assert.Equal(t, []Cage{{Sum: 3, Cells: []Position{{Row: 0, Cell: 0}, {Row: 1, Cell: 1}}, Label: "a"}}, cages)
+	})
+
+	t.Run("labels split on spaces", func(t *testing.T) {
+		cages, err := ParseCages([]string{"10 10 11", "12 12 11", "10=3 11=7 12=4"})
+
+		assert.NoError(t, err)
+		assert.Len(t, cages, 3)
+		assert.Equal(t, 7, cages[1].Sum)
+	})
+
+	errorTests := []struct {
+		name                  string
+		lines                 []string
+		expectedErrorContains string
+	}{
+		{name: "missing sum", lines: []string{"ab", "ab", "a=3"}, expectedErrorContains: "cage 'b' doesn't have a sum"},
+		{name: "missing sums", lines: []string{"abc", "abc", "a=3"}, expectedErrorContains: "cage 'b' doesn't have a sum"},
+		{name: "sum without a cage", lines: []string{"aa", "aa", "a=10 b=4"}, expectedErrorContains: "there's a sum for cage 'b'"},
+		{name: "sum that isn't a number", lines: []string{"aa", "a=three"}, expectedErrorContains: "invalid cage sum 'a=three'"},
+	}
+	for _, tt := range errorTests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseCages(tt.lines)
+
+			assert.ErrorContains(t, err, tt.expectedErrorContains)
+		})
+	}
+}
+
+func TestCheckCages(t *testing.T) {
+	cages, _ := ParseCages(killerRows)
+	assert.NoError(t, CheckCages(cages, 4))
+
+	tests := []struct {
+		name                  string
+		cages                 []Cage
+		expectedErrorContains string
+	}{
+		{
+			name:                  "overlapping cages",
+			cages:                 []Cage{{Sum: 3, Cells: []Position{{Row: 0, Cell: 0}, {Row: 0, Cell: 1}}}, {Sum: 5, Cells: []Position{{Row: 0, Cell: 1}, {Row: 1, Cell: 1}}}},
+			expectedErrorContains: "cell r1c2 is in both cage 1 and cage 2",
+		},
+		{
+			name:                  "too many cells",
+			cages:                 []Cage{{Sum: 10, Cells: []Position{{Row: 0, Cell: 0}, {Row: 0, Cell: 1}, {Row: 0, Cell: 2}, {Row: 0, Cell: 3}, {Row: 1, Cell: 3}}}},
+			expectedErrorContains: "cage 1 has 5 cells, more than the 4 values",
+		},
+		{
+			name:                  "not connected",
+			cages:                 []Cage{{Sum: 3, Cells: []Position{{Row: 0, Cell: 0}, {Row: 1, Cell: 1}}, Label: "a"}},
+			expectedErrorContains: "cage 'a' isn't connected",
+		},
+		{
+			name:                  "sum too small",
+			cages:                 []Cage{{Sum: 2, Cells: []Position{{Row: 0, Cell: 0}, {Row: 0, Cell: 1}}}},
+			expectedErrorContains: "cage 1 has a sum of 2, which 2 different values can't add up to",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.ErrorContains(t, CheckCages(tt.cages, 4), tt.expectedErrorContains)
+		})
+	}
+}
+
+func TestCageCombinations(t *testing.T) {
+	tests := []struct {
+		cells    int
+		sum      int
+		expected [][]int
+	}{
+		{cells: 2, sum: 3, expected: [][]int{{1, 2}}},
+		{cells: 2, sum: 10, expected: [][]int{{1, 9}, {2, 8}, {3, 7}, {4, 6}}},
+		{cells: 3, sum: 23, expected: [][]int{{6, 8, 9}}},
+		{cells: 9, sum: 45, expected: [][]int{{1, 2, 3, 4, 5, 6, 7, 8, 9}}},
+		{cells: 3, sum: 5, expected: [][]int{}},
+	}
+
+	for _, tt := range tests {
+		combinations := [][]int{}
+		for _, combination := range CageCombinations(Cage{Sum: tt.sum, Cells: make([]Position, tt.cells)}, 9) {
+			combinations = append(combinations, combination.Values())
+		}
+
+		assert.Equal(t, tt.expected, combinations, "%d cells adding up to %d", tt.cells, tt.sum)
+	}
+}
+
+func TestKillerCandidates(t *testing.T) {
+	cages, _ := ParseCages(killerRows)
+	board := [][]int{
+		{0, 0, 0, 0},
+		{0, 0, 0, 0},
+		{0, 0, 0, 0},
+		{0, 0, 0, 0},
+	}
+	puzzle := Puzzle{Board: board, Cages: cages}
+
+	// a=3 can only be 1+2, and b=8 with three cells only 1+3+4
+	assert.Equal(t, []int{1, 2}, puzzle.Candidates(0, 0).Values())
+	assert.Equal(t, []int{1, 3, 4}, puzzle.Candidates(0, 2).Values())
+
+	puzzle.PlaceValue(0, 0, 1)
+	assert.Equal(t, []int{2}, puzzle.Candidates(0, 1).Values())
+
+	// the rest of b needs 1+3, and the row already has its 1
+	puzzle.PlaceValue(0, 2, 4)
+	assert.Equal(t, 1, puzzle.CageIndexFor(0, 3))
+	assert.Equal(t, []int{4}, puzzle.ValuesInCage(1))
+	assert.Equal(t, []int{3}, puzzle.Candidates(0, 3).Values())
+
+	puzzle.UndoLastPlacement()
+	puzzle.UndoLastPlacement()
+	assert.Equal(t, []int{1, 2}, puzzle.Candidates(0, 1).Values())
+	assert.Equal(t, -1, (&Puzzle{Board: board}).CageIndexFor(0, 0))
+}
+
+func TestKillerPrettyString(t *testing.T) {
+	cages, _ := ParseCages(killerRows)
+	board := [][]int{
+		{1, 0, 0, 0},
+		{0, 0, 0, 0},
+		{0, 0, 0, 0},
+		{0, 0, 0, 2},
+	}
+	puzzle := Puzzle{Board: board, Cages: cages}
+
+	expected := strings.Join([]string{
+		"╔═════════╤═════════╗",
+		"║3 1    _ │8 _    _ ║",
+		"╟────┬────┤    ┌────╢",
+		"║5 _ │5 _ │  _ │5 _ ║",
+		"║┄┄┄┄│┄┄┄┄├────┤┄┄┄┄║",
+		"║  _ │  _ │7 _ │  _ ║",
+		"╟────┴────┤    └────╢",
+		"║7 _    _ │  _    2 ║",
+		"╚═════════╧═════════╝",
+	}, "\n")
+	assert.Equal(t, expected, puzzle.PrettyString())
+}
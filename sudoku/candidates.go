@@ -0,0 +1,212 @@
+package sudoku
+
+import (
+	"math/bits"
+	"slices"
+)
+
+// A bitmask of values, bit 0 is the value 1, bit 1 is the value 2, etc.
+type CandidateMask uint32
+
+func MaskFor(value int) CandidateMask {
+	if value < 1 || value > MaxSize {
+		// out of range values are reported by validation, they don't take
+		// part in candidate tracking
+		return 0
+	}
+
+	return CandidateMask(1) << (value - 1)
+}
+
+func (mask CandidateMask) Has(value int) bool {
+	return mask&MaskFor(value) != 0
+}
+
+func (mask CandidateMask) Count() int {
+	return bits.OnesCount32(uint32(mask))
+}
+
+// List the values in the mask in ascending order.
+func (mask CandidateMask) Values() []int {
+	values := make([]int, 0, mask.Count())
+	for remaining := mask; remaining != 0; remaining &= remaining - 1 {
+		values = append(values, bits.TrailingZeros32(uint32(remaining))+1)
+	}
+
+	return values
+}
+
+// The search state is derived from the Board and every Placement in the
+// Solution. It is built the first time it is needed and from then on kept up
+// to date by PlaceValue and UndoLastPlacement, so that looking at a cell or
+// its candidates never has to rebuild the board.
+//
+// Copies of a Puzzle share the same search state for as long as they have
+// the same number of placements, which is what a depth-first traversal that
+// undoes its placements in reverse order needs. A copy whose placements no
+// longer match the state's builds a state of its own, so placements on one
+// copy never show up on another.
+type searchState struct {
+	layout      Layout
+	grid        [][]int
+	rowMasks    []CandidateMask
+	columnMasks []CandidateMask
+	sectorMasks []CandidateMask
+	emptyCells  int
+	// the cage of every cell, -1 if it isn't in one, and for every cage the
+	// values placed in it, what they add up to, how many of its cells are
+	// still empty, and the sum it has to reach
+	cageOf     [][]int
+	cageMasks  []CandidateMask
+	cageSums   []int
+	cageEmpty  []int
+	cageTotals []int
+	// the puzzle's constraints beyond its rows, columns, and sectors, which
+	// narrow down the candidates of a cell further
+	constraints []Constraint
+	// a copy of the puzzle that shares this state, for the constraints to
+	// look at the board through, so that the Puzzle Candidates is called on
+	// doesn't have to escape to the heap. Its Solution is kept up to date
+	// along with the state, so it also tells which placements the state
+	// reflects.
+	view *Puzzle
+}
+
+func (puz *Puzzle) state() *searchState {
+	if puz.search != nil && len(puz.search.view.Solution) == len(puz.Solution) {
+		return puz.search
+	}
+
+	return puz.buildState()
+}
+
+func (puz *Puzzle) buildState() *searchState {
+	layout := puz.Layout()
+	size := layout.Size()
+	search := &searchState{
+		layout:      layout,
+		grid:        make([][]int, size),
+		rowMasks:    make([]CandidateMask, size),
+		columnMasks: make([]CandidateMask, size),
+		sectorMasks: make([]CandidateMask, size),
+		cageOf:      make([][]int, size),
+		cageMasks:   make([]CandidateMask, len(puz.Cages)),
+		cageSums:    make([]int, len(puz.Cages)),
+		cageEmpty:   make([]int, len(puz.Cages)),
+		cageTotals:  make([]int, len(puz.Cages)),
+		constraints: puz.extraConstraints(),
+	}
+	for i := range size {
+		search.grid[i] = make([]int, size)
+		copy(search.grid[i], puz.Board[i])
+		search.cageOf[i] = slices.Repeat([]int{-1}, size)
+	}
+	for i, cage := range puz.Cages {
+		search.cageTotals[i] = cage.Sum
+		for _, position := range cage.Cells {
+			if position.Row < 0 || position.Row >= size || position.Cell < 0 || position.Cell >= size {
+				// cells off the board are reported by validation
+				continue
+			}
+			search.cageOf[position.Row][position.Cell] = i
+			search.cageEmpty[i]++
+		}
+	}
+	for _, p := range puz.Solution {
+		search.grid[p.Row][p.Cell] = p.Value
+	}
+
+	for row := range size {
+		for cell := range size {
+			value := search.grid[row][cell]
+			if value == 0 {
+				search.emptyCells++
+			} else {
+				search.mark(row, cell, value)
+			}
+		}
+	}
+
+	puz.search = search
+	view := *puz
+	view.Solution = slices.Clone(puz.Solution)
+	search.view = &view
+
+	return search
+}
+
+func (search *searchState) mark(row int, cell int, value int) {
+	mask := MaskFor(value)
+	search.rowMasks[row] |= mask
+	search.columnMasks[cell] |= mask
+	search.sectorMasks[search.layout.SectorIndexFor(row, cell)] |= mask
+
+	if cage := search.cageOf[row][cell]; cage >= 0 {
+		search.cageMasks[cage] |= mask
+		search.cageSums[cage] += value
+		search.cageEmpty[cage]--
+	}
+}
+
+func (search *searchState) unmark(row int, cell int, value int) {
+	mask := ^MaskFor(value)
+	search.rowMasks[row] &= mask
+	search.columnMasks[cell] &= mask
+	search.sectorMasks[search.layout.SectorIndexFor(row, cell)] &= mask
+
+	if cage := search.cageOf[row][cell]; cage >= 0 {
+		search.cageMasks[cage] &= mask
+		search.cageSums[cage] -= value
+		search.cageEmpty[cage]++
+	}
+}
+
+func (search *searchState) place(row int, cell int, value int) {
+	if search.grid[row][cell] == 0 {
+		search.emptyCells--
+	} else {
+		search.unmark(row, cell, search.grid[row][cell])
+	}
+
+	search.grid[row][cell] = value
+	search.mark(row, cell, value)
+	search.view.Solution = append(search.view.Solution, Placement{Row: row, Cell: cell, Value: value})
+}
+
+func (search *searchState) restore(row int, cell int, previousValue int) {
+	search.unmark(row, cell, search.grid[row][cell])
+	search.grid[row][cell] = previousValue
+	search.view.Solution = search.view.Solution[:len(search.view.Solution)-1]
+
+	if previousValue == 0 {
+		search.emptyCells++
+	} else {
+		search.mark(row, cell, previousValue)
+	}
+}
+
+// The value currently in the cell, 0 if it is empty.
+func (puz *Puzzle) ValueAt(row int, cell int) int {
+	return puz.state().grid[row][cell]
+}
+
+// The values that could be placed in the cell without breaking any of the
+// puzzle's constraints, given the values already on the board. Every puzzle
+// has rows, columns, and sectors, and every solver asks for candidates over
+// and over, so those come straight from their masks rather than through the
+// Constraint interface.
+func (puz *Puzzle) Candidates(row int, cell int) CandidateMask {
+	search := puz.state()
+	used := search.rowMasks[row] | search.columnMasks[cell] | search.sectorMasks[search.layout.SectorIndexFor(row, cell)]
+	candidates := search.layout.AllValuesMask() &^ used
+
+	for _, constraint := range search.constraints {
+		candidates = constraint.Prune(search.view, row, cell, candidates)
+	}
+
+	return candidates
+}
+
+func (puz *Puzzle) EmptyCellCount() int {
+	return puz.state().emptyCells
+}
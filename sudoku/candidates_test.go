@@ -0,0 +1,74 @@
+package sudoku
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCandidates(t *testing.T) {
+	board := [][]int{
+		{0, 0, 0, 0, 8, 0, 0, 0, 0},
+		{8, 2, 3, 1, 0, 7, 4, 9, 6},
+		{0, 0, 0, 0, 0, 0, 0, 0, 8},
+		{9, 4, 8, 0, 0, 2, 0, 0, 1},
+		{0, 7, 5, 0, 0, 0, 6, 0, 0},
+		{6, 0, 1, 0, 4, 9, 8, 2, 0},
+		{0, 8, 0, 0, 1, 0, 9, 0, 2},
+		{0, 0, 0, 7, 6, 3, 0, 0, 0},
+		{5, 1, 0, 9, 2, 8, 0, 7, 4},
+	}
+
+	t.Run("excludes values in the row, column, and sector", func(t *testing.T) {
+		puzzle := Puzzle{Board: board}
+
+		assert.Equal(t, []int{1, 4, 7}, puzzle.Candidates(0, 0).Values())
+		assert.Equal(t, []int{5}, puzzle.Candidates(1, 4).Values())
+		assert.Equal(t, 1, puzzle.Candidates(4, 4).Count())
+	})
+
+	t.Run("placements update the candidates of peers", func(t *testing.T) {
+		puzzle := Puzzle{Board: board}
+		emptyCells := puzzle.EmptyCellCount()
+
+		puzzle.PlaceValue(1, 4, 5)
+
+		assert.Equal(t, 5, puzzle.ValueAt(1, 4))
+		assert.Equal(t, emptyCells-1, puzzle.EmptyCellCount())
+		assert.False(t, puzzle.Candidates(2, 4).Has(5))
+		assert.False(t, puzzle.Candidates(0, 3).Has(5))
+	})
+
+	t.Run("undoing a placement restores the candidates", func(t *testing.T) {
+		puzzle := Puzzle{Board: board}
+		before := puzzle.Candidates(2, 4)
+
+		puzzle.PlaceValue(1, 4, 5)
+		puzzle.UndoLastPlacement()
+
+		assert.Equal(t, 0, puzzle.ValueAt(1, 4))
+		assert.Equal(t, before, puzzle.Candidates(2, 4))
+		assert.Empty(t, puzzle.Solution)
+	})
+
+	t.Run("the board itself is never modified", func(t *testing.T) {
+		puzzle := Puzzle{Board: board}
+
+		puzzle.PlaceValue(1, 4, 5)
+
+		assert.Equal(t, 0, board[1][4])
+	})
+
+	t.Run("placements on a copy don't show up on the original", func(t *testing.T) {
+		puzzle := Puzzle{Board: board}
+		emptyCells := puzzle.EmptyCellCount()
+
+		copied := puzzle
+		copied.PlaceValue(1, 4, 5)
+
+		assert.Equal(t, 0, puzzle.ValueAt(1, 4))
+		assert.Equal(t, emptyCells, puzzle.EmptyCellCount())
+		assert.True(t, puzzle.Candidates(2, 4).Has(5))
+		assert.Equal(t, 5, copied.ValueAt(1, 4))
+	})
+}
@@ -0,0 +1,496 @@
+package sudoku
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// A node of a chain: a value in one cell, or for grouped nodes, a value in two
+// or three cells of a box that also share a row or column, meaning the value
+// is in one of them.
+type chainNode struct {
+	Value int
+	Cells []Position
+}
+
+func (node chainNode) isGroup() bool {
+	return len(node.Cells) > 1
+}
+
+func (node chainNode) key() string {
+	return fmt.Sprintf("%d@%v", node.Value, node.Cells)
+}
+
+// Grouped nodes list all of their rows and columns together, e.g. r1c789.
+// On boards with more than nine rows they're separated by commas, e.g.
+// r1c10,11,12.
+func groupName(cells []Position) string {
+	separator := ""
+	rows := []string{}
+	columns := []string{}
+	for _, position := range cells {
+		if position.Row >= 9 || position.Cell >= 9 {
+			separator = ","
+		}
+
+		row := fmt.Sprintf("%d", position.Row+1)
+		column := fmt.Sprintf("%d", position.Cell+1)
+		if !slices.Contains(rows, row) {
+			rows = append(rows, row)
+		}
+		if !slices.Contains(columns, column) {
+			columns = append(columns, column)
+		}
+	}
+
+	return fmt.Sprintf("r%sc%s", strings.Join(rows, separator), strings.Join(columns, separator))
+}
+
+func (node chainNode) String() string {
+	return fmt.Sprintf("(%d)%s", node.Value, groupName(node.Cells))
+}
+
+// The rules a chain technique plays by.
+type chainRules struct {
+	technique Technique
+	// every node has the same value, linked through the units they share
+	singleValue bool
+	// strong links only inside cells with two candidates, and weak links only
+	// between cells, as in an XY-Chain
+	bivalueOnly bool
+	groups      bool
+	// chains with two ends, ruling out what sees both ends
+	open bool
+	// chains that loop back to where they started
+	loops bool
+	// the fewest nodes an open chain needs, anything shorter is a simpler
+	// technique, e.g. an XY-Chain through two cells is a naked pair
+	shortest int
+}
+
+var chainTechniqueRules = map[Technique]chainRules{
+	XChain:  {technique: XChain, singleValue: true, open: true, shortest: 4},
+	XCycle:  {technique: XCycle, singleValue: true, loops: true},
+	XYChain: {technique: XYChain, bivalueOnly: true, open: true, shortest: 6},
+	AIC:     {technique: AIC, groups: true, open: true, loops: true, shortest: 4},
+}
+
+// A set of candidates of a board with the given number of values, indexed by
+// candidateIndex.
+type candidateSet struct {
+	size int
+	bits []uint64
+}
+
+func newCandidateSet(size int) candidateSet {
+	return candidateSet{size: size, bits: make([]uint64, (size*size*size+63)/64)}
+}
+
+func (set candidateSet) candidateIndex(row int, cell int, value int) int {
+	return (row*set.size+cell)*set.size + (value - 1)
+}
+
+func (set candidateSet) add(row int, cell int, value int) {
+	index := set.candidateIndex(row, cell, value)
+	set.bits[index/64] |= 1 << (index % 64)
+}
+
+func (set candidateSet) intersect(other candidateSet) candidateSet {
+	result := newCandidateSet(set.size)
+	for i := range set.bits {
+		result.bits[i] = set.bits[i] & other.bits[i]
+	}
+
+	return result
+}
+
+func (set candidateSet) union(other candidateSet) candidateSet {
+	result := newCandidateSet(set.size)
+	for i := range set.bits {
+		result.bits[i] = set.bits[i] | other.bits[i]
+	}
+
+	return result
+}
+
+// The candidates in the set, in row-major order.
+func (set candidateSet) candidates() []Candidate {
+	candidates := []Candidate{}
+	for index := range set.size * set.size * set.size {
+		if set.bits[index/64]&(1<<(index%64)) == 0 {
+			continue
+		}
+
+		cellIndex := index / set.size
+		candidates = append(candidates, Candidate{Row: cellIndex / set.size, Cell: cellIndex % set.size, Value: index%set.size + 1})
+	}
+
+	return candidates
+}
+
+// Every node a chain can use, and the links between them. A strong link means
+// at least one of the two nodes is true, a weak link means they can't both
+// be.
+type chainGraph struct {
+	nodes  []chainNode
+	strong [][]int
+	weak   [][]int
+	// the candidates that are false when the node is true, by node
+	excludes []candidateSet
+}
+
+func buildChainGraph(grid *CandidateGrid, rules chainRules) chainGraph {
+	graph := chainGraph{}
+	indexes := map[string]int{}
+	addNode := func(node chainNode) {
+		indexes[node.key()] = len(graph.nodes)
+		graph.nodes = append(graph.nodes, node)
+	}
+
+	size := grid.Layout.Size()
+	for row := range size {
+		for cell := range size {
+			for _, value := range grid.Candidates[row][cell].Values() {
+				addNode(chainNode{Value: value, Cells: []Position{{Row: row, Cell: cell}}})
+			}
+		}
+	}
+
+	units := grid.Layout.Units()
+	if rules.groups {
+		for boxIndex := 2 * size; boxIndex < 3*size; boxIndex++ {
+			for lineIndex := range 2 * size {
+				for value := 1; value <= size; value++ {
+					cells := []Position{}
+					for _, position := range grid.positionsFor(units[boxIndex], value) {
+						if containsPosition(units[lineIndex], position) {
+							cells = append(cells, position)
+						}
+					}
+
+					if len(cells) >= 2 {
+						addNode(chainNode{Value: value, Cells: cells})
+					}
+				}
+			}
+		}
+	}
+
+	graph.strong = make([][]int, len(graph.nodes))
+	graph.weak = make([][]int, len(graph.nodes))
+	graph.excludes = make([]candidateSet, len(graph.nodes))
+
+	for i, node := range graph.nodes {
+		graph.excludes[i] = newCandidateSet(size)
+		for row := range size {
+			for cell := range size {
+				position := Position{Row: row, Cell: cell}
+				if grid.has(position, node.Value) && !containsPosition(node.Cells, position) && grid.Layout.seesAll(position, node.Cells) {
+					graph.excludes[i].add(row, cell, node.Value)
+				}
+			}
+		}
+
+		if !node.isGroup() {
+			position := node.Cells[0]
+			for _, value := range grid.candidatesAt(position).Values() {
+				if value != node.Value {
+					graph.excludes[i].add(position.Row, position.Cell, value)
+				}
+			}
+		}
+
+		if !rules.bivalueOnly {
+			// the only other place for the value in a unit
+			for _, unit := range units {
+				if !containsAll(unit, node.Cells) {
+					continue
+				}
+
+				rest := []Position{}
+				for _, position := range grid.positionsFor(unit, node.Value) {
+					if !containsPosition(node.Cells, position) {
+						rest = append(rest, position)
+					}
+				}
+
+				j, ok := indexes[chainNode{Value: node.Value, Cells: rest}.key()]
+				if ok && !slices.Contains(graph.strong[i], j) {
+					graph.strong[i] = append(graph.strong[i], j)
+				}
+			}
+		}
+
+		if !node.isGroup() && !rules.singleValue {
+			// the only other value in the cell
+			candidates := grid.candidatesAt(node.Cells[0])
+			if candidates.Count() == 2 {
+				other := (candidates &^ MaskFor(node.Value)).Values()[0]
+				graph.strong[i] = append(graph.strong[i], indexes[chainNode{Value: other, Cells: node.Cells}.key()])
+			}
+		}
+
+		for j, other := range graph.nodes {
+			if i == j {
+				continue
+			}
+
+			switch {
+			case other.Value == node.Value:
+				if rules.bivalueOnly && (node.isGroup() || other.isGroup()) {
+					continue
+				}
+				if disjoint(node.Cells, other.Cells) && grid.Layout.everySees(node.Cells, other.Cells) {
+					graph.weak[i] = append(graph.weak[i], j)
+				}
+			case !rules.singleValue && !rules.bivalueOnly:
+				if !node.isGroup() && !other.isGroup() && node.Cells[0] == other.Cells[0] {
+					graph.weak[i] = append(graph.weak[i], j)
+				}
+			}
+		}
+	}
+
+	return graph
+}
+
+func (layout Layout) seesAll(position Position, cells []Position) bool {
+	for _, other := range cells {
+		if !layout.sees(position, other) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (layout Layout) everySees(cells []Position, others []Position) bool {
+	for _, position := range cells {
+		if !layout.seesAll(position, others) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func disjoint(cells []Position, others []Position) bool {
+	for _, position := range cells {
+		if containsPosition(others, position) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Follow alternating links out from every single-cell node in turn: if the
+// node is false, the nodes it's strongly linked to are true, which makes the
+// nodes they're weakly linked to false, and so on. Whatever the chain ends
+// on, either it or the start is true.
+func findChain(grid *CandidateGrid, rules chainRules) (Step, bool) {
+	graph := buildChainGraph(grid, rules)
+
+	for start, node := range graph.nodes {
+		if node.isGroup() {
+			continue
+		}
+
+		if step, found := graph.search(start, rules); found {
+			return step, true
+		}
+	}
+
+	return Step{}, false
+}
+
+// A breadth first search, so the first chain found from a start is one of the
+// shortest. A node can turn up both true and false along the way, since every
+// link only says what follows from the one before it.
+func (graph chainGraph) search(start int, rules chainRules) (Step, bool) {
+	type link struct {
+		node int
+		on   bool
+	}
+
+	// parents and visited are indexed by whether the node is on, then by node
+	parents := [2][]int{make([]int, len(graph.nodes)), make([]int, len(graph.nodes))}
+	visited := [2][]bool{make([]bool, len(graph.nodes)), make([]bool, len(graph.nodes))}
+	visited[0][start] = true
+	queue := []link{{node: start, on: false}}
+
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+
+		if current.on {
+			for _, next := range graph.weak[current.node] {
+				if !visited[0][next] && !graph.overlaps(start, next) {
+					visited[0][next] = true
+					parents[0][next] = current.node
+					queue = append(queue, link{node: next, on: false})
+				}
+			}
+			continue
+		}
+
+		for _, next := range graph.strong[current.node] {
+			if next == start {
+				if !rules.loops {
+					continue
+				}
+
+				// the start being false makes it true, so it's true
+				chain := append(graph.path(parents, start, current.node, false), start)
+				position := graph.nodes[start].Cells[0]
+				value := graph.nodes[start].Value
+
+				return Step{
+					Technique:   rules.technique,
+					Cells:       graph.cells(chain),
+					Values:      graph.values(chain),
+					Placements:  []Placement{{Row: position.Row, Cell: position.Cell, Value: value}},
+					Description: fmt.Sprintf("%s, so %s is %d", graph.describe(chain), cellName(position.Row, position.Cell), value),
+				}, true
+			}
+			if visited[1][next] || graph.overlaps(start, next) {
+				continue
+			}
+
+			visited[1][next] = true
+			parents[1][next] = current.node
+			queue = append(queue, link{node: next, on: true})
+			chain := graph.path(parents, start, next, true)
+
+			if rules.open && len(chain) >= rules.shortest {
+				eliminations := graph.excludes[start].intersect(graph.excludes[next]).candidates()
+				if len(eliminations) > 0 {
+					return Step{
+						Technique:    rules.technique,
+						Cells:        graph.cells(chain),
+						Values:       graph.values(chain),
+						Eliminations: eliminations,
+						Description:  graph.describe(chain),
+					}, true
+				}
+			}
+
+			if rules.loops && len(chain) >= 4 && slices.Contains(graph.weak[next], start) && distinct(chain) {
+				// a continuous loop, every weak link in it is strong too
+				excluded := newCandidateSet(graph.excludes[start].size)
+				for i := 1; i < len(chain); i += 2 {
+					following := chain[(i+1)%len(chain)]
+					excluded = excluded.union(graph.excludes[chain[i]].intersect(graph.excludes[following]))
+				}
+
+				eliminations := excluded.candidates()
+				if len(eliminations) > 0 {
+					return Step{
+						Technique:    rules.technique,
+						Cells:        graph.cells(chain),
+						Values:       graph.values(chain),
+						Eliminations: eliminations,
+						Description:  graph.describe(append(chain, start)),
+					}, true
+				}
+			}
+		}
+	}
+
+	return Step{}, false
+}
+
+func distinct(chain []int) bool {
+	for i, node := range chain {
+		if slices.Contains(chain[i+1:], node) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Whether a grouped node shares a cell with another node of the same value. A
+// chain through both would say the same thing twice.
+func (graph chainGraph) overlaps(i int, j int) bool {
+	first, second := graph.nodes[i], graph.nodes[j]
+	if first.Value != second.Value || (!first.isGroup() && !second.isGroup()) {
+		return false
+	}
+
+	return !disjoint(first.Cells, second.Cells)
+}
+
+// The nodes from start to end, following the parents back. The start is
+// always off, and the nodes alternate between on and off from there.
+func (graph chainGraph) path(parents [2][]int, start int, end int, on bool) []int {
+	path := []int{end}
+	for len(path) == 1 || path[0] != start || on {
+		index := 0
+		if on {
+			index = 1
+		}
+		path = append([]int{parents[index][path[0]]}, path...)
+		on = !on
+	}
+
+	return path
+}
+
+func (graph chainGraph) cells(chain []int) []Position {
+	cells := []Position{}
+	for _, index := range chain {
+		for _, position := range graph.nodes[index].Cells {
+			if !containsPosition(cells, position) {
+				cells = append(cells, position)
+			}
+		}
+	}
+
+	return cells
+}
+
+func (graph chainGraph) values(chain []int) []int {
+	var values CandidateMask
+	for _, index := range chain {
+		values |= MaskFor(graph.nodes[index].Value)
+	}
+
+	return values.Values()
+}
+
+// The chain in Eureka notation, where = is a strong link and - is a weak one.
+// The links alternate starting with a strong one, and strong links inside a
+// single cell are written together, e.g. (4)r1c2=(4)r1c8-(4=7)r3c8.
+func (graph chainGraph) describe(chain []int) string {
+	var builder strings.Builder
+	linkAfter := func(i int) string {
+		if i%2 == 0 {
+			return "="
+		}
+		return "-"
+	}
+
+	for i := 0; i < len(chain); i++ {
+		node := graph.nodes[chain[i]]
+		if i+1 < len(chain) {
+			next := graph.nodes[chain[i+1]]
+			if linkAfter(i) == "=" && !node.isGroup() && !next.isGroup() && node.Cells[0] == next.Cells[0] {
+				builder.WriteString(fmt.Sprintf("(%d%s%d)%s", node.Value, linkAfter(i), next.Value, groupName(node.Cells)))
+				i++
+				if i+1 < len(chain) {
+					builder.WriteString(linkAfter(i))
+				}
+				continue
+			}
+		}
+
+		builder.WriteString(node.String())
+		if i+1 < len(chain) {
+			builder.WriteString(linkAfter(i))
+		}
+	}
+
+	return builder.String()
+}
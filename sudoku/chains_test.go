@@ -0,0 +1,117 @@
+package sudoku
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChains(t *testing.T) {
+	t.Run("x-chain", func(t *testing.T) {
+		grid := openCandidateGrid()
+		restrictColumns(&grid, 3, map[int][]int{0: {0, 6}, 4: {2, 6}})
+
+		step, found := findChain(&grid, chainTechniqueRules[XChain])
+
+		assert.True(t, found)
+		assert.Equal(t, XChain, step.Technique)
+		assert.Equal(t, "(3)r1c1=(3)r7c1-(3)r7c5=(3)r3c5", step.Description)
+		assert.Equal(t, []Candidate{
+			{Row: 0, Cell: 3, Value: 3},
+			{Row: 0, Cell: 5, Value: 3},
+			{Row: 2, Cell: 1, Value: 3},
+			{Row: 2, Cell: 2, Value: 3},
+		}, step.Eliminations)
+	})
+
+	t.Run("x-cycle, continuous loop", func(t *testing.T) {
+		grid := openCandidateGrid()
+		restrictRows(&grid, 7, map[int][]int{0: {0, 4}, 4: {4, 8}, 8: {0, 8}})
+
+		step, found := findChain(&grid, chainTechniqueRules[XCycle])
+
+		assert.True(t, found)
+		assert.Equal(t, XCycle, step.Technique)
+		assert.Equal(t, "(7)r1c1=(7)r1c5-(7)r5c5=(7)r5c9-(7)r9c9=(7)r9c1-(7)r1c1", step.Description)
+		assert.Len(t, step.Eliminations, 18)
+		assert.Equal(t, []int{7}, eliminatedFrom(step, 3, 4))
+	})
+
+	t.Run("x-cycle, discontinuous loop", func(t *testing.T) {
+		grid := openCandidateGrid()
+		restrictRows(&grid, 3, map[int][]int{0: {0, 4}, 3: {1, 4}})
+		restrictColumns(&grid, 3, map[int][]int{0: {0, 5}})
+
+		step, found := findChain(&grid, chainTechniqueRules[XCycle])
+
+		assert.True(t, found)
+		assert.Equal(t, []Placement{{Row: 0, Cell: 0, Value: 3}}, step.Placements)
+		assert.Empty(t, step.Eliminations)
+	})
+
+	t.Run("xy-chain", func(t *testing.T) {
+		grid := openCandidateGrid()
+		grid.Candidates[0][0] = maskOf(1, 2)
+		grid.Candidates[0][4] = maskOf(2, 3)
+		grid.Candidates[4][4] = maskOf(3, 4)
+		grid.Candidates[4][2] = maskOf(4, 1)
+
+		step, found := findChain(&grid, chainTechniqueRules[XYChain])
+
+		assert.True(t, found)
+		assert.Equal(t, XYChain, step.Technique)
+		assert.Equal(t, "(1=2)r1c1-(2=3)r1c5-(3=4)r5c5-(4=1)r5c3", step.Description)
+		assert.Equal(t, []Candidate{
+			{Row: 0, Cell: 2, Value: 1},
+			{Row: 1, Cell: 2, Value: 1},
+			{Row: 2, Cell: 2, Value: 1},
+			{Row: 3, Cell: 0, Value: 1},
+			{Row: 4, Cell: 0, Value: 1},
+			{Row: 5, Cell: 0, Value: 1},
+		}, step.Eliminations)
+	})
+
+	t.Run("xy-chain through two cells is too short", func(t *testing.T) {
+		grid := openCandidateGrid()
+		grid.Candidates[0][0] = maskOf(1, 2)
+		grid.Candidates[0][4] = maskOf(1, 2)
+
+		_, found := findChain(&grid, chainTechniqueRules[XYChain])
+
+		assert.False(t, found)
+	})
+
+	t.Run("aic with a grouped node", func(t *testing.T) {
+		grid := openCandidateGrid()
+		restrictRows(&grid, 5, map[int][]int{0: {0, 6, 7}})
+		grid.Candidates[1][8] = maskOf(5, 6)
+		grid.Candidates[1][1] = maskOf(5, 6)
+
+		step, found := findChain(&grid, chainTechniqueRules[AIC])
+
+		assert.True(t, found)
+		assert.Equal(t, AIC, step.Technique)
+		assert.Equal(t, "(5)r1c1=(5)r1c78-(5=6)r2c9-(6=5)r2c2", step.Description)
+		assert.Equal(t, []Candidate{
+			{Row: 1, Cell: 0, Value: 5},
+			{Row: 1, Cell: 2, Value: 5},
+			{Row: 2, Cell: 0, Value: 5},
+			{Row: 2, Cell: 1, Value: 5},
+			{Row: 2, Cell: 2, Value: 5},
+		}, step.Eliminations)
+	})
+
+	t.Run("nothing to find", func(t *testing.T) {
+		grid := openCandidateGrid()
+
+		for _, technique := range []Technique{XChain, XCycle, XYChain, AIC} {
+			_, found := findChain(&grid, chainTechniqueRules[technique])
+			assert.False(t, found, string(technique))
+		}
+	})
+}
+
+func TestGroupName(t *testing.T) {
+	assert.Equal(t, "r1c789", groupName([]Position{{Row: 0, Cell: 6}, {Row: 0, Cell: 7}, {Row: 0, Cell: 8}}))
+	assert.Equal(t, "r45c2", groupName([]Position{{Row: 3, Cell: 1}, {Row: 4, Cell: 1}}))
+}
@@ -0,0 +1,230 @@
+package sudoku
+
+import (
+	"fmt"
+)
+
+// The cells of a value linked by conjugate pairs, units where the value can
+// only go in two cells. Neighbouring cells get opposite colors, so the value
+// is either in every cell of one color or in every cell of the other.
+type colorCluster struct {
+	value  int
+	colors [2][]Position
+}
+
+func (cluster colorCluster) contains(position Position) bool {
+	return containsPosition(cluster.colors[0], position) || containsPosition(cluster.colors[1], position)
+}
+
+func (cluster colorCluster) String() string {
+	return fmt.Sprintf("%d is either in %s or in %s", cluster.value, cellNames(cluster.colors[0]), cellNames(cluster.colors[1]))
+}
+
+func joinPositions(lists ...[]Position) []Position {
+	joined := []Position{}
+	for _, list := range lists {
+		joined = append(joined, list...)
+	}
+
+	return joined
+}
+
+func (layout Layout) seesAny(position Position, cells []Position) bool {
+	for _, other := range cells {
+		if layout.sees(position, other) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (layout Layout) anySees(cells []Position, others []Position) bool {
+	for _, position := range cells {
+		if layout.seesAny(position, others) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func colorClusters(grid *CandidateGrid, value int) []colorCluster {
+	links := map[Position][]Position{}
+	for _, unit := range grid.Layout.Units() {
+		positions := grid.positionsFor(unit, value)
+		if len(positions) != 2 {
+			continue
+		}
+
+		links[positions[0]] = append(links[positions[0]], positions[1])
+		links[positions[1]] = append(links[positions[1]], positions[0])
+	}
+
+	size := grid.Layout.Size()
+	clusters := []colorCluster{}
+	colored := map[Position]bool{}
+	for row := range size {
+		for cell := range size {
+			start := Position{Row: row, Cell: cell}
+			if colored[start] || len(links[start]) == 0 {
+				continue
+			}
+
+			cluster := colorCluster{value: value}
+			colorOf := map[Position]int{start: 0}
+			colored[start] = true
+			queue := []Position{start}
+			for len(queue) > 0 {
+				position := queue[0]
+				queue = queue[1:]
+				cluster.colors[colorOf[position]] = append(cluster.colors[colorOf[position]], position)
+
+				for _, next := range links[position] {
+					if colored[next] {
+						continue
+					}
+					colored[next] = true
+					colorOf[next] = 1 - colorOf[position]
+					queue = append(queue, next)
+				}
+			}
+
+			clusters = append(clusters, cluster)
+		}
+	}
+
+	return clusters
+}
+
+// Color the conjugate pairs of a value. When two cells of the same color see
+// each other, that color can't be the one holding the value (a color wrap).
+// Any other cell that sees both colors can't hold the value either (a color
+// trap).
+func findSimpleColoring(grid *CandidateGrid) (Step, bool) {
+	size := grid.Layout.Size()
+	for value := 1; value <= size; value++ {
+		for _, cluster := range colorClusters(grid, value) {
+			for color, cells := range cluster.colors {
+				for i, position := range cells {
+					if !grid.Layout.seesAny(position, cells[i+1:]) {
+						continue
+					}
+
+					eliminations := []Candidate{}
+					for _, cell := range cells {
+						eliminations = append(eliminations, Candidate{Row: cell.Row, Cell: cell.Cell, Value: value})
+					}
+
+					return Step{
+						Technique:    SimpleColoring,
+						Cells:        joinPositions(cluster.colors[0], cluster.colors[1]),
+						Values:       []int{value},
+						Eliminations: eliminations,
+						Description:  fmt.Sprintf("%s, two of %s see each other so it's %s", cluster, cellNames(cells), cellNames(cluster.colors[1-color])),
+					}, true
+				}
+			}
+
+			eliminations := []Candidate{}
+			for row := range size {
+				for cell := range size {
+					position := Position{Row: row, Cell: cell}
+					if !grid.has(position, value) || cluster.contains(position) {
+						continue
+					}
+
+					if grid.Layout.seesAny(position, cluster.colors[0]) && grid.Layout.seesAny(position, cluster.colors[1]) {
+						eliminations = append(eliminations, Candidate{Row: row, Cell: cell, Value: value})
+					}
+				}
+			}
+
+			if len(eliminations) > 0 {
+				return Step{
+					Technique:    SimpleColoring,
+					Cells:        joinPositions(cluster.colors[0], cluster.colors[1]),
+					Values:       []int{value},
+					Eliminations: eliminations,
+					Description:  fmt.Sprintf("%s, so cells that see both can't be %d", cluster, value),
+				}, true
+			}
+		}
+	}
+
+	return Step{}, false
+}
+
+// Compare two color clusters of the same value. When a color of one cluster
+// sees a color of the other, they can't both hold the value, so one of their
+// opposite colors does, and no cell that sees both opposites can. When a color
+// sees both colors of the other cluster, it can't hold the value at all.
+func findMultiColoring(grid *CandidateGrid) (Step, bool) {
+	size := grid.Layout.Size()
+	for value := 1; value <= size; value++ {
+		clusters := colorClusters(grid, value)
+
+		for i, first := range clusters {
+			for j, second := range clusters {
+				if i == j {
+					continue
+				}
+
+				for a := range 2 {
+					if grid.Layout.anySees(first.colors[a], second.colors[0]) && grid.Layout.anySees(first.colors[a], second.colors[1]) {
+						eliminations := []Candidate{}
+						for _, cell := range first.colors[a] {
+							eliminations = append(eliminations, Candidate{Row: cell.Row, Cell: cell.Cell, Value: value})
+						}
+
+						return Step{
+							Technique:    MultiColoring,
+							Cells:        joinPositions(first.colors[a], second.colors[0], second.colors[1]),
+							Values:       []int{value},
+							Eliminations: eliminations,
+							Description:  fmt.Sprintf("%s, and %s, %s sees both sides of the second", first, second, cellNames(first.colors[a])),
+						}, true
+					}
+
+					if j < i {
+						// the other way around was already tried
+						continue
+					}
+
+					for b := range 2 {
+						if !grid.Layout.anySees(first.colors[a], second.colors[b]) {
+							continue
+						}
+
+						opposites := [2][]Position{first.colors[1-a], second.colors[1-b]}
+						eliminations := []Candidate{}
+						for row := range size {
+							for cell := range size {
+								position := Position{Row: row, Cell: cell}
+								if !grid.has(position, value) || first.contains(position) || second.contains(position) {
+									continue
+								}
+
+								if grid.Layout.seesAny(position, opposites[0]) && grid.Layout.seesAny(position, opposites[1]) {
+									eliminations = append(eliminations, Candidate{Row: row, Cell: cell, Value: value})
+								}
+							}
+						}
+
+						if len(eliminations) > 0 {
+							return Step{
+								Technique:    MultiColoring,
+								Cells:        joinPositions(opposites[0], opposites[1]),
+								Values:       []int{value},
+								Eliminations: eliminations,
+								Description:  fmt.Sprintf("%s, and %s, %s and %s can't both hold it so it's in %s or %s", first, second, cellNames(first.colors[a]), cellNames(second.colors[b]), cellNames(opposites[0]), cellNames(opposites[1])),
+							}, true
+						}
+					}
+				}
+			}
+		}
+	}
+
+	return Step{}, false
+}
@@ -0,0 +1,79 @@
+package sudoku
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Leave value as a candidate in only the given rows of each column.
+func restrictColumns(grid *CandidateGrid, value int, rowsByColumn map[int][]int) {
+	for cell, rows := range rowsByColumn {
+		for row := range 9 {
+			if !containsInt(rows, row) {
+				grid.Candidates[row][cell] &^= MaskFor(value)
+			}
+		}
+	}
+}
+
+func TestColoring(t *testing.T) {
+	t.Run("color trap", func(t *testing.T) {
+		grid := openCandidateGrid()
+		restrictRows(&grid, 5, map[int][]int{0: {0, 4}, 4: {1, 4}})
+		restrictColumns(&grid, 5, map[int][]int{4: {0, 4}})
+
+		step, found := findSimpleColoring(&grid)
+
+		assert.True(t, found)
+		assert.Equal(t, SimpleColoring, step.Technique)
+		assert.Equal(t, []Candidate{
+			{Row: 1, Cell: 1, Value: 5},
+			{Row: 2, Cell: 1, Value: 5},
+			{Row: 3, Cell: 0, Value: 5},
+			{Row: 5, Cell: 0, Value: 5},
+		}, step.Eliminations)
+		assert.Equal(t, "5 is either in r1c1,r5c5 or in r1c5,r5c2, so cells that see both can't be 5", step.Description)
+	})
+
+	t.Run("color wrap", func(t *testing.T) {
+		grid := openCandidateGrid()
+		restrictRows(&grid, 5, map[int][]int{0: {0, 4}, 3: {1, 4}})
+		restrictColumns(&grid, 5, map[int][]int{4: {0, 3}, 1: {1, 3}})
+
+		step, found := findSimpleColoring(&grid)
+
+		assert.True(t, found)
+		assert.Equal(t, []Candidate{{Row: 0, Cell: 0, Value: 5}, {Row: 3, Cell: 4, Value: 5}, {Row: 1, Cell: 1, Value: 5}}, step.Eliminations)
+	})
+
+	t.Run("multi-coloring", func(t *testing.T) {
+		grid := openCandidateGrid()
+		restrictRows(&grid, 5, map[int][]int{0: {0, 4}})
+		for _, position := range StandardShape.Units()[21] {
+			if position != (Position{Row: 3, Cell: 0}) && position != (Position{Row: 5, Cell: 2}) {
+				grid.Candidates[position.Row][position.Cell] &^= MaskFor(5)
+			}
+		}
+
+		_, found := findSimpleColoring(&grid)
+		assert.False(t, found)
+
+		step, found := findMultiColoring(&grid)
+
+		assert.True(t, found)
+		assert.Equal(t, MultiColoring, step.Technique)
+		assert.Equal(t, []Candidate{{Row: 5, Cell: 4, Value: 5}}, step.Eliminations)
+	})
+
+	t.Run("multi-coloring, a color that sees both colors of another cluster", func(t *testing.T) {
+		grid := openCandidateGrid()
+		restrictRows(&grid, 5, map[int][]int{0: {0, 4}, 1: {1, 6}, 5: {0, 6}})
+		restrictColumns(&grid, 5, map[int][]int{6: {1, 5}})
+
+		step, found := findMultiColoring(&grid)
+
+		assert.True(t, found)
+		assert.Equal(t, []Candidate{{Row: 0, Cell: 0, Value: 5}}, step.Eliminations)
+	})
+}
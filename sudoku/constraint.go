@@ -0,0 +1,591 @@
+package sudoku
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// A rule the values of a puzzle have to follow. Every puzzle has the row,
+// column, and sector constraints, and a variant adds its own on top of them,
+// like the diagonals of a Sudoku-X.
+type Constraint interface {
+	// What the constraint is called, e.g. "row" or "anti-knight".
+	Name() string
+	// The cells the constraint has a say in.
+	Cells(layout Layout) []Position
+	// Rule out the candidates of an empty cell that would break the
+	// constraint, given the values already on the board.
+	Prune(puz *Puzzle, row int, cell int, candidates CandidateMask) CandidateMask
+	// The first place the values on the board break the constraint, as a
+	// Violation, or nil if they don't.
+	Validate(puz *Puzzle) error
+}
+
+// Where and how the values on a board break a constraint.
+type Violation struct {
+	// the cells whose values clash
+	Cells       []Position
+	Description string
+}
+
+func (violation Violation) Error() string {
+	return violation.Description
+}
+
+// The variant constraints a puzzle can opt into by name.
+var VariantNames = []string{"diagonal", "windoku", "anti-knight", "anti-king", "non-consecutive"}
+
+func ParseConstraint(name string) (Constraint, error) {
+	switch name {
+	case "diagonal":
+		return Diagonal{}, nil
+	case "windoku":
+		return Windoku{}, nil
+	case "anti-knight":
+		return AntiKnight{}, nil
+	case "anti-king":
+		return AntiKing{}, nil
+	case "non-consecutive":
+		return NonConsecutive{}, nil
+	default:
+		return nil, fmt.Errorf("Unrecognized constraint '%s', expected one of %v", name, VariantNames)
+	}
+}
+
+// Parse a list of constraint names separated by commas or spaces, e.g.
+// "diagonal,anti-knight".
+func ParseConstraints(names string) ([]Constraint, error) {
+	constraints := []Constraint{}
+	for _, name := range strings.FieldsFunc(names, func(r rune) bool { return r == ',' || r == ' ' }) {
+		constraint, err := ParseConstraint(name)
+		if err != nil {
+			return nil, err
+		}
+		constraints = append(constraints, constraint)
+	}
+
+	return constraints, nil
+}
+
+// The kinds of line ParseConstraintLines reads, each followed by a colon.
+var constraintLineKinds = []string{"constraints", "thermo", "arrow", "white", "black", "x", "v"}
+
+// Parse the constraints of a variant, one per line, each line starting with
+// its kind:
+//
+//	constraints: diagonal, anti-knight
+//	thermo: r1c1 r1c2 r2c3
+//	arrow: r5c5 r4c4 r3c3
+//	white: r1c1 r1c2
+//	black: r2c1 r3c1
+//	x: r4c4 r4c5
+//	v: r6c6 r7c6
+//
+// A `constraints:` line lists variants by name, see ParseConstraints. The
+// rest lay a constraint out on the board: a thermometer from its bulb to its
+// tip, an arrow from its circle to its tip, and a kropki dot or an X or V
+// between the two cells it sits between.
+func ParseConstraintLines(lines []string) ([]Constraint, error) {
+	constraints := []Constraint{}
+
+	for _, line := range lines {
+		kind, rest, found := strings.Cut(line, ":")
+		kind = strings.ToLower(strings.TrimSpace(kind))
+		if !found || !slices.Contains(constraintLineKinds, kind) {
+			return nil, fmt.Errorf("Unrecognized constraint line '%s', expected one of %v followed by a colon", line, constraintLineKinds)
+		}
+
+		if kind == "constraints" {
+			variants, err := ParseConstraints(rest)
+			if err != nil {
+				return nil, err
+			}
+			constraints = append(constraints, variants...)
+			continue
+		}
+
+		cells := []Position{}
+		for _, field := range strings.Fields(rest) {
+			position, err := ParsePosition(field)
+			if err != nil {
+				return nil, err
+			}
+			cells = append(cells, position)
+		}
+
+		switch kind {
+		case "thermo", "arrow":
+			if len(cells) < 2 {
+				return nil, fmt.Errorf("the %s line '%s' needs at least 2 cells", kind, line)
+			}
+		default:
+			if len(cells) != 2 {
+				return nil, fmt.Errorf("the %s line '%s' needs exactly 2 cells", kind, line)
+			}
+		}
+
+		switch kind {
+		case "thermo":
+			constraints = append(constraints, Thermometer{Path: cells})
+		case "arrow":
+			constraints = append(constraints, Arrow{Circle: cells[0], Path: cells[1:]})
+		case "white", "black":
+			constraints = append(constraints, KropkiDot{Pair: [2]Position{cells[0], cells[1]}, Black: kind == "black"})
+		case "x", "v":
+			constraints = append(constraints, XVPair{Pair: [2]Position{cells[0], cells[1]}, Sum: pick(kind == "x", 10, 5)})
+		}
+	}
+
+	return constraints, nil
+}
+
+// Parse a cell in r1c1 notation, counting rows and columns from 1.
+func ParsePosition(name string) (Position, error) {
+	invalid := fmt.Errorf("invalid cell '%s', expected r1c1 notation", name)
+
+	unparsedRow, unparsedCell, found := strings.Cut(strings.TrimPrefix(strings.ToLower(name), "r"), "c")
+	if !found || !strings.HasPrefix(strings.ToLower(name), "r") {
+		return Position{}, invalid
+	}
+	row, err := strconv.Atoi(unparsedRow)
+	if err != nil || row < 1 {
+		return Position{}, invalid
+	}
+	cell, err := strconv.Atoi(unparsedCell)
+	if err != nil || cell < 1 {
+		return Position{}, invalid
+	}
+
+	return Position{Row: row - 1, Cell: cell - 1}, nil
+}
+
+// A constraint laid out on particular cells, which has to check that they
+// make sense together, e.g. that a thermometer's cells touch.
+type cellChecker interface {
+	checkCells(size int) error
+}
+
+// Check that every cell of the constraints is on the board and that the ones
+// laid out on the board make sense.
+func CheckConstraints(constraints []Constraint, layout Layout) error {
+	size := layout.Size()
+
+	for _, constraint := range constraints {
+		for _, position := range constraint.Cells(layout) {
+			if position.Row < 0 || position.Row >= size || position.Cell < 0 || position.Cell >= size {
+				return fmt.Errorf("the %s constraint has cell %s, which isn't on the board", constraint.Name(), position)
+			}
+		}
+
+		if checker, ok := constraint.(cellChecker); ok {
+			err := checker.checkCells(size)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// The names of the constraints, separated by commas, the way ParseConstraints
+// reads them.
+func ConstraintNames(constraints []Constraint) string {
+	names := []string{}
+	for _, constraint := range constraints {
+		names = append(names, constraint.Name())
+	}
+
+	return strings.Join(names, ",")
+}
+
+// The constraints' names for people to read, e.g. "diagonal, anti-knight",
+// with each name only once however many thermometers or dots there are.
+func ConstraintList(constraints []Constraint) string {
+	names := []string{}
+	for _, constraint := range constraints {
+		if !slices.Contains(names, constraint.Name()) {
+			names = append(names, constraint.Name())
+		}
+	}
+
+	return strings.Join(names, ", ")
+}
+
+// Every rule the puzzle's values have to follow: no repeats in a row, column,
+// or sector, the cages of a Killer Sudoku, and then the puzzle's variant
+// constraints.
+func (puz *Puzzle) AllConstraints() []Constraint {
+	constraints := []Constraint{rowConstraint{}, columnConstraint{}, sectorConstraint{}}
+	return append(constraints, puz.extraConstraints()...)
+}
+
+// The puzzle's constraints beyond its rows, columns, and sectors.
+func (puz *Puzzle) extraConstraints() []Constraint {
+	constraints := []Constraint{}
+	if puz.Cages != nil {
+		constraints = append(constraints, cageConstraint{cages: puz.Cages})
+	}
+
+	return append(constraints, puz.Constraints...)
+}
+
+func allCells(layout Layout) []Position {
+	cells := []Position{}
+	for _, row := range layout.Units()[:layout.Size()] {
+		cells = append(cells, row...)
+	}
+
+	return cells
+}
+
+// The first value that shows up twice in a unit, named e.g. "row 3" or
+// "cage 'a'", where the cell is counted among the unit's filled in cells.
+func checkForDuplicates(puz *Puzzle, unit []Position, name string) error {
+	var seen CandidateMask
+	filled := 0
+	for _, position := range unit {
+		value := puz.ValueAt(position.Row, position.Cell)
+		if value == 0 {
+			continue
+		}
+
+		if seen.Has(value) {
+			other := unit[slices.IndexFunc(unit, func(p Position) bool { return puz.ValueAt(p.Row, p.Cell) == value })]
+			return Violation{
+				Cells:       []Position{other, position},
+				Description: fmt.Sprintf("Duplicate check failed, value '%d' in %s, cell %d", value, name, filled+1),
+			}
+		}
+		seen |= MaskFor(value)
+		filled++
+	}
+
+	return nil
+}
+
+// Check each of the units for a repeated value.
+func checkUnitsForDuplicates(puz *Puzzle, units [][]Position, kind string) error {
+	for i, unit := range units {
+		err := checkForDuplicates(puz, unit, fmt.Sprintf("%s %d", kind, i+1))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Remove the values already in the unit from the candidates.
+func pruneUnit(puz *Puzzle, unit []Position, candidates CandidateMask) CandidateMask {
+	for _, position := range unit {
+		candidates &^= MaskFor(puz.ValueAt(position.Row, position.Cell))
+	}
+
+	return candidates
+}
+
+type rowConstraint struct{}
+
+func (rowConstraint) Name() string { return "row" }
+
+func (rowConstraint) Cells(layout Layout) []Position { return allCells(layout) }
+
+func (rowConstraint) Prune(puz *Puzzle, row int, cell int, candidates CandidateMask) CandidateMask {
+	return candidates &^ puz.state().rowMasks[row]
+}
+
+func (rowConstraint) Validate(puz *Puzzle) error {
+	layout := puz.Layout()
+	return checkUnitsForDuplicates(puz, layout.Units()[:layout.Size()], "row")
+}
+
+type columnConstraint struct{}
+
+func (columnConstraint) Name() string { return "column" }
+
+func (columnConstraint) Cells(layout Layout) []Position { return allCells(layout) }
+
+func (columnConstraint) Prune(puz *Puzzle, row int, cell int, candidates CandidateMask) CandidateMask {
+	return candidates &^ puz.state().columnMasks[cell]
+}
+
+func (columnConstraint) Validate(puz *Puzzle) error {
+	layout := puz.Layout()
+	return checkUnitsForDuplicates(puz, layout.Units()[layout.Size():2*layout.Size()], "column")
+}
+
+type sectorConstraint struct{}
+
+func (sectorConstraint) Name() string { return "sector" }
+
+func (sectorConstraint) Cells(layout Layout) []Position { return allCells(layout) }
+
+func (sectorConstraint) Prune(puz *Puzzle, row int, cell int, candidates CandidateMask) CandidateMask {
+	search := puz.state()
+	return candidates &^ search.sectorMasks[search.layout.SectorIndexFor(row, cell)]
+}
+
+func (sectorConstraint) Validate(puz *Puzzle) error {
+	layout := puz.Layout()
+	return checkUnitsForDuplicates(puz, layout.Units()[2*layout.Size():], "sector")
+}
+
+// Sudoku-X: both of the board's long diagonals hold every value once.
+type Diagonal struct{}
+
+func (Diagonal) Name() string { return "diagonal" }
+
+// The main diagonal first, top-left to bottom-right, then the anti-diagonal,
+// top-right to bottom-left.
+func (Diagonal) diagonals(size int) [][]Position {
+	main := []Position{}
+	anti := []Position{}
+	for i := range size {
+		main = append(main, Position{Row: i, Cell: i})
+		anti = append(anti, Position{Row: i, Cell: size - 1 - i})
+	}
+
+	return [][]Position{main, anti}
+}
+
+func (diagonal Diagonal) Cells(layout Layout) []Position {
+	cells := []Position{}
+	for _, unit := range diagonal.diagonals(layout.Size()) {
+		for _, position := range unit {
+			if !containsPosition(cells, position) {
+				cells = append(cells, position)
+			}
+		}
+	}
+
+	return cells
+}
+
+func (diagonal Diagonal) Prune(puz *Puzzle, row int, cell int, candidates CandidateMask) CandidateMask {
+	size := puz.state().layout.Size()
+	if row == cell {
+		for i := range size {
+			candidates &^= MaskFor(puz.ValueAt(i, i))
+		}
+	}
+	if row+cell == size-1 {
+		for i := range size {
+			candidates &^= MaskFor(puz.ValueAt(i, size-1-i))
+		}
+	}
+
+	return candidates
+}
+
+func (diagonal Diagonal) Validate(puz *Puzzle) error {
+	return checkUnitsForDuplicates(puz, diagonal.diagonals(puz.Size()), "diagonal")
+}
+
+// Windoku, or Hyper Sudoku: four extra boxes, one cell in from the edges and
+// one cell apart, also hold every value once. On other board sizes the
+// windows are laid out the same way, as many as fit.
+type Windoku struct{}
+
+func (Windoku) Name() string { return "windoku" }
+
+// Where the windows start along a side of the board, each window taking up
+// length cells with a cell between it and the next one.
+func windowStarts(length int, size int) []int {
+	starts := []int{}
+	for start := 1; start+length < size; start += length + 1 {
+		starts = append(starts, start)
+	}
+
+	return starts
+}
+
+func (Windoku) windows(shape Shape) [][]Position {
+	size := shape.Size()
+	windows := [][]Position{}
+	for _, firstRow := range windowStarts(shape.BoxRows, size) {
+		for _, firstCell := range windowStarts(shape.BoxColumns, size) {
+			window := []Position{}
+			for row := firstRow; row < firstRow+shape.BoxRows; row++ {
+				for cell := firstCell; cell < firstCell+shape.BoxColumns; cell++ {
+					window = append(window, Position{Row: row, Cell: cell})
+				}
+			}
+			windows = append(windows, window)
+		}
+	}
+
+	return windows
+}
+
+func (windoku Windoku) Cells(layout Layout) []Position {
+	cells := []Position{}
+	for _, window := range windoku.windows(layout.Shape) {
+		cells = append(cells, window...)
+	}
+
+	return cells
+}
+
+func (windoku Windoku) Prune(puz *Puzzle, row int, cell int, candidates CandidateMask) CandidateMask {
+	shape := puz.state().layout.Shape
+	size := shape.Size()
+
+	for _, firstRow := range windowStarts(shape.BoxRows, size) {
+		if row < firstRow || row >= firstRow+shape.BoxRows {
+			continue
+		}
+		for _, firstCell := range windowStarts(shape.BoxColumns, size) {
+			if cell < firstCell || cell >= firstCell+shape.BoxColumns {
+				continue
+			}
+
+			for r := firstRow; r < firstRow+shape.BoxRows; r++ {
+				for c := firstCell; c < firstCell+shape.BoxColumns; c++ {
+					candidates &^= MaskFor(puz.ValueAt(r, c))
+				}
+			}
+		}
+	}
+
+	return candidates
+}
+
+func (windoku Windoku) Validate(puz *Puzzle) error {
+	return checkUnitsForDuplicates(puz, windoku.windows(puz.GridShape()), "window")
+}
+
+// Cells a given move apart can't have the same value.
+type moveConstraint struct {
+	offsets []Position
+	// how the move is described when two cells break the constraint
+	move string
+}
+
+var knightMoves = moveConstraint{
+	offsets: []Position{{-2, -1}, {-2, 1}, {-1, -2}, {-1, 2}, {1, -2}, {1, 2}, {2, -1}, {2, 1}},
+	move:    "a knight's move",
+}
+
+var kingMoves = moveConstraint{
+	offsets: []Position{{-1, -1}, {-1, 0}, {-1, 1}, {0, -1}, {0, 1}, {1, -1}, {1, 0}, {1, 1}},
+	move:    "a king's move",
+}
+
+// The cells a move away from the given one that are on the board.
+func neighbors(offsets []Position, size int, row int, cell int) []Position {
+	positions := []Position{}
+	for _, offset := range offsets {
+		r, c := row+offset.Row, cell+offset.Cell
+		if r >= 0 && r < size && c >= 0 && c < size {
+			positions = append(positions, Position{Row: r, Cell: c})
+		}
+	}
+
+	return positions
+}
+
+func (moves moveConstraint) prune(puz *Puzzle, row int, cell int, candidates CandidateMask) CandidateMask {
+	return pruneUnit(puz, neighbors(moves.offsets, puz.state().layout.Size(), row, cell), candidates)
+}
+
+func (moves moveConstraint) validate(puz *Puzzle) error {
+	size := puz.Size()
+	for row := range size {
+		for cell := range size {
+			value := puz.ValueAt(row, cell)
+			if value == 0 {
+				continue
+			}
+
+			for _, neighbor := range neighbors(moves.offsets, size, row, cell) {
+				if puz.ValueAt(neighbor.Row, neighbor.Cell) == value {
+					position := Position{Row: row, Cell: cell}
+					return Violation{
+						Cells:       []Position{position, neighbor},
+						Description: fmt.Sprintf("value '%d' is in both %s and %s, %s apart", value, position, neighbor, moves.move),
+					}
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// Cells a chess knight's move apart can't have the same value.
+type AntiKnight struct{}
+
+func (AntiKnight) Name() string { return "anti-knight" }
+
+func (AntiKnight) Cells(layout Layout) []Position { return allCells(layout) }
+
+func (AntiKnight) Prune(puz *Puzzle, row int, cell int, candidates CandidateMask) CandidateMask {
+	return knightMoves.prune(puz, row, cell, candidates)
+}
+
+func (AntiKnight) Validate(puz *Puzzle) error {
+	return knightMoves.validate(puz)
+}
+
+// Cells that touch, even diagonally, can't have the same value.
+type AntiKing struct{}
+
+func (AntiKing) Name() string { return "anti-king" }
+
+func (AntiKing) Cells(layout Layout) []Position { return allCells(layout) }
+
+func (AntiKing) Prune(puz *Puzzle, row int, cell int, candidates CandidateMask) CandidateMask {
+	return kingMoves.prune(puz, row, cell, candidates)
+}
+
+func (AntiKing) Validate(puz *Puzzle) error {
+	return kingMoves.validate(puz)
+}
+
+// Cells that share a side can't have values one apart.
+type NonConsecutive struct{}
+
+var sideOffsets = []Position{{-1, 0}, {1, 0}, {0, -1}, {0, 1}}
+
+func (NonConsecutive) Name() string { return "non-consecutive" }
+
+func (NonConsecutive) Cells(layout Layout) []Position { return allCells(layout) }
+
+func (NonConsecutive) Prune(puz *Puzzle, row int, cell int, candidates CandidateMask) CandidateMask {
+	for _, neighbor := range neighbors(sideOffsets, puz.state().layout.Size(), row, cell) {
+		value := puz.ValueAt(neighbor.Row, neighbor.Cell)
+		if value != 0 {
+			candidates &^= MaskFor(value-1) | MaskFor(value+1)
+		}
+	}
+
+	return candidates
+}
+
+func (NonConsecutive) Validate(puz *Puzzle) error {
+	size := puz.Size()
+	for row := range size {
+		for cell := range size {
+			value := puz.ValueAt(row, cell)
+			if value == 0 {
+				continue
+			}
+
+			for _, neighbor := range neighbors(sideOffsets, size, row, cell) {
+				other := puz.ValueAt(neighbor.Row, neighbor.Cell)
+				if other == value-1 || other == value+1 {
+					position := Position{Row: row, Cell: cell}
+					return Violation{
+						Cells:       []Position{position, neighbor},
+						Description: fmt.Sprintf("values '%d' in %s and '%d' in %s are next to each other and consecutive", value, position, other, neighbor),
+					}
+				}
+			}
+		}
+	}
+
+	return nil
+}
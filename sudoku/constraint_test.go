@@ -0,0 +1,333 @@
+package sudoku
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func emptyBoard(size int) [][]int {
+	board := make([][]int, size)
+	for row := range board {
+		board[row] = make([]int, size)
+	}
+
+	return board
+}
+
+func TestParseConstraints(t *testing.T) {
+	constraints, err := ParseConstraints("diagonal, anti-knight,windoku")
+
+	assert.NoError(t, err)
+	assert.Equal(t, []Constraint{Diagonal{}, AntiKnight{}, Windoku{}}, constraints)
+	assert.Equal(t, "diagonal,anti-knight,windoku", ConstraintNames(constraints))
+	assert.Equal(t, "diagonal, anti-knight, windoku", ConstraintList(constraints))
+
+	constraints, err = ParseConstraints("")
+	assert.NoError(t, err)
+	assert.Empty(t, constraints)
+
+	_, err = ParseConstraints("diagonal,anti-bishop")
+	assert.ErrorContains(t, err, "Unrecognized constraint 'anti-bishop'")
+
+	for _, name := range VariantNames {
+		constraint, err := ParseConstraint(name)
+		assert.NoError(t, err)
+		assert.Equal(t, name, constraint.Name())
+	}
+}
+
+func TestAllConstraints(t *testing.T) {
+	cages, _ := ParseCages(killerRows)
+
+	plain := Puzzle{Board: emptyBoard(4)}
+	assert.Equal(t, "row,column,sector", ConstraintNames(plain.AllConstraints()))
+
+	variant := Puzzle{Board: emptyBoard(4), Cages: cages, Constraints: []Constraint{AntiKing{}}}
+	assert.Equal(t, "row,column,sector,cage,anti-king", ConstraintNames(variant.AllConstraints()))
+}
+
+func TestConstraintCells(t *testing.T) {
+	layout := Layout{Shape: StandardShape}
+
+	assert.Len(t, Diagonal{}.Cells(layout), 17)
+	assert.Len(t, Windoku{}.Cells(layout), 36)
+	assert.Contains(t, Windoku{}.Cells(layout), Position{Row: 7, Cell: 7})
+	assert.NotContains(t, Windoku{}.Cells(layout), Position{Row: 4, Cell: 4})
+	assert.Len(t, AntiKnight{}.Cells(layout), 81)
+
+	// a 6x6 board with 2x3 boxes only has room for one window that stays a
+	// cell in from the edges
+	assert.Equal(t, [][]Position{
+		{{1, 1}, {1, 2}, {1, 3}, {2, 1}, {2, 2}, {2, 3}},
+	}, Windoku{}.windows(Shape{BoxRows: 2, BoxColumns: 3}))
+}
+
+func TestConstraintPrune(t *testing.T) {
+	tests := []struct {
+		name       string
+		constraint Constraint
+		// where a 5 goes on an otherwise empty board
+		placed   Position
+		pruned   []Position
+		unpruned []Position
+	}{
+		{
+			name:       "diagonal",
+			constraint: Diagonal{},
+			placed:     Position{Row: 0, Cell: 0},
+			pruned:     []Position{{Row: 8, Cell: 8}, {Row: 4, Cell: 4}},
+			unpruned:   []Position{{Row: 0, Cell: 8}, {Row: 3, Cell: 5}},
+		},
+		{
+			name:       "windoku",
+			constraint: Windoku{},
+			placed:     Position{Row: 1, Cell: 1},
+			pruned:     []Position{{Row: 3, Cell: 3}, {Row: 2, Cell: 2}},
+			unpruned:   []Position{{Row: 5, Cell: 5}, {Row: 4, Cell: 4}},
+		},
+		{
+			name:       "anti-knight",
+			constraint: AntiKnight{},
+			placed:     Position{Row: 4, Cell: 4},
+			pruned:     []Position{{Row: 2, Cell: 3}, {Row: 6, Cell: 5}, {Row: 5, Cell: 2}},
+			unpruned:   []Position{{Row: 5, Cell: 5}, {Row: 2, Cell: 2}},
+		},
+		{
+			name:       "anti-king",
+			constraint: AntiKing{},
+			placed:     Position{Row: 0, Cell: 0},
+			pruned:     []Position{{Row: 1, Cell: 1}, {Row: 0, Cell: 1}},
+			unpruned:   []Position{{Row: 2, Cell: 2}, {Row: 0, Cell: 2}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			puzzle := Puzzle{Board: emptyBoard(9), Constraints: []Constraint{tt.constraint}}
+			puzzle.PlaceValue(tt.placed.Row, tt.placed.Cell, 5)
+
+			for _, position := range tt.pruned {
+				candidates := tt.constraint.Prune(&puzzle, position.Row, position.Cell, StandardShape.AllValuesMask())
+				assert.False(t, candidates.Has(5), "5 should be pruned from %s", position)
+				assert.False(t, puzzle.Candidates(position.Row, position.Cell).Has(5))
+			}
+			for _, position := range tt.unpruned {
+				candidates := tt.constraint.Prune(&puzzle, position.Row, position.Cell, StandardShape.AllValuesMask())
+				assert.True(t, candidates.Has(5), "5 shouldn't be pruned from %s", position)
+			}
+		})
+	}
+
+	t.Run("non-consecutive", func(t *testing.T) {
+		puzzle := Puzzle{Board: emptyBoard(9), Constraints: []Constraint{NonConsecutive{}}}
+		puzzle.PlaceValue(4, 4, 5)
+
+		assert.Equal(t, []int{1, 2, 3, 7, 8, 9}, puzzle.Candidates(3, 4).Values())
+		assert.Equal(t, []int{1, 2, 3, 4, 6, 7, 8, 9}, puzzle.Candidates(3, 3).Values())
+		assert.Equal(t, []int{1, 2, 3, 4, 5, 6, 7, 8, 9}, puzzle.Candidates(0, 0).Values())
+
+		// a 1 only rules out 2 next to it
+		puzzle.PlaceValue(0, 0, 1)
+		assert.Equal(t, []int{3, 4, 5, 6, 7, 8, 9}, puzzle.Candidates(0, 1).Values())
+	})
+}
+
+func TestConstraintValidate(t *testing.T) {
+	tests := []struct {
+		name                  string
+		constraint            Constraint
+		values                map[Position]int
+		expectedErrorContains string
+	}{
+		{
+			name:                  "diagonal",
+			constraint:            Diagonal{},
+			values:                map[Position]int{{Row: 0, Cell: 8}: 3, {Row: 8, Cell: 0}: 3},
+			expectedErrorContains: "Duplicate check failed, value '3' in diagonal 2",
+		},
+		{
+			name:                  "windoku",
+			constraint:            Windoku{},
+			values:                map[Position]int{{Row: 5, Cell: 1}: 3, {Row: 7, Cell: 3}: 3},
+			expectedErrorContains: "Duplicate check failed, value '3' in window 3",
+		},
+		{
+			name:                  "anti-knight",
+			constraint:            AntiKnight{},
+			values:                map[Position]int{{Row: 0, Cell: 0}: 3, {Row: 1, Cell: 2}: 3},
+			expectedErrorContains: "value '3' is in both r1c1 and r2c3, a knight's move apart",
+		},
+		{
+			name:                  "anti-king",
+			constraint:            AntiKing{},
+			values:                map[Position]int{{Row: 0, Cell: 1}: 3, {Row: 1, Cell: 0}: 3},
+			expectedErrorContains: "value '3' is in both r1c2 and r2c1, a king's move apart",
+		},
+		{
+			name:                  "non-consecutive",
+			constraint:            NonConsecutive{},
+			values:                map[Position]int{{Row: 0, Cell: 0}: 3, {Row: 1, Cell: 0}: 4},
+			expectedErrorContains: "values '3' in r1c1 and '4' in r2c1 are next to each other and consecutive",
+		},
+		{
+			name:                  "non-consecutive with a gap",
+			constraint:            NonConsecutive{},
+			values:                map[Position]int{{Row: 0, Cell: 0}: 3, {Row: 1, Cell: 0}: 5, {Row: 1, Cell: 1}: 4},
+			expectedErrorContains: "values '5' in r2c1 and '4' in r2c2",
+		},
+		{
+			name:                  "knight's move apart isn't a king's",
+			constraint:            AntiKing{},
+			values:                map[Position]int{{Row: 0, Cell: 0}: 3, {Row: 1, Cell: 2}: 3},
+			expectedErrorContains: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			board := emptyBoard(9)
+			for position, value := range tt.values {
+				board[position.Row][position.Cell] = value
+			}
+			puzzle := Puzzle{Board: board, Constraints: []Constraint{tt.constraint}}
+
+			err := tt.constraint.Validate(&puzzle)
+
+			if tt.expectedErrorContains == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, tt.expectedErrorContains)
+				assert.Len(t, err.(Violation).Cells, 2)
+			}
+		})
+	}
+}
+
+func TestParseConstraintLines(t *testing.T) {
+	constraints, err := ParseConstraintLines([]string{
+		"constraints: diagonal",
+		"thermo: r1c1 r2c2 r3c2",
+		"Arrow: r4c4 r4c3 r4c2",
+		"white: r1c1 r1c2",
+		"black: r1c2 r2c2",
+		"x: r3c3 r3c4",
+		"v: r4c1 r3c1",
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []Constraint{
+		Diagonal{},
+		Thermometer{Path: []Position{{0, 0}, {1, 1}, {2, 1}}},
+		Arrow{Circle: Position{3, 3}, Path: []Position{{3, 2}, {3, 1}}},
+		KropkiDot{Pair: [2]Position{{0, 0}, {0, 1}}},
+		KropkiDot{Pair: [2]Position{{0, 1}, {1, 1}}, Black: true},
+		XVPair{Pair: [2]Position{{2, 2}, {2, 3}}, Sum: 10},
+		XVPair{Pair: [2]Position{{3, 0}, {2, 0}}, Sum: 5},
+	}, constraints)
+
+	// each of them writes itself back out the way it was read
+	for _, constraint := range constraints[1:] {
+		reparsed, err := ParseConstraintLines([]string{constraint.(fmt.Stringer).String()})
+		assert.NoError(t, err)
+		assert.Equal(t, []Constraint{constraint}, reparsed)
+	}
+
+	tests := []struct {
+		line                  string
+		expectedErrorContains string
+	}{
+		{line: "killer: r1c1 r1c2", expectedErrorContains: "Unrecognized constraint line 'killer: r1c1 r1c2'"},
+		{line: "thermo r1c1 r1c2", expectedErrorContains: "Unrecognized constraint line"},
+		{line: "thermo: r1c1", expectedErrorContains: "the thermo line 'thermo: r1c1' needs at least 2 cells"},
+		{line: "white: r1c1 r1c2 r1c3", expectedErrorContains: "needs exactly 2 cells"},
+		{line: "x: r1c1 c1r2", expectedErrorContains: "invalid cell 'c1r2', expected r1c1 notation"},
+		{line: "constraints: anti-bishop", expectedErrorContains: "Unrecognized constraint 'anti-bishop'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.line, func(t *testing.T) {
+			_, err := ParseConstraintLines([]string{tt.line})
+
+			assert.ErrorContains(t, err, tt.expectedErrorContains)
+		})
+	}
+}
+
+func TestParsePosition(t *testing.T) {
+	position, err := ParsePosition("r12C3")
+	assert.NoError(t, err)
+	assert.Equal(t, Position{Row: 11, Cell: 2}, position)
+
+	for _, name := range []string{"r0c1", "r1", "1c1", "rxc1", "r1c-1", "r1c"} {
+		_, err := ParsePosition(name)
+		assert.Error(t, err, name)
+	}
+}
+
+func TestCheckConstraints(t *testing.T) {
+	layout := Layout{Shape: Shape{BoxRows: 2, BoxColumns: 2}}
+	tests := []struct {
+		name                  string
+		constraint            Constraint
+		expectedErrorContains string
+	}{
+		{
+			name:       "connected thermometer",
+			constraint: Thermometer{Path: []Position{{0, 0}, {1, 1}, {1, 2}}},
+		},
+		{
+			name:                  "off the board",
+			constraint:            Thermometer{Path: []Position{{0, 3}, {0, 4}}},
+			expectedErrorContains: "the thermometer constraint has cell r1c5, which isn't on the board",
+		},
+		{
+			name:                  "thermometer with a gap",
+			constraint:            Thermometer{Path: []Position{{0, 0}, {0, 2}}},
+			expectedErrorContains: "the thermometer from r1c1 isn't connected, r1c3 doesn't touch r1c1",
+		},
+		{
+			name:                  "thermometer that crosses itself",
+			constraint:            Thermometer{Path: []Position{{0, 0}, {0, 1}, {0, 0}}},
+			expectedErrorContains: "the thermometer from r1c1 goes through r1c1 twice",
+		},
+		{
+			name:                  "thermometer longer than the values",
+			constraint:            Thermometer{Path: []Position{{0, 0}, {0, 1}, {0, 2}, {0, 3}, {1, 3}}},
+			expectedErrorContains: "the thermometer from r1c1 has 5 cells, more than the 4 values that can go up it",
+		},
+		{
+			name:                  "arrow too long for its circle",
+			constraint:            Arrow{Circle: Position{0, 0}, Path: []Position{{1, 0}, {2, 0}, {3, 0}, {3, 1}, {3, 2}}},
+			expectedErrorContains: "the arrow from r1c1 has 5 cells, which add up to more than the 4 its circle can hold",
+		},
+		{
+			name:                  "arrow that doesn't leave its circle",
+			constraint:            Arrow{Circle: Position{0, 0}, Path: []Position{{2, 0}}},
+			expectedErrorContains: "the arrow from r1c1 isn't connected, r3c1 doesn't touch r1c1",
+		},
+		{
+			name:                  "dot between diagonal cells",
+			constraint:            KropkiDot{Pair: [2]Position{{0, 0}, {1, 1}}, Black: true},
+			expectedErrorContains: "the black dot between r1c1 and r2c2 has to be between cells that share a side",
+		},
+		{
+			name:                  "V between the same cell",
+			constraint:            XVPair{Pair: [2]Position{{0, 0}, {0, 0}}, Sum: 5},
+			expectedErrorContains: "the V between r1c1 and r1c1 has to be between cells that share a side",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckConstraints([]Constraint{Diagonal{}, tt.constraint}, layout)
+
+			if tt.expectedErrorContains == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, tt.expectedErrorContains)
+			}
+		})
+	}
+}
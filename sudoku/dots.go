@@ -0,0 +1,164 @@
+package sudoku
+
+import "fmt"
+
+// A kropki dot between two neighbouring cells: a white dot means their values
+// are consecutive, a black dot that one is double the other. Cells without a
+// dot between them can be anything.
+type KropkiDot struct {
+	Pair  [2]Position
+	Black bool
+}
+
+func (KropkiDot) Name() string { return "kropki" }
+
+func (dot KropkiDot) Cells(layout Layout) []Position { return dot.Pair[:] }
+
+func (dot KropkiDot) Prune(puz *Puzzle, row int, cell int, candidates CandidateMask) CandidateMask {
+	other, ok := otherCell(dot.Pair, Position{Row: row, Cell: cell})
+	if !ok {
+		return candidates
+	}
+
+	value := puz.ValueAt(other.Row, other.Cell)
+	switch {
+	case value != 0 && dot.Black:
+		half := pick(value%2 == 0, MaskFor(value/2), 0)
+		return candidates & (MaskFor(value*2) | half)
+	case value != 0:
+		return candidates & (MaskFor(value-1) | MaskFor(value+1))
+	case dot.Black:
+		// only values with a double or a half on the board
+		size := puz.state().layout.Size()
+		var halvable CandidateMask
+		for even := 2; even <= size; even += 2 {
+			halvable |= MaskFor(even)
+		}
+		return candidates & (valueRange(1, size/2) | halvable)
+	default:
+		return candidates
+	}
+}
+
+func (dot KropkiDot) Validate(puz *Puzzle) error {
+	first, second := dot.Pair[0], dot.Pair[1]
+	a, b := puz.ValueAt(first.Row, first.Cell), puz.ValueAt(second.Row, second.Cell)
+	if a == 0 || b == 0 {
+		return nil
+	}
+
+	if dot.Black && a != 2*b && b != 2*a {
+		return Violation{
+			Cells:       dot.Pair[:],
+			Description: fmt.Sprintf("values '%d' in %s and '%d' in %s have a black dot between them, but neither is double the other", a, first, b, second),
+		}
+	}
+	if !dot.Black && a != b+1 && b != a+1 {
+		return Violation{
+			Cells:       dot.Pair[:],
+			Description: fmt.Sprintf("values '%d' in %s and '%d' in %s have a white dot between them, but aren't consecutive", a, first, b, second),
+		}
+	}
+
+	return nil
+}
+
+func (dot KropkiDot) checkCells(size int) error {
+	return checkNeighbors(dot.Pair, "the "+dot.color()+" dot")
+}
+
+func (dot KropkiDot) color() string {
+	return pick(dot.Black, "black", "white")
+}
+
+func (dot KropkiDot) draw(marks boardMarks) {
+	marks.mark(dot.Pair[0], dot.Pair[1], pick(dot.Black, "●", "○"))
+}
+
+func (dot KropkiDot) String() string {
+	return dot.color() + ": " + positionList(dot.Pair[:])
+}
+
+// An X or a V between two neighbouring cells: their values add up to 10 or to
+// 5.
+type XVPair struct {
+	Pair [2]Position
+	Sum  int
+}
+
+func (XVPair) Name() string { return "XV" }
+
+func (pair XVPair) Cells(layout Layout) []Position { return pair.Pair[:] }
+
+func (pair XVPair) Prune(puz *Puzzle, row int, cell int, candidates CandidateMask) CandidateMask {
+	other, ok := otherCell(pair.Pair, Position{Row: row, Cell: cell})
+	if !ok {
+		return candidates
+	}
+
+	value := puz.ValueAt(other.Row, other.Cell)
+	if value != 0 {
+		return candidates & valueRange(pair.Sum-value, pair.Sum-value)
+	}
+
+	// the other cell needs a value of its own, and the two of them are in the
+	// same row or column so they can't both be half the sum
+	size := puz.state().layout.Size()
+	possible := valueRange(pair.Sum-size, pair.Sum-1) & valueRange(1, size)
+	if pair.Sum%2 == 0 {
+		possible &^= MaskFor(pair.Sum / 2)
+	}
+
+	return candidates & possible
+}
+
+func (pair XVPair) Validate(puz *Puzzle) error {
+	first, second := pair.Pair[0], pair.Pair[1]
+	a, b := puz.ValueAt(first.Row, first.Cell), puz.ValueAt(second.Row, second.Cell)
+	if a == 0 || b == 0 || a+b == pair.Sum {
+		return nil
+	}
+
+	return Violation{
+		Cells:       pair.Pair[:],
+		Description: fmt.Sprintf("Sum check failed, values '%d' in %s and '%d' in %s add up to %d, but the %s between them needs %d", a, first, b, second, a+b, pair.letter(), pair.Sum),
+	}
+}
+
+func (pair XVPair) checkCells(size int) error {
+	return checkNeighbors(pair.Pair, "the "+pair.letter())
+}
+
+func (pair XVPair) letter() string {
+	return pick(pair.Sum == 10, "X", "V")
+}
+
+func (pair XVPair) draw(marks boardMarks) {
+	marks.mark(pair.Pair[0], pair.Pair[1], pair.letter())
+}
+
+func (pair XVPair) String() string {
+	return fmt.Sprintf("%s: %s", pick(pair.Sum == 10, "x", "v"), positionList(pair.Pair[:]))
+}
+
+// The other cell of the pair, if the given one is in it.
+func otherCell(pair [2]Position, position Position) (Position, bool) {
+	switch position {
+	case pair[0]:
+		return pair[1], true
+	case pair[1]:
+		return pair[0], true
+	default:
+		return Position{}, false
+	}
+}
+
+// A mark between two cells only makes sense when they share a side.
+func checkNeighbors(pair [2]Position, name string) error {
+	first, second := pair[0], pair[1]
+	if abs(first.Row-second.Row)+abs(first.Cell-second.Cell) != 1 {
+		return fmt.Errorf("%s between %s and %s has to be between cells that share a side", name, first, second)
+	}
+
+	return nil
+}
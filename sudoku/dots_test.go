@@ -0,0 +1,49 @@
+package sudoku
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKropkiDot(t *testing.T) {
+	white := KropkiDot{Pair: [2]Position{{0, 0}, {0, 1}}}
+	black := KropkiDot{Pair: [2]Position{{1, 0}, {2, 0}}, Black: true}
+	puzzle := Puzzle{Board: emptyBoard(9), Constraints: []Constraint{white, black}}
+
+	// 5 and 7 have neither a half nor a double that fits
+	assert.Equal(t, []int{1, 2, 3, 4, 6, 8}, puzzle.Candidates(1, 0).Values())
+	assert.Equal(t, []int{1, 2, 3, 4, 5, 6, 7, 8, 9}, puzzle.Candidates(0, 0).Values())
+
+	puzzle.PlaceValue(0, 0, 5)
+	assert.Equal(t, []int{4, 6}, puzzle.Candidates(0, 1).Values())
+
+	puzzle.PlaceValue(1, 0, 4)
+	assert.Equal(t, []int{2, 8}, puzzle.Candidates(2, 0).Values())
+
+	puzzle.PlaceValue(2, 0, 3)
+	assert.ErrorContains(t, black.Validate(&puzzle), "values '4' in r2c1 and '3' in r3c1 have a black dot between them, but neither is double the other")
+	assert.NoError(t, white.Validate(&puzzle))
+
+	puzzle.PlaceValue(0, 1, 7)
+	assert.ErrorContains(t, white.Validate(&puzzle), "values '5' in r1c1 and '7' in r1c2 have a white dot between them, but aren't consecutive")
+}
+
+func TestXVPair(t *testing.T) {
+	x := XVPair{Pair: [2]Position{{0, 0}, {0, 1}}, Sum: 10}
+	v := XVPair{Pair: [2]Position{{1, 0}, {2, 0}}, Sum: 5}
+	puzzle := Puzzle{Board: emptyBoard(9), Constraints: []Constraint{x, v}}
+
+	// the two cells can't both be a 5
+	assert.Equal(t, []int{1, 2, 3, 4, 6, 7, 8, 9}, puzzle.Candidates(0, 0).Values())
+	assert.Equal(t, []int{1, 2, 3, 4}, puzzle.Candidates(1, 0).Values())
+
+	puzzle.PlaceValue(0, 0, 3)
+	assert.Equal(t, []int{7}, puzzle.Candidates(0, 1).Values())
+	assert.Equal(t, []int{1, 2, 4}, puzzle.Candidates(1, 0).Values())
+
+	puzzle.PlaceValue(1, 0, 4)
+	puzzle.PlaceValue(2, 0, 2)
+	assert.ErrorContains(t, v.Validate(&puzzle), "Sum check failed, values '4' in r2c1 and '2' in r3c1 add up to 6, but the V between them needs 5")
+	assert.NoError(t, x.Validate(&puzzle))
+}
@@ -0,0 +1,249 @@
+package sudoku
+
+import (
+	"fmt"
+	"strings"
+)
+
+var basicFishTechniques = map[int]Technique{2: XWing, 3: Swordfish, 4: Jellyfish}
+var finnedFishTechniques = map[int]Technique{2: FinnedXWing, 3: FinnedSwordfish, 4: FinnedJellyfish}
+var sashimiFishTechniques = map[int]Technique{2: SashimiXWing, 3: SashimiSwordfish, 4: SashimiJellyfish}
+
+// One way of looking for fish: the base lines are the rows and the cover
+// lines the columns, or the other way around.
+type fishOrientation struct {
+	base  string
+	cover string
+	// the cell at the crossing of a base line and a cover line
+	position func(base int, cover int) Position
+}
+
+var fishOrientations = []fishOrientation{
+	{base: "rows", cover: "columns", position: func(base int, cover int) Position { return Position{Row: base, Cell: cover} }},
+	{base: "columns", cover: "rows", position: func(base int, cover int) Position { return Position{Row: cover, Cell: base} }},
+}
+
+// The cover lines of a base line that still have value as a candidate.
+func (grid *CandidateGrid) fishCovers(orientation fishOrientation, base int, value int) []int {
+	covers := []int{}
+	for cover := range grid.Layout.Size() {
+		if grid.has(orientation.position(base, cover), value) {
+			covers = append(covers, cover)
+		}
+	}
+
+	return covers
+}
+
+// When a value's candidates in n rows all fall in the same n columns, each of
+// those columns gets its value from one of the rows, so it can't go anywhere
+// else in the columns. Works the same with rows and columns swapped.
+func findBasicFish(grid *CandidateGrid, size int) (Step, bool) {
+	for _, orientation := range fishOrientations {
+		for value := 1; value <= grid.Layout.Size(); value++ {
+			covers := make([][]int, grid.Layout.Size())
+			bases := []int{}
+			for base := range grid.Layout.Size() {
+				covers[base] = grid.fishCovers(orientation, base, value)
+				count := len(covers[base])
+				if count >= 2 && count <= size {
+					bases = append(bases, base)
+				}
+			}
+
+			for _, combination := range combinations(len(bases), size) {
+				baseLines := []int{}
+				var coverMask CandidateMask
+				for _, i := range combination {
+					baseLines = append(baseLines, bases[i])
+					for _, cover := range covers[bases[i]] {
+						coverMask |= MaskFor(cover + 1)
+					}
+				}
+				if coverMask.Count() != size {
+					continue
+				}
+
+				coverLines := []int{}
+				for _, cover := range coverMask.Values() {
+					coverLines = append(coverLines, cover-1)
+				}
+
+				eliminations := fishEliminations(grid, orientation, value, baseLines, coverLines, nil)
+				if len(eliminations) > 0 {
+					return Step{
+						Technique:    basicFishTechniques[size],
+						Cells:        fishCells(grid, orientation, value, baseLines, coverLines),
+						Values:       []int{value},
+						Eliminations: eliminations,
+						Description:  fmt.Sprintf("%d in %s is locked to %s", value, lineNames(orientation.base, baseLines), lineNames(orientation.cover, coverLines)),
+					}, true
+				}
+			}
+		}
+	}
+
+	return Step{}, false
+}
+
+// A fish with extra candidates, the fins, in one of its base lines. Either the
+// fish is there or one of the fins is true, so only the cells of the cover
+// lines that also see every fin can lose the value. It's a sashimi fish when
+// some base line has a single candidate left once the fins are set aside.
+func findFinnedFish(grid *CandidateGrid, size int, sashimi bool) (Step, bool) {
+	units := grid.Layout.Units()
+	// the fins all have to fit in one sector, which can't hold more cells of
+	// a line than its longest side
+	maxFins := grid.Layout.longestSectorLine()
+
+	for _, orientation := range fishOrientations {
+		for value := 1; value <= grid.Layout.Size(); value++ {
+			covers := make([][]int, grid.Layout.Size())
+			bases := []int{}
+			for base := range grid.Layout.Size() {
+				covers[base] = grid.fishCovers(orientation, base, value)
+				count := len(covers[base])
+				if count > 0 && count <= size+maxFins {
+					bases = append(bases, base)
+				}
+			}
+
+			for _, combination := range combinations(len(bases), size) {
+				baseLines := []int{}
+				var coverMask CandidateMask
+				for _, i := range combination {
+					baseLines = append(baseLines, bases[i])
+					for _, cover := range covers[bases[i]] {
+						coverMask |= MaskFor(cover + 1)
+					}
+				}
+				if coverMask.Count() <= size {
+					// no room for a fin, this is a basic fish at best
+					continue
+				}
+				if coverMask.Count() > size+maxFins {
+					// the lines left over for the fins don't fit in one box
+					continue
+				}
+
+				candidateCovers := []int{}
+				for _, cover := range coverMask.Values() {
+					candidateCovers = append(candidateCovers, cover-1)
+				}
+
+				for _, coverCombination := range combinations(len(candidateCovers), size) {
+					coverLines := []int{}
+					for _, i := range coverCombination {
+						coverLines = append(coverLines, candidateCovers[i])
+					}
+
+					fins := []Position{}
+					isSashimi := false
+					isFish := true
+					for _, base := range baseLines {
+						inCover := 0
+						for _, cover := range covers[base] {
+							if containsInt(coverLines, cover) {
+								inCover++
+							} else {
+								fins = append(fins, orientation.position(base, cover))
+							}
+						}
+						if inCover == 0 {
+							isFish = false
+						}
+						if inCover < 2 {
+							isSashimi = true
+						}
+					}
+					if !isFish || isSashimi != sashimi {
+						continue
+					}
+
+					finBox := grid.Layout.SectorIndexFor(fins[0].Row, fins[0].Cell)
+					if !containsAll(units[2*grid.Layout.Size()+finBox], fins) {
+						continue
+					}
+
+					eliminations := fishEliminations(grid, orientation, value, baseLines, coverLines, units[2*grid.Layout.Size()+finBox])
+					if len(eliminations) > 0 {
+						technique := finnedFishTechniques[size]
+						if sashimi {
+							technique = sashimiFishTechniques[size]
+						}
+
+						return Step{
+							Technique:    technique,
+							Cells:        append(fishCells(grid, orientation, value, baseLines, coverLines), fins...),
+							Values:       []int{value},
+							Eliminations: eliminations,
+							Description:  fmt.Sprintf("%d in %s is locked to %s, with fins at %s", value, lineNames(orientation.base, baseLines), lineNames(orientation.cover, coverLines), cellNames(fins)),
+						}, true
+					}
+				}
+			}
+		}
+	}
+
+	return Step{}, false
+}
+
+// The value can't go in any cell of the cover lines outside of the base
+// lines, and for a finned fish, the cell also has to be in the fin's box.
+func fishEliminations(grid *CandidateGrid, orientation fishOrientation, value int, baseLines []int, coverLines []int, finBox []Position) []Candidate {
+	eliminations := []Candidate{}
+	for other := range grid.Layout.Size() {
+		if containsInt(baseLines, other) {
+			continue
+		}
+
+		for _, cover := range coverLines {
+			position := orientation.position(other, cover)
+			if !grid.has(position, value) {
+				continue
+			}
+			if finBox != nil && !containsPosition(finBox, position) {
+				continue
+			}
+
+			eliminations = append(eliminations, Candidate{Row: position.Row, Cell: position.Cell, Value: value})
+		}
+	}
+
+	return eliminations
+}
+
+// The cells where the base lines and cover lines cross that still have value
+// as a candidate.
+func fishCells(grid *CandidateGrid, orientation fishOrientation, value int, baseLines []int, coverLines []int) []Position {
+	cells := []Position{}
+	for _, base := range baseLines {
+		for _, cover := range coverLines {
+			position := orientation.position(base, cover)
+			if grid.has(position, value) {
+				cells = append(cells, position)
+			}
+		}
+	}
+
+	return cells
+}
+
+func lineNames(kind string, lines []int) string {
+	names := []string{}
+	for _, line := range lines {
+		names = append(names, fmt.Sprintf("%d", line+1))
+	}
+
+	return fmt.Sprintf("%s %s", kind, strings.Join(names, ","))
+}
+
+func containsInt(values []int, value int) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,116 @@
+package sudoku
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Leave value as a candidate in only the given cells of each row.
+func restrictRows(grid *CandidateGrid, value int, cellsByRow map[int][]int) {
+	for row, cells := range cellsByRow {
+		for cell := range 9 {
+			if !containsInt(cells, cell) {
+				grid.Candidates[row][cell] &^= MaskFor(value)
+			}
+		}
+	}
+}
+
+func TestFish(t *testing.T) {
+	t.Run("x-wing", func(t *testing.T) {
+		grid := openCandidateGrid()
+		restrictRows(&grid, 4, map[int][]int{0: {1, 6}, 4: {1, 6}})
+
+		step, found := findBasicFish(&grid, 2)
+
+		assert.True(t, found)
+		assert.Equal(t, XWing, step.Technique)
+		assert.Len(t, step.Eliminations, 14)
+		assert.Equal(t, []int{4}, eliminatedFrom(step, 8, 6))
+		assert.Empty(t, eliminatedFrom(step, 4, 6))
+		assert.Equal(t, "4 in rows 1,5 is locked to columns 2,7", step.Description)
+	})
+
+	t.Run("swordfish", func(t *testing.T) {
+		grid := openCandidateGrid()
+		restrictRows(&grid, 4, map[int][]int{0: {0, 4}, 3: {4, 8}, 6: {0, 8}})
+
+		_, found := findBasicFish(&grid, 2)
+		assert.False(t, found)
+
+		step, found := findBasicFish(&grid, 3)
+
+		assert.True(t, found)
+		assert.Equal(t, Swordfish, step.Technique)
+		assert.Len(t, step.Eliminations, 18)
+		assert.Len(t, step.Cells, 6)
+	})
+
+	t.Run("jellyfish", func(t *testing.T) {
+		grid := openCandidateGrid()
+		restrictRows(&grid, 9, map[int][]int{1: {0, 2}, 3: {2, 5}, 5: {5, 7}, 7: {0, 7}})
+
+		step, found := findBasicFish(&grid, 4)
+
+		assert.True(t, found)
+		assert.Equal(t, Jellyfish, step.Technique)
+		assert.Len(t, step.Eliminations, 20)
+	})
+
+	t.Run("looks down columns too", func(t *testing.T) {
+		grid := openCandidateGrid()
+		for row := range 9 {
+			if row != 2 && row != 7 {
+				grid.Candidates[row][3] &^= MaskFor(6)
+				grid.Candidates[row][5] &^= MaskFor(6)
+			}
+		}
+
+		step, found := findBasicFish(&grid, 2)
+
+		assert.True(t, found)
+		assert.Equal(t, "6 in columns 4,6 is locked to rows 3,8", step.Description)
+	})
+
+	t.Run("finned x-wing", func(t *testing.T) {
+		grid := openCandidateGrid()
+		restrictRows(&grid, 4, map[int][]int{0: {1, 6}, 4: {1, 6, 7}})
+
+		_, found := findBasicFish(&grid, 2)
+		assert.False(t, found)
+
+		step, found := findFinnedFish(&grid, 2, false)
+
+		assert.True(t, found)
+		assert.Equal(t, FinnedXWing, step.Technique)
+		assert.Equal(t, []Candidate{{Row: 3, Cell: 6, Value: 4}, {Row: 5, Cell: 6, Value: 4}}, step.Eliminations)
+		assert.Equal(t, "4 in rows 1,5 is locked to columns 2,7, with fins at r5c8", step.Description)
+
+		_, found = findFinnedFish(&grid, 2, true)
+		assert.False(t, found)
+	})
+
+	t.Run("sashimi x-wing", func(t *testing.T) {
+		grid := openCandidateGrid()
+		restrictRows(&grid, 4, map[int][]int{0: {1, 6}, 4: {1, 7, 8}})
+
+		_, found := findFinnedFish(&grid, 2, false)
+		assert.False(t, found)
+
+		step, found := findFinnedFish(&grid, 2, true)
+
+		assert.True(t, found)
+		assert.Equal(t, SashimiXWing, step.Technique)
+		assert.Equal(t, []Candidate{{Row: 3, Cell: 6, Value: 4}, {Row: 5, Cell: 6, Value: 4}}, step.Eliminations)
+	})
+
+	t.Run("fins spread over two boxes", func(t *testing.T) {
+		grid := openCandidateGrid()
+		restrictRows(&grid, 4, map[int][]int{0: {1, 6}, 4: {1, 4, 6, 7}})
+
+		_, found := findFinnedFish(&grid, 2, false)
+
+		assert.False(t, found)
+	})
+}
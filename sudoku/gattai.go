@@ -0,0 +1,265 @@
+package sudoku
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// A gattai puzzle is several grids that overlap, like the five 9x9 grids of a
+// Samurai Sudoku, four of which share a box with the one in the middle. Each
+// grid follows the usual rules on its own, and a cell that grids share holds
+// the same value in all of them.
+type Gattai struct {
+	Grids []Puzzle
+	// where each grid's top left cell is on the combined board
+	Offsets []Position
+	// the placements on the combined board, each of which is made in every
+	// grid that has the cell
+	Solution []Placement
+}
+
+// Layouts that have a name, by the top left cell of each grid.
+var GattaiLayouts = map[string][]Position{
+	"samurai": {{0, 0}, {0, 12}, {6, 6}, {12, 0}, {12, 12}},
+	"twodoku": {{0, 0}, {6, 6}},
+}
+
+// Parse a gattai layout, either one of GattaiLayouts by name or the top left
+// cell of each grid in r1c1 notation, e.g. `r1c1 r7c7`.
+func ParseGattaiLayout(value string) ([]Position, error) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if offsets, ok := GattaiLayouts[value]; ok {
+		return offsets, nil
+	}
+
+	offsets := []Position{}
+	for _, field := range strings.Fields(value) {
+		position, err := ParsePosition(field)
+		if err != nil {
+			return nil, fmt.Errorf("Unrecognized gattai layout '%s', expected one of %v or the top left cell of each grid", value, slices.Sorted(maps.Keys(GattaiLayouts)))
+		}
+		offsets = append(offsets, position)
+	}
+
+	return offsets, nil
+}
+
+// Split the combined board into a grid of the given shape at each of the
+// offsets. Rows can stop short after the last grid that has cells in them, and
+// the cells outside every grid have to be empty.
+func NewGattai(board [][]int, shape Shape, offsets []Position) (Gattai, error) {
+	if len(offsets) < 2 {
+		return Gattai{}, fmt.Errorf("a gattai puzzle needs at least 2 grids, got %d", len(offsets))
+	}
+
+	size := shape.Size()
+	gattai := Gattai{Offsets: offsets}
+	for i, offset := range offsets {
+		// grids can only share whole boxes
+		if offset.Row%shape.BoxRows != 0 || offset.Cell%shape.BoxColumns != 0 {
+			return Gattai{}, fmt.Errorf("grid %d starts at %s, which isn't the corner of a %s box", i+1, offset, shape)
+		}
+
+		grid := make([][]int, size)
+		for row := range size {
+			if offset.Row+row >= len(board) || offset.Cell+size > len(board[offset.Row+row]) {
+				return Gattai{}, fmt.Errorf("grid %d from %s runs off the board", i+1, offset)
+			}
+			grid[row] = slices.Clone(board[offset.Row+row][offset.Cell : offset.Cell+size])
+		}
+		gattai.Grids = append(gattai.Grids, Puzzle{Board: grid, Shape: shape})
+	}
+
+	for row := range board {
+		for cell, value := range board[row] {
+			if value != 0 && !gattai.Contains(row, cell) {
+				return Gattai{}, fmt.Errorf("%s has a '%s' but isn't in any of the grids", cellName(row, cell), Symbol(value))
+			}
+		}
+	}
+
+	return gattai, nil
+}
+
+// The rows and columns of the combined board.
+func (gattai *Gattai) Dimensions() (int, int) {
+	rows, columns := 0, 0
+	for i, offset := range gattai.Offsets {
+		size := gattai.Grids[i].Size()
+		rows = max(rows, offset.Row+size)
+		columns = max(columns, offset.Cell+size)
+	}
+
+	return rows, columns
+}
+
+// The cell of the combined board in the given grid's own rows and columns,
+// if the grid has it.
+func (gattai *Gattai) gridCell(grid int, row int, cell int) (Position, bool) {
+	offset := gattai.Offsets[grid]
+	size := gattai.Grids[grid].Size()
+	position := Position{Row: row - offset.Row, Cell: cell - offset.Cell}
+	if position.Row < 0 || position.Row >= size || position.Cell < 0 || position.Cell >= size {
+		return Position{}, false
+	}
+
+	return position, true
+}
+
+// Whether any of the grids has the cell of the combined board.
+func (gattai *Gattai) Contains(row int, cell int) bool {
+	for i := range gattai.Grids {
+		if _, ok := gattai.gridCell(i, row, cell); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// The value in a cell of the combined board, 0 for an empty one or one
+// outside every grid.
+func (gattai *Gattai) ValueAt(row int, cell int) int {
+	for i := range gattai.Grids {
+		if position, ok := gattai.gridCell(i, row, cell); ok {
+			return gattai.Grids[i].ValueAt(position.Row, position.Cell)
+		}
+	}
+
+	return 0
+}
+
+// The values a cell of the combined board can take, the ones that every grid
+// that has it allows.
+func (gattai *Gattai) Candidates(row int, cell int) CandidateMask {
+	candidates := gattai.Grids[0].Layout().AllValuesMask()
+	for i := range gattai.Grids {
+		if position, ok := gattai.gridCell(i, row, cell); ok {
+			candidates &= gattai.Grids[i].Candidates(position.Row, position.Cell)
+		}
+	}
+
+	return candidates
+}
+
+func (gattai *Gattai) PlaceValue(row int, cell int, value int) {
+	for i := range gattai.Grids {
+		if position, ok := gattai.gridCell(i, row, cell); ok {
+			gattai.Grids[i].PlaceValue(position.Row, position.Cell, value)
+		}
+	}
+
+	gattai.Solution = append(gattai.Solution, Placement{Row: row, Cell: cell, Value: value})
+}
+
+func (gattai *Gattai) UndoLastPlacement() {
+	if len(gattai.Solution) == 0 {
+		return
+	}
+
+	last := gattai.Solution[len(gattai.Solution)-1]
+	gattai.Solution = gattai.Solution[:len(gattai.Solution)-1]
+
+	for i := range gattai.Grids {
+		if _, ok := gattai.gridCell(i, last.Row, last.Cell); ok {
+			gattai.Grids[i].UndoLastPlacement()
+		}
+	}
+}
+
+// The rows, columns, and sectors of every grid, in the combined board's rows
+// and columns. A box that grids share is a unit of each of them.
+func (gattai *Gattai) Units() [][]Position {
+	units := [][]Position{}
+	for i := range gattai.Grids {
+		offset := gattai.Offsets[i]
+		for _, unit := range gattai.Grids[i].Layout().Units() {
+			shifted := make([]Position, len(unit))
+			for j, position := range unit {
+				shifted[j] = Position{Row: position.Row + offset.Row, Cell: position.Cell + offset.Cell}
+			}
+			units = append(units, shifted)
+		}
+	}
+
+	return units
+}
+
+// The empty cells of the combined board, counting a cell that grids share
+// once.
+func (gattai *Gattai) EmptyCellCount() int {
+	rows, columns := gattai.Dimensions()
+	count := 0
+	for row := range rows {
+		for cell := range columns {
+			if gattai.Contains(row, cell) && gattai.ValueAt(row, cell) == 0 {
+				count++
+			}
+		}
+	}
+
+	return count
+}
+
+// A deep copy of the puzzle, see Puzzle.Clone.
+func (gattai *Gattai) Clone() Gattai {
+	grids := make([]Puzzle, len(gattai.Grids))
+	for i := range gattai.Grids {
+		grids[i] = gattai.Grids[i].Clone()
+	}
+
+	return Gattai{Grids: grids, Offsets: gattai.Offsets, Solution: slices.Clone(gattai.Solution)}
+}
+
+// Every cell of the combined board as a symbol, 0 for an empty one and a
+// space for one outside every grid, with a line per row.
+func (gattai *Gattai) String() string {
+	rows, columns := gattai.Dimensions()
+
+	lines := make([]string, rows)
+	for row := range rows {
+		var builder strings.Builder
+		for cell := range columns {
+			if !gattai.Contains(row, cell) {
+				builder.WriteString(" ")
+				continue
+			}
+			builder.WriteString(Symbol(gattai.ValueAt(row, cell)))
+		}
+		lines[row] = strings.TrimRight(builder.String(), " ")
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// The combined board with the borders of every box, and the cells outside
+// every grid left blank. Grids only share whole boxes, so the boxes of the
+// combined board line up with the ones of every grid.
+func (gattai *Gattai) PrettyString() string {
+	rows, columns := gattai.Dimensions()
+	shape := gattai.Grids[0].GridShape()
+
+	box := func(position Position) Position {
+		if !gattai.Contains(position.Row, position.Cell) {
+			return Position{Row: -1, Cell: -1}
+		}
+		return Position{Row: position.Row / shape.BoxRows, Cell: position.Cell / shape.BoxColumns}
+	}
+	edge := func(a Position, b Position) outlineEdge {
+		return pick(box(a) != box(b), solidEdge, noEdge)
+	}
+	cellText := func(row int, cell int) string {
+		switch {
+		case !gattai.Contains(row, cell):
+			return "   "
+		case gattai.ValueAt(row, cell) == 0:
+			return " _ "
+		default:
+			return fmt.Sprintf(" %s ", Symbol(gattai.ValueAt(row, cell)))
+		}
+	}
+
+	return outlineString(rows, columns, 3, edge, cellText, boardMarks{})
+}
@@ -0,0 +1,151 @@
+package sudoku
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// two 4x4 grids that share the box at the bottom right of the first one
+//
+//	12..
+//	34..
+//	..2..1
+//	...3..
+//	  ....
+//	  ....
+var twodokuOffsets = []Position{{0, 0}, {2, 2}}
+
+func twodokuBoard() [][]int {
+	return [][]int{
+		{1, 2, 0, 0},
+		{3, 4, 0, 0},
+		{0, 0, 2, 0, 0, 1},
+		{0, 0, 0, 3, 0, 0},
+		{0, 0, 0, 0, 0, 0},
+		{0, 0, 0, 0, 0, 0},
+	}
+}
+
+func TestParseGattaiLayout(t *testing.T) {
+	offsets, err := ParseGattaiLayout(" Samurai ")
+	assert.NoError(t, err)
+	assert.Equal(t, GattaiLayouts["samurai"], offsets)
+
+	offsets, err = ParseGattaiLayout("r1c1 r7c7")
+	assert.NoError(t, err)
+	assert.Equal(t, []Position{{0, 0}, {6, 6}}, offsets)
+
+	_, err = ParseGattaiLayout("triple")
+	assert.ErrorContains(t, err, "Unrecognized gattai layout 'triple', expected one of [samurai twodoku]")
+}
+
+func TestNewGattai(t *testing.T) {
+	shape := Shape{BoxRows: 2, BoxColumns: 2}
+
+	gattai, err := NewGattai(twodokuBoard(), shape, twodokuOffsets)
+	assert.NoError(t, err)
+	assert.Len(t, gattai.Grids, 2)
+	assert.Equal(t, [][]int{{2, 0, 0, 1}, {0, 3, 0, 0}, {0, 0, 0, 0}, {0, 0, 0, 0}}, gattai.Grids[1].Board)
+
+	rows, columns := gattai.Dimensions()
+	assert.Equal(t, 6, rows)
+	assert.Equal(t, 6, columns)
+	assert.True(t, gattai.Contains(2, 2))
+	assert.False(t, gattai.Contains(0, 4))
+	assert.False(t, gattai.Contains(4, 1))
+	// the shared box counts once
+	assert.Equal(t, 16+16-4-7, gattai.EmptyCellCount())
+	assert.Len(t, gattai.Units(), 2*3*4)
+
+	tests := []struct {
+		name                  string
+		board                 [][]int
+		offsets               []Position
+		expectedErrorContains string
+	}{
+		{
+			name:                  "a single grid",
+			board:                 twodokuBoard(),
+			offsets:               []Position{{0, 0}},
+			expectedErrorContains: "a gattai puzzle needs at least 2 grids, got 1",
+		},
+		{
+			name:                  "grids that share part of a box",
+			board:                 twodokuBoard(),
+			offsets:               []Position{{0, 0}, {1, 2}},
+			expectedErrorContains: "grid 2 starts at r2c3, which isn't the corner of a 2x2 box",
+		},
+		{
+			name:                  "grid off the board",
+			board:                 twodokuBoard()[:5],
+			offsets:               twodokuOffsets,
+			expectedErrorContains: "grid 2 from r3c3 runs off the board",
+		},
+		{
+			name:                  "value outside the grids",
+			board:                 append(twodokuBoard()[:4], []int{3, 0, 0, 0, 0, 0}, []int{0, 0, 0, 0, 0, 0}),
+			offsets:               twodokuOffsets,
+			expectedErrorContains: "r5c1 has a '3' but isn't in any of the grids",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewGattai(tt.board, shape, tt.offsets)
+
+			assert.ErrorContains(t, err, tt.expectedErrorContains)
+		})
+	}
+}
+
+func TestGattaiSharedCells(t *testing.T) {
+	gattai, _ := NewGattai(twodokuBoard(), Shape{BoxRows: 2, BoxColumns: 2}, twodokuOffsets)
+
+	// r3c1 is only in the first grid, whose row and column leave it a 4, and
+	// r3c5 is only in the second grid, whose row leaves it a 3 or a 4
+	assert.Equal(t, []int{4}, gattai.Candidates(2, 0).Values())
+	assert.Equal(t, []int{3, 4}, gattai.Candidates(2, 4).Values())
+
+	// r3c4 is in both grids, the first one leaves it a 1 or a 4 and the
+	// second one a 4
+	assert.Equal(t, []int{4}, gattai.Candidates(2, 3).Values())
+
+	clone := gattai.Clone()
+	clone.PlaceValue(2, 3, 4)
+	assert.Equal(t, 4, clone.ValueAt(2, 3))
+	assert.Equal(t, 4, clone.Grids[0].ValueAt(2, 3))
+	assert.Equal(t, 4, clone.Grids[1].ValueAt(0, 1))
+	assert.Equal(t, 0, gattai.ValueAt(2, 3))
+	assert.False(t, clone.Candidates(0, 3).Has(4))
+	assert.False(t, clone.Candidates(5, 3).Has(4))
+
+	clone.UndoLastPlacement()
+	assert.Equal(t, 0, clone.Grids[1].ValueAt(0, 1))
+	assert.True(t, clone.Candidates(0, 3).Has(4))
+	assert.True(t, clone.Candidates(5, 3).Has(4))
+}
+
+func TestGattaiStrings(t *testing.T) {
+	gattai, _ := NewGattai(twodokuBoard(), Shape{BoxRows: 2, BoxColumns: 2}, twodokuOffsets)
+
+	assert.Equal(t, "1200\n3400\n002001\n000300\n  0000\n  0000", gattai.String())
+
+	expected := strings.Join([]string{
+		"╔═══════╤═══════╤═══════╗",
+		"║ 1   2 │ _   _ │       ║",
+		"║       │       │       ║",
+		"║ 3   4 │ _   _ │       ║",
+		"╟───────┼───────┼───────╢",
+		"║ _   _ │ 2   _ │ _   1 ║",
+		"║       │       │       ║",
+		"║ _   _ │ _   3 │ _   _ ║",
+		"╟───────┼───────┼───────╢",
+		"║       │ _   _ │ _   _ ║",
+		"║       │       │       ║",
+		"║       │ _   _ │ _   _ ║",
+		"╚═══════╧═══════╧═══════╝",
+	}, "\n")
+	assert.Equal(t, expected, gattai.PrettyString())
+}
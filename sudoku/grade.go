@@ -0,0 +1,174 @@
+package sudoku
+
+import (
+	"fmt"
+)
+
+// A coarse label for how hard a puzzle is.
+type Difficulty string
+
+const (
+	// hidden singles are enough
+	Easy Difficulty = "easy"
+	// needs naked singles or locked candidates
+	Medium Difficulty = "medium"
+	// needs pairs, triples, or fish up to a swordfish
+	Hard Difficulty = "hard"
+	// needs wings, coloring, quads, or jellyfish
+	Expert Difficulty = "expert"
+	// needs chains, or can't be solved by logic alone
+	Diabolical Difficulty = "diabolical"
+)
+
+var Difficulties = []Difficulty{Easy, Medium, Hard, Expert, Diabolical}
+
+func ParseDifficulty(value string) (Difficulty, error) {
+	for _, difficulty := range Difficulties {
+		if string(difficulty) == value {
+			return difficulty, nil
+		}
+	}
+
+	return "", fmt.Errorf("Unrecognized difficulty '%s', expected one of %v", value, Difficulties)
+}
+
+// How hard each technique is to spot, on a scale modeled after Sudoku
+// Explainer's. Techniques that Sudoku Explainer doesn't have are placed next
+// to the ones they're most like.
+var TechniqueRatings = map[Technique]float64{
+	HiddenSingle:     1.5,
+	NakedSingle:      2.3,
+	PointingPair:     2.6,
+	BoxLineReduction: 2.8,
+	NakedPair:        3.0,
+	XWing:            3.2,
+	HiddenPair:       3.4,
+	FinnedXWing:      3.4,
+	SashimiXWing:     3.5,
+	NakedTriple:      3.6,
+	Swordfish:        3.8,
+	HiddenTriple:     4.0,
+	FinnedSwordfish:  4.0,
+	SashimiSwordfish: 4.1,
+	XYWing:           4.2,
+	XYZWing:          4.4,
+	WWing:            4.4,
+	SimpleColoring:   4.5,
+	MultiColoring:    4.8,
+	NakedQuad:        5.0,
+	Jellyfish:        5.2,
+	HiddenQuad:       5.4,
+	FinnedJellyfish:  5.4,
+	SashimiJellyfish: 5.5,
+	XCycle:           6.5,
+	XChain:           6.6,
+	XYChain:          6.6,
+	AIC:              7.0,
+}
+
+// The rating of a puzzle that the logic solver can't finish, since it's
+// harder than anything the solver knows.
+const BeyondLogicRating = 10.0
+
+// The lowest rating for each difficulty, from hardest to easiest.
+var difficultyThresholds = []struct {
+	rating     float64
+	difficulty Difficulty
+}{
+	{rating: 6.0, difficulty: Diabolical},
+	{rating: 4.2, difficulty: Expert},
+	{rating: 3.0, difficulty: Hard},
+	{rating: 2.0, difficulty: Medium},
+	{rating: 0, difficulty: Easy},
+}
+
+func DifficultyFor(rating float64) Difficulty {
+	for _, threshold := range difficultyThresholds {
+		if rating >= threshold.rating {
+			return threshold.difficulty
+		}
+	}
+
+	return Easy
+}
+
+// The lowest rating of the bucket above the one the rating is in, false for
+// a rating in the hardest bucket.
+func nextThreshold(rating float64) (float64, bool) {
+	next, found := 0.0, false
+	for _, threshold := range difficultyThresholds {
+		if threshold.rating <= rating {
+			break
+		}
+		next, found = threshold.rating, true
+	}
+
+	return next, found
+}
+
+type Grade struct {
+	// The rating of the hardest step, plus 0.1 for each further time the
+	// hardest technique is needed, up to 0.5. A puzzle that needs the same
+	// hard step over and over is harder than one that needs it once.
+	Rating float64
+	// The bucket of the rating. Repeating a technique doesn't move a puzzle
+	// into the next bucket, the repeats only add up to just below it, so a
+	// puzzle of only hidden singles is easy however many of them it takes.
+	Difficulty Difficulty
+	Hardest    Technique
+	// how many steps used each technique
+	Counts map[Technique]int
+	Steps  int
+	// whether logic alone was enough to solve the puzzle
+	Solved bool
+}
+
+// Grade a puzzle by solving it with every technique the logic solver knows.
+// The solver always uses the simplest technique it can, so the hardest step
+// it takes is one the puzzle can't do without. A puzzle the logic solver
+// can't work through is an error, see CheckLogicSupport.
+func GradePuzzle(puzzle Puzzle) (Grade, error) {
+	if err := CheckLogicSupport(puzzle); err != nil {
+		return Grade{}, err
+	}
+
+	result := NewLogicSolver().Solve(puzzle)
+
+	grade := Grade{
+		Counts: map[Technique]int{},
+		Steps:  len(result.Steps),
+		Solved: result.Solved,
+	}
+
+	hardest := 0.0
+	for _, step := range result.Steps {
+		grade.Counts[step.Technique]++
+
+		rating := TechniqueRatings[step.Technique]
+		if rating > hardest {
+			hardest = rating
+			grade.Hardest = step.Technique
+		}
+	}
+
+	if !result.Solved {
+		grade.Rating = BeyondLogicRating
+		grade.Difficulty = Diabolical
+		return grade, nil
+	}
+
+	repeats := float64(grade.Counts[grade.Hardest] - 1)
+	rating := hardest + min(0.1*repeats, 0.5)
+	if next, ok := nextThreshold(hardest); ok {
+		rating = min(rating, next-0.1)
+	}
+	grade.Rating = roundRating(rating)
+	grade.Difficulty = DifficultyFor(grade.Rating)
+
+	return grade, nil
+}
+
+// Ratings are kept to one decimal place, the way Sudoku Explainer shows them.
+func roundRating(rating float64) float64 {
+	return float64(int(rating*10+0.5)) / 10
+}
@@ -0,0 +1,123 @@
+package sudoku
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGradePuzzle(t *testing.T) {
+	t.Run("hidden singles only", func(t *testing.T) {
+		board := [][]int{
+			{0, 0, 0, 0, 8, 0, 0, 0, 0},
+			{8, 2, 3, 1, 0, 7, 4, 9, 6},
+			{0, 0, 0, 0, 0, 0, 0, 0, 8},
+			{9, 4, 8, 0, 0, 2, 0, 0, 1},
+			{0, 7, 5, 0, 0, 0, 6, 0, 0},
+			{6, 0, 1, 0, 4, 9, 8, 2, 0},
+			{0, 8, 0, 0, 1, 0, 9, 0, 2},
+			{0, 0, 0, 7, 6, 3, 0, 0, 0},
+			{5, 1, 0, 9, 2, 8, 0, 7, 4},
+		}
+
+		grade, err := GradePuzzle(Puzzle{Board: board})
+		assert.NoError(t, err)
+
+		assert.True(t, grade.Solved)
+		assert.Equal(t, HiddenSingle, grade.Hardest)
+		assert.Equal(t, Easy, grade.Difficulty)
+		assert.Equal(t, grade.Steps, grade.Counts[HiddenSingle])
+		// the repeats stop just short of medium
+		assert.InDelta(t, 1.9, grade.Rating, 0.001)
+		assert.Equal(t, DifficultyFor(grade.Rating), grade.Difficulty)
+	})
+
+	t.Run("needs a naked pair", func(t *testing.T) {
+		board := [][]int{
+			{0, 0, 0, 5, 0, 1, 0, 7, 0},
+			{0, 0, 0, 0, 0, 9, 0, 0, 0},
+			{0, 0, 0, 0, 6, 0, 0, 2, 5},
+			{8, 0, 0, 0, 0, 0, 0, 9, 0},
+			{5, 0, 1, 0, 9, 3, 0, 0, 0},
+			{4, 0, 0, 0, 2, 0, 0, 0, 0},
+			{1, 2, 7, 0, 0, 0, 0, 0, 4},
+			{0, 0, 0, 0, 0, 6, 9, 0, 0},
+			{0, 0, 5, 0, 0, 0, 0, 3, 1},
+		}
+
+		grade, err := GradePuzzle(Puzzle{Board: board})
+		assert.NoError(t, err)
+
+		assert.True(t, grade.Solved)
+		assert.Greater(t, grade.Counts[NakedPair], 0)
+		assert.GreaterOrEqual(t, grade.Rating, TechniqueRatings[NakedPair])
+		assert.Equal(t, DifficultyFor(TechniqueRatings[grade.Hardest]), grade.Difficulty)
+		assert.Equal(t, DifficultyFor(grade.Rating), grade.Difficulty)
+	})
+
+	t.Run("beyond logic", func(t *testing.T) {
+		// two clues can't be finished without guessing
+		board := make([][]int, 9)
+		for row := range board {
+			board[row] = make([]int, 9)
+		}
+		board[0][0] = 1
+		board[4][4] = 2
+
+		grade, err := GradePuzzle(Puzzle{Board: board})
+		assert.NoError(t, err)
+
+		assert.False(t, grade.Solved)
+		assert.Equal(t, BeyondLogicRating, grade.Rating)
+		assert.Equal(t, Diabolical, grade.Difficulty)
+	})
+
+	t.Run("killer cages", func(t *testing.T) {
+		board := make([][]int, 9)
+		for row := range board {
+			board[row] = make([]int, 9)
+		}
+		cages := []Cage{{Sum: 3, Cells: []Position{{Row: 0, Cell: 0}, {Row: 0, Cell: 1}}}}
+
+		_, err := GradePuzzle(Puzzle{Board: board, Cages: cages})
+
+		assert.ErrorIs(t, err, ErrCagesBeyondLogic)
+	})
+}
+
+func TestDifficultyFor(t *testing.T) {
+	tests := []struct {
+		rating     float64
+		difficulty Difficulty
+	}{
+		{rating: 1.5, difficulty: Easy},
+		{rating: 2.3, difficulty: Medium},
+		{rating: 2.9, difficulty: Medium},
+		{rating: 3.0, difficulty: Hard},
+		{rating: 4.2, difficulty: Expert},
+		{rating: 5.5, difficulty: Expert},
+		{rating: 6.6, difficulty: Diabolical},
+		{rating: BeyondLogicRating, difficulty: Diabolical},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.difficulty), func(t *testing.T) {
+			assert.Equal(t, tt.difficulty, DifficultyFor(tt.rating))
+		})
+	}
+}
+
+func TestTechniqueRatings(t *testing.T) {
+	for _, technique := range AllTechniques {
+		assert.Contains(t, TechniqueRatings, technique, "%s has no rating", technique)
+	}
+}
+
+func TestParseDifficulty(t *testing.T) {
+	difficulty, err := ParseDifficulty("expert")
+	assert.NoError(t, err)
+	assert.Equal(t, Expert, difficulty)
+
+	_, err = ParseDifficulty("impossible")
+	assert.Error(t, err)
+}
@@ -0,0 +1,184 @@
+package sudoku
+
+import (
+	"fmt"
+	"strings"
+)
+
+// How a board is split into sectors. Usually they're the boxes of the board's
+// shape, but a jigsaw puzzle has irregular regions instead, each still made
+// up of one cell for every value.
+type Layout struct {
+	Shape
+	// The region of every cell, numbered from 0, or nil for boxes.
+	Regions [][]int
+}
+
+func (layout Layout) SectorIndexFor(row int, cell int) int {
+	if layout.Regions == nil {
+		return layout.Shape.SectorIndexFor(row, cell)
+	}
+
+	return layout.Regions[row][cell]
+}
+
+// Every row, column, and sector of the board, each as the positions of the
+// cells that make it up. A region's cells are in row-major order.
+func (layout Layout) Units() [][]Position {
+	if layout.Regions == nil {
+		return layout.Shape.Units()
+	}
+
+	size := layout.Size()
+	units := append([][]Position{}, layout.Shape.Units()[:2*size]...)
+
+	regions := make([][]Position, size)
+	for row := range size {
+		for cell := range size {
+			region := layout.Regions[row][cell]
+			regions[region] = append(regions[region], Position{Row: row, Cell: cell})
+		}
+	}
+
+	return append(units, regions...)
+}
+
+// Every other cell that shares a row, column, or sector with this one.
+func (layout Layout) Peers(row int, cell int) []Position {
+	size := layout.Size()
+	peers := []Position{}
+	sector := layout.SectorIndexFor(row, cell)
+
+	for r := range size {
+		for c := range size {
+			if r == row && c == cell {
+				continue
+			}
+			if r == row || c == cell || layout.SectorIndexFor(r, c) == sector {
+				peers = append(peers, Position{Row: r, Cell: c})
+			}
+		}
+	}
+
+	return peers
+}
+
+// Whether two different cells share a row, column, or sector.
+func (layout Layout) sees(a Position, b Position) bool {
+	if a == b {
+		return false
+	}
+
+	return a.Row == b.Row || a.Cell == b.Cell || layout.SectorIndexFor(a.Row, a.Cell) == layout.SectorIndexFor(b.Row, b.Cell)
+}
+
+// The most cells any sector has in a single row or column.
+func (layout Layout) longestSectorLine() int {
+	if layout.Regions == nil {
+		return max(layout.BoxRows, layout.BoxColumns)
+	}
+
+	longest := 0
+	for _, unit := range layout.Units()[2*layout.Size():] {
+		rows := map[int]int{}
+		columns := map[int]int{}
+		for _, position := range unit {
+			rows[position.Row]++
+			columns[position.Cell]++
+			longest = max(longest, rows[position.Row], columns[position.Cell])
+		}
+	}
+
+	return longest
+}
+
+// Parse a region layout with a line per row and a label per cell. Any symbol
+// can be a label, the regions are numbered in the order their labels first
+// show up. Like a puzzle's rows, a row with spaces in it is split on them.
+func ParseRegions(rows []string) [][]int {
+	labels := map[string]int{}
+	regions := [][]int{}
+
+	for _, row := range rows {
+		unparsedCells := strings.Split(row, "")
+		if strings.ContainsAny(row, " \t") {
+			unparsedCells = strings.Fields(row)
+		}
+
+		cells := []int{}
+		for _, label := range unparsedCells {
+			region, ok := labels[label]
+			if !ok {
+				region = len(labels)
+				labels[label] = region
+			}
+			cells = append(cells, region)
+		}
+
+		regions = append(regions, cells)
+	}
+
+	return regions
+}
+
+// Check that the regions cover a board of the given size, with one region
+// for every value, each made up of one cell for every value and connected
+// through the sides of its cells.
+func CheckRegions(regions [][]int, size int) error {
+	if len(regions) != size {
+		return fmt.Errorf("the region layout has %d rows, expected %d", len(regions), size)
+	}
+
+	cells := make([][]Position, size)
+	for row := range size {
+		if len(regions[row]) != size {
+			return fmt.Errorf("row %d of the region layout has %d cells, expected %d", row+1, len(regions[row]), size)
+		}
+
+		for cell, region := range regions[row] {
+			if region < 0 || region >= size {
+				return fmt.Errorf("the region layout has more than %d regions", size)
+			}
+			cells[region] = append(cells[region], Position{Row: row, Cell: cell})
+		}
+	}
+
+	for region, positions := range cells {
+		if len(positions) != size {
+			return fmt.Errorf("region %d has %d cells, expected %d", region+1, len(positions), size)
+		}
+
+		if !connected(positions) {
+			return fmt.Errorf("region %d isn't connected, its cells have to be next to each other", region+1)
+		}
+	}
+
+	return nil
+}
+
+// Whether every cell can be reached from the first one by moving up, down,
+// left, or right without leaving the cells.
+func connected(positions []Position) bool {
+	reached := map[Position]bool{positions[0]: true}
+	queue := []Position{positions[0]}
+
+	for len(queue) > 0 {
+		position := queue[0]
+		queue = queue[1:]
+
+		neighbors := []Position{
+			{Row: position.Row - 1, Cell: position.Cell},
+			{Row: position.Row + 1, Cell: position.Cell},
+			{Row: position.Row, Cell: position.Cell - 1},
+			{Row: position.Row, Cell: position.Cell + 1},
+		}
+		for _, neighbor := range neighbors {
+			if !reached[neighbor] && containsPosition(positions, neighbor) {
+				reached[neighbor] = true
+				queue = append(queue, neighbor)
+			}
+		}
+	}
+
+	return len(reached) == len(positions)
+}
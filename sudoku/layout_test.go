@@ -0,0 +1,141 @@
+package sudoku
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var jigsawRows = []string{
+	"AAABBB",
+	"AABBBC",
+	"ADDDCC",
+	"DDDECC",
+	"EEEEFC",
+	"EFFFFF",
+}
+
+func TestParseRegions(t *testing.T) {
+	regions := ParseRegions([]string{"aab", "abb", "ccc"})
+	assert.Equal(t, [][]int{{0, 0, 1}, {0, 1, 1}, {2, 2, 2}}, regions)
+
+	regions = ParseRegions([]string{"1 1 10", "1 10 10", "2 2 2"})
+	assert.Equal(t, [][]int{{0, 0, 1}, {0, 1, 1}, {2, 2, 2}}, regions)
+}
+
+func TestCheckRegions(t *testing.T) {
+	tests := []struct {
+		name                  string
+		rows                  []string
+		expectedErrorContains string
+	}{
+		{
+			name:                  "valid",
+			rows:                  jigsawRows,
+			expectedErrorContains: "",
+		},
+		{
+			name:                  "missing a row",
+			rows:                  jigsawRows[:5],
+			expectedErrorContains: "the region layout has 5 rows, expected 6",
+		},
+		{
+			name:                  "short row",
+			rows:                  append(append([]string{}, jigsawRows[:5]...), "FFFFF"),
+			expectedErrorContains: "row 6 of the region layout has 5 cells, expected 6",
+		},
+		{
+			name:                  "too many regions",
+			rows:                  append(append([]string{}, jigsawRows[:5]...), "FFFFFG"),
+			expectedErrorContains: "more than 6 regions",
+		},
+		{
+			name:                  "uneven regions",
+			rows:                  []string{"AAABBB", "AABBBC", "ADDDCC", "DDDECC", "EEEEFC", "EFFFFA"},
+			expectedErrorContains: "region 1 has 7 cells, expected 6",
+		},
+		{
+			name:                  "disconnected region",
+			rows:                  []string{"FAABBB", "AABBBC", "ADDDCC", "DDDECC", "EEEEFC", "EFFFFA"},
+			expectedErrorContains: "region 1 isn't connected",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckRegions(ParseRegions(tt.rows), 6)
+
+			if tt.expectedErrorContains == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, tt.expectedErrorContains)
+			}
+		})
+	}
+}
+
+func TestJigsawLayout(t *testing.T) {
+	layout := Layout{Shape: Shape{BoxRows: 2, BoxColumns: 3}, Regions: ParseRegions(jigsawRows)}
+
+	assert.Equal(t, 1, layout.SectorIndexFor(1, 2))
+	assert.Equal(t, 3, layout.SectorIndexFor(3, 2))
+	assert.Equal(t, 2, layout.SectorIndexFor(4, 5))
+
+	units := layout.Units()
+	assert.Len(t, units, 18)
+	assert.Equal(t, []Position{{0, 3}, {0, 4}, {0, 5}, {1, 2}, {1, 3}, {1, 4}}, units[13])
+	for sectorIndex, unit := range units[12:] {
+		assert.Len(t, unit, 6)
+		for _, position := range unit {
+			assert.Equal(t, sectorIndex, layout.SectorIndexFor(position.Row, position.Cell))
+		}
+	}
+
+	// r3c2 and r4c1 are in different boxes but the same region
+	assert.True(t, layout.sees(Position{Row: 2, Cell: 1}, Position{Row: 3, Cell: 0}))
+	assert.True(t, layout.sees(Position{Row: 0, Cell: 0}, Position{Row: 1, Cell: 1}))
+	// r1c1 and r2c3 share a box but not a region
+	assert.False(t, layout.sees(Position{Row: 0, Cell: 0}, Position{Row: 1, Cell: 2}))
+	assert.Len(t, layout.Peers(2, 0), 5+5+3)
+
+	assert.Equal(t, 5, layout.longestSectorLine())
+}
+
+func TestJigsawPrettyString(t *testing.T) {
+	board := make([][]int, 6)
+	for row := range board {
+		board[row] = make([]int, 6)
+	}
+	board[0][0] = 1
+	board[5][5] = 6
+
+	puzzle := Puzzle{Board: board, Regions: ParseRegions(jigsawRows)}
+
+	expected := strings.Join([]string{
+		"╔═══════════╤═══════════╗",
+		"║ 1   _   _ │ _   _   _ ║",
+		"║       ┌───┘       ┌───╢",
+		"║ _   _ │ _   _   _ │ _ ║",
+		"║   ┌───┴───────┬───┘   ║",
+		"║ _ │ _   _   _ │ _   _ ║",
+		"╟───┘       ┌───┤       ║",
+		"║ _   _   _ │ _ │ _   _ ║",
+		"╟───────────┘   ├───┐   ║",
+		"║ _   _   _   _ │ _ │ _ ║",
+		"║   ┌───────────┘   └───╢",
+		"║ _ │ _   _   _   _   6 ║",
+		"╚═══╧═══════════════════╝",
+	}, "\n")
+	assert.Equal(t, expected, puzzle.PrettyString())
+}
+
+func TestLayoutUnitName(t *testing.T) {
+	boxes := Layout{Shape: Shape{BoxRows: 2, BoxColumns: 3}}
+	jigsaw := Layout{Shape: Shape{BoxRows: 2, BoxColumns: 3}, Regions: ParseRegions(jigsawRows)}
+
+	assert.Equal(t, "row 2", jigsaw.unitName(1))
+	assert.Equal(t, "column 2", jigsaw.unitName(7))
+	assert.Equal(t, "box 2", boxes.unitName(13))
+	assert.Equal(t, "region 2", jigsaw.unitName(13))
+}
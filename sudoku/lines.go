@@ -0,0 +1,250 @@
+package sudoku
+
+import (
+	"fmt"
+	"strings"
+)
+
+// A thermometer: the values along it strictly increase from its bulb, its
+// first cell, to its tip.
+type Thermometer struct {
+	Path []Position
+}
+
+func (Thermometer) Name() string { return "thermometer" }
+
+func (thermometer Thermometer) Cells(layout Layout) []Position { return thermometer.Path }
+
+// A cell i steps up the thermometer is at least i+1 and leaves room for the
+// cells above it, and it has to fit between the values already placed below
+// and above it, with room for the cells in between.
+func (thermometer Thermometer) Prune(puz *Puzzle, row int, cell int, candidates CandidateMask) CandidateMask {
+	i := positionIndex(thermometer.Path, Position{Row: row, Cell: cell})
+	if i == -1 {
+		return candidates
+	}
+
+	size := puz.state().layout.Size()
+	low, high := i+1, size-(len(thermometer.Path)-1-i)
+	for j, position := range thermometer.Path {
+		value := puz.ValueAt(position.Row, position.Cell)
+		if value == 0 || j == i {
+			continue
+		}
+
+		if j < i {
+			low = max(low, value+i-j)
+		} else {
+			high = min(high, value-(j-i))
+		}
+	}
+
+	return candidates & valueRange(low, high)
+}
+
+func (thermometer Thermometer) Validate(puz *Puzzle) error {
+	for i, position := range thermometer.Path {
+		value := puz.ValueAt(position.Row, position.Cell)
+		if value == 0 {
+			continue
+		}
+
+		for _, earlier := range thermometer.Path[:i] {
+			earlierValue := puz.ValueAt(earlier.Row, earlier.Cell)
+			if earlierValue != 0 && earlierValue >= value {
+				return Violation{
+					Cells:       []Position{earlier, position},
+					Description: fmt.Sprintf("the thermometer from %s doesn't increase, the '%d' in %s comes after the '%d' in %s", thermometer.Path[0], value, position, earlierValue, earlier),
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+func (thermometer Thermometer) checkCells(size int) error {
+	start := thermometer.Path[0]
+	if len(thermometer.Path) < 2 {
+		return fmt.Errorf("the thermometer from %s needs at least 2 cells", start)
+	}
+	if len(thermometer.Path) > size {
+		return fmt.Errorf("the thermometer from %s has %d cells, more than the %d values that can go up it", start, len(thermometer.Path), size)
+	}
+
+	return checkPath(thermometer.Path, fmt.Sprintf("the thermometer from %s", start))
+}
+
+func (thermometer Thermometer) draw(marks boardMarks) {
+	marks.bracket(thermometer.Path[0], "(", ")")
+	marks.connectPath(thermometer.Path)
+}
+
+func (thermometer Thermometer) String() string {
+	return "thermo: " + positionList(thermometer.Path)
+}
+
+// An arrow: the values along it add up to the value in its circle. Unlike a
+// cage's, they can repeat wherever the rows, columns, and sectors allow it.
+type Arrow struct {
+	Circle Position
+	// from the cell next to the circle to the arrow's tip
+	Path []Position
+}
+
+func (Arrow) Name() string { return "arrow" }
+
+func (arrow Arrow) Cells(layout Layout) []Position { return arrow.line() }
+
+// The circle and then the arrow's path.
+func (arrow Arrow) line() []Position {
+	return append([]Position{arrow.Circle}, arrow.Path...)
+}
+
+// What the values already on the arrow add up to, and how many of its cells
+// are still empty.
+func (arrow Arrow) progress(puz *Puzzle) (int, int) {
+	total, empty := 0, 0
+	for _, position := range arrow.Path {
+		value := puz.ValueAt(position.Row, position.Cell)
+		total += value
+		if value == 0 {
+			empty++
+		}
+	}
+
+	return total, empty
+}
+
+// The circle has to fit what the arrow adds up to, at least a 1 for each of
+// its empty cells, and a cell on the arrow has to leave the same for the
+// others without going over the circle.
+func (arrow Arrow) Prune(puz *Puzzle, row int, cell int, candidates CandidateMask) CandidateMask {
+	size := puz.state().layout.Size()
+	position := Position{Row: row, Cell: cell}
+	total, empty := arrow.progress(puz)
+
+	if position == arrow.Circle {
+		if empty == 0 {
+			return candidates & valueRange(total, total)
+		}
+		return candidates & valueRange(total+empty, size)
+	}
+
+	if positionIndex(arrow.Path, position) == -1 {
+		return candidates
+	}
+
+	// the cell itself is empty, so this is what the other cells need
+	othersEmpty := empty - 1
+	circle := puz.ValueAt(arrow.Circle.Row, arrow.Circle.Cell)
+	if circle == 0 {
+		return candidates & valueRange(1, size-total-othersEmpty)
+	}
+	if othersEmpty == 0 {
+		return candidates & valueRange(circle-total, circle-total)
+	}
+
+	return candidates & valueRange(1, circle-total-othersEmpty)
+}
+
+func (arrow Arrow) Validate(puz *Puzzle) error {
+	total, empty := arrow.progress(puz)
+	circle := puz.ValueAt(arrow.Circle.Row, arrow.Circle.Cell)
+	cells := arrow.line()
+
+	switch {
+	case circle != 0 && total > circle:
+		return Violation{
+			Cells:       cells,
+			Description: fmt.Sprintf("Sum check failed, values on the arrow from %s add up to %d, more than the '%d' in its circle", arrow.Circle, total, circle),
+		}
+	case circle != 0 && empty == 0 && total != circle:
+		return Violation{
+			Cells:       cells,
+			Description: fmt.Sprintf("Sum check failed, values on the arrow from %s add up to %d instead of the '%d' in its circle", arrow.Circle, total, circle),
+		}
+	case circle == 0 && empty == 0 && total > puz.Size():
+		return Violation{
+			Cells:       cells,
+			Description: fmt.Sprintf("Sum check failed, values on the arrow from %s add up to %d, more than fits in its circle", arrow.Circle, total),
+		}
+	}
+
+	return nil
+}
+
+func (arrow Arrow) checkCells(size int) error {
+	if len(arrow.Path) == 0 {
+		return fmt.Errorf("the arrow from %s doesn't have any cells past its circle", arrow.Circle)
+	}
+	if len(arrow.Path) > size {
+		return fmt.Errorf("the arrow from %s has %d cells, which add up to more than the %d its circle can hold", arrow.Circle, len(arrow.Path), size)
+	}
+
+	return checkPath(arrow.line(), fmt.Sprintf("the arrow from %s", arrow.Circle))
+}
+
+func (arrow Arrow) draw(marks boardMarks) {
+	marks.bracket(arrow.Circle, "[", "]")
+	marks.connectPath(arrow.line())
+}
+
+func (arrow Arrow) String() string {
+	return "arrow: " + positionList(arrow.line())
+}
+
+// The values from low to high, clipped to the ones a cell can hold, which
+// leaves none when low is past high.
+func valueRange(low int, high int) CandidateMask {
+	low = max(low, 1)
+	high = min(high, MaxSize)
+	if low > high {
+		return 0
+	}
+
+	return allValues(high) &^ allValues(low-1)
+}
+
+func positionIndex(positions []Position, position Position) int {
+	for i, p := range positions {
+		if p == position {
+			return i
+		}
+	}
+
+	return -1
+}
+
+func positionList(positions []Position) string {
+	names := []string{}
+	for _, position := range positions {
+		names = append(names, position.String())
+	}
+
+	return strings.Join(names, " ")
+}
+
+// Every cell of a line has to touch the one before it, even diagonally, and
+// the line can't cross itself.
+func checkPath(path []Position, name string) error {
+	for i, position := range path {
+		if positionIndex(path[:i], position) != -1 {
+			return fmt.Errorf("%s goes through %s twice", name, position)
+		}
+		if i == 0 {
+			continue
+		}
+
+		previous := path[i-1]
+		if abs(position.Row-previous.Row) > 1 || abs(position.Cell-previous.Cell) > 1 {
+			return fmt.Errorf("%s isn't connected, %s doesn't touch %s", name, position, previous)
+		}
+	}
+
+	return nil
+}
+
+func abs(value int) int {
+	return max(value, -value)
+}
@@ -0,0 +1,125 @@
+package sudoku
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestThermometer(t *testing.T) {
+	thermometer := Thermometer{Path: []Position{{0, 0}, {0, 1}, {1, 2}, {2, 2}}}
+	puzzle := Puzzle{Board: emptyBoard(9), Constraints: []Constraint{thermometer}}
+
+	// four cells leave room for the bulb to go up to 6 and the tip down to 4
+	assert.Equal(t, []int{1, 2, 3, 4, 5, 6}, puzzle.Candidates(0, 0).Values())
+	assert.Equal(t, []int{2, 3, 4, 5, 6, 7}, puzzle.Candidates(0, 1).Values())
+	assert.Equal(t, []int{4, 5, 6, 7, 8, 9}, puzzle.Candidates(2, 2).Values())
+
+	// a 6 two steps up leaves the cell between it and the bulb at most a 5,
+	// and the tip at least a 7
+	puzzle.PlaceValue(1, 2, 6)
+	assert.Equal(t, []int{2, 3, 4, 5}, puzzle.Candidates(0, 1).Values())
+	assert.Equal(t, []int{7, 8, 9}, puzzle.Candidates(2, 2).Values())
+
+	puzzle.PlaceValue(0, 0, 3)
+	assert.Equal(t, []int{4, 5}, puzzle.Candidates(0, 1).Values())
+
+	assert.NoError(t, thermometer.Validate(&puzzle))
+	puzzle.PlaceValue(2, 2, 6)
+	assert.ErrorContains(t, thermometer.Validate(&puzzle), "the thermometer from r1c1 doesn't increase, the '6' in r3c3 comes after the '6' in r2c3")
+}
+
+func TestArrow(t *testing.T) {
+	arrow := Arrow{Circle: Position{0, 0}, Path: []Position{{1, 1}, {2, 2}}}
+	puzzle := Puzzle{Board: emptyBoard(9), Constraints: []Constraint{arrow}}
+
+	// two cells add up to at least 2, and leave each other at least a 1
+	assert.Equal(t, []int{2, 3, 4, 5, 6, 7, 8, 9}, puzzle.Candidates(0, 0).Values())
+	assert.Equal(t, []int{1, 2, 3, 4, 5, 6, 7, 8}, puzzle.Candidates(1, 1).Values())
+	assert.Equal(t, []int{1, 2, 3, 4, 5, 6, 7, 8, 9}, puzzle.Candidates(1, 2).Values())
+
+	puzzle.PlaceValue(1, 1, 4)
+	assert.Equal(t, []int{5, 6, 7, 8, 9}, puzzle.Candidates(0, 0).Values())
+	assert.Equal(t, []int{1, 2, 3, 5}, puzzle.Candidates(2, 2).Values())
+
+	puzzle.PlaceValue(0, 0, 7)
+	assert.Equal(t, []int{3}, puzzle.Candidates(2, 2).Values())
+
+	tests := []struct {
+		name                  string
+		values                map[Position]int
+		expectedErrorContains string
+	}{
+		{
+			name:   "adds up",
+			values: map[Position]int{{0, 0}: 7, {1, 1}: 4, {2, 2}: 3},
+		},
+		{
+			name:   "partly filled in",
+			values: map[Position]int{{0, 0}: 7, {1, 1}: 4},
+		},
+		{
+			name:                  "over the circle",
+			values:                map[Position]int{{0, 0}: 3, {1, 1}: 4},
+			expectedErrorContains: "Sum check failed, values on the arrow from r1c1 add up to 4, more than the '3' in its circle",
+		},
+		{
+			name:                  "under the circle",
+			values:                map[Position]int{{0, 0}: 9, {1, 1}: 4, {2, 2}: 3},
+			expectedErrorContains: "Sum check failed, values on the arrow from r1c1 add up to 7 instead of the '9' in its circle",
+		},
+		{
+			name:                  "too much for any circle",
+			values:                map[Position]int{{1, 1}: 6, {2, 2}: 7},
+			expectedErrorContains: "Sum check failed, values on the arrow from r1c1 add up to 13, more than fits in its circle",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			board := emptyBoard(9)
+			for position, value := range tt.values {
+				board[position.Row][position.Cell] = value
+			}
+
+			err := arrow.Validate(&Puzzle{Board: board})
+
+			if tt.expectedErrorContains == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, tt.expectedErrorContains)
+				assert.Len(t, err.(Violation).Cells, 3)
+			}
+		})
+	}
+}
+
+func TestConstraintPrettyString(t *testing.T) {
+	board := [][]int{
+		{1, 0, 0, 0},
+		{0, 0, 0, 0},
+		{0, 0, 0, 0},
+		{0, 0, 0, 2},
+	}
+	puzzle := Puzzle{Board: board, Constraints: []Constraint{
+		Thermometer{Path: []Position{{0, 0}, {1, 1}, {1, 2}}},
+		Arrow{Circle: Position{3, 0}, Path: []Position{{2, 0}, {2, 1}}},
+		KropkiDot{Pair: [2]Position{{0, 2}, {0, 3}}},
+		KropkiDot{Pair: [2]Position{{2, 3}, {3, 3}}, Black: true},
+		XVPair{Pair: [2]Position{{3, 1}, {3, 2}}, Sum: 5},
+	}}
+
+	expected := strings.Join([]string{
+		"╔═══════╤═══════╗",
+		"║(1)  _ │ _ ○ _ ║",
+		"║   ╲   │       ║",
+		"║ _   _ ─ _   _ ║",
+		"╟───────┼───────╢",
+		"║ _ ─ _ │ _   _ ║",
+		"║ │     │     ● ║",
+		"║[_]  _ V _   2 ║",
+		"╚═══════╧═══════╝",
+	}, "\n")
+	assert.Equal(t, expected, puzzle.PrettyString())
+}
@@ -0,0 +1,688 @@
+package sudoku
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// A named solving technique that a person could apply without guessing.
+type Technique string
+
+const (
+	HiddenSingle     Technique = "Hidden Single"
+	NakedSingle      Technique = "Naked Single"
+	PointingPair     Technique = "Pointing Pair"
+	BoxLineReduction Technique = "Box/Line Reduction"
+	NakedPair        Technique = "Naked Pair"
+	HiddenPair       Technique = "Hidden Pair"
+	NakedTriple      Technique = "Naked Triple"
+	HiddenTriple     Technique = "Hidden Triple"
+	NakedQuad        Technique = "Naked Quad"
+	HiddenQuad       Technique = "Hidden Quad"
+	XWing            Technique = "X-Wing"
+	Swordfish        Technique = "Swordfish"
+	Jellyfish        Technique = "Jellyfish"
+	FinnedXWing      Technique = "Finned X-Wing"
+	FinnedSwordfish  Technique = "Finned Swordfish"
+	FinnedJellyfish  Technique = "Finned Jellyfish"
+	SashimiXWing     Technique = "Sashimi X-Wing"
+	SashimiSwordfish Technique = "Sashimi Swordfish"
+	SashimiJellyfish Technique = "Sashimi Jellyfish"
+	XYWing           Technique = "XY-Wing"
+	XYZWing          Technique = "XYZ-Wing"
+	WWing            Technique = "W-Wing"
+	SimpleColoring   Technique = "Simple Coloring"
+	MultiColoring    Technique = "Multi-Coloring"
+	XChain           Technique = "X-Chain"
+	XCycle           Technique = "X-Cycle"
+	XYChain          Technique = "XY-Chain"
+	AIC              Technique = "AIC"
+)
+
+// A value that could still go in a cell.
+type Candidate struct {
+	Row   int
+	Cell  int
+	Value int
+}
+
+func (candidate Candidate) String() string {
+	return fmt.Sprintf("%s<>%d", cellName(candidate.Row, candidate.Cell), candidate.Value)
+}
+
+// One deduction made by the logic solver: the technique, the cells that make
+// up the pattern, and what it allows us to place or rule out.
+type Step struct {
+	Technique    Technique
+	Cells        []Position
+	Values       []int
+	Placements   []Placement
+	Eliminations []Candidate
+	Description  string
+}
+
+func (step Step) String() string {
+	var builder strings.Builder
+	builder.WriteString(fmt.Sprintf("%s: %s", step.Technique, step.Description))
+
+	if len(step.Eliminations) > 0 {
+		eliminations := []string{}
+		for _, elimination := range step.Eliminations {
+			eliminations = append(eliminations, elimination.String())
+		}
+		builder.WriteString(" => ")
+		builder.WriteString(strings.Join(eliminations, ", "))
+	}
+
+	return builder.String()
+}
+
+// Cells are named the way Sudoku players write them, r1c1 is the top-left
+// cell and r9c9 the bottom-right one.
+func cellName(row int, cell int) string {
+	return fmt.Sprintf("r%dc%d", row+1, cell+1)
+}
+
+func cellNames(positions []Position) string {
+	names := []string{}
+	for _, position := range positions {
+		names = append(names, cellName(position.Row, position.Cell))
+	}
+
+	return strings.Join(names, ",")
+}
+
+func valueNames(values []int) string {
+	names := []string{}
+	for _, value := range values {
+		names = append(names, fmt.Sprintf("%d", value))
+	}
+
+	return strings.Join(names, "/")
+}
+
+// Units() lists the rows first, then the columns, then the sectors.
+func (layout Layout) unitName(unitIndex int) string {
+	size := layout.Size()
+	switch unitIndex / size {
+	case 0:
+		return fmt.Sprintf("row %d", unitIndex%size+1)
+	case 1:
+		return fmt.Sprintf("column %d", unitIndex%size+1)
+	default:
+		if layout.Regions != nil {
+			return fmt.Sprintf("region %d", unitIndex%size+1)
+		}
+
+		return fmt.Sprintf("box %d", unitIndex%size+1)
+	}
+}
+
+// The pencil marks for a puzzle: the value of every filled in cell, and the
+// values that could still go in every empty one.
+type CandidateGrid struct {
+	Layout     Layout
+	Values     [][]int
+	Candidates [][]CandidateMask
+}
+
+func NewCandidateGrid(puzzle Puzzle) CandidateGrid {
+	board := puzzle.CurrentBoard()
+	layout := puzzle.Layout()
+	size := layout.Size()
+
+	grid := CandidateGrid{
+		Layout:     layout,
+		Values:     board,
+		Candidates: make([][]CandidateMask, size),
+	}
+	for row := range size {
+		grid.Candidates[row] = make([]CandidateMask, size)
+		for cell := range size {
+			if board[row][cell] == 0 {
+				grid.Candidates[row][cell] = puzzle.Candidates(row, cell)
+			}
+		}
+	}
+
+	return grid
+}
+
+func (grid *CandidateGrid) IsSolved() bool {
+	for _, row := range grid.Values {
+		for _, value := range row {
+			if value == 0 {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// Whether some empty cell has run out of candidates, or some unit has a value
+// with nowhere left to go, which only happens when the puzzle has no
+// solution.
+func (grid *CandidateGrid) HasContradiction() bool {
+	size := grid.Layout.Size()
+	for row := range size {
+		for cell := range size {
+			if grid.Values[row][cell] == 0 && grid.Candidates[row][cell] == 0 {
+				return true
+			}
+		}
+	}
+
+	for _, unit := range grid.Layout.Units() {
+		var covered CandidateMask
+		for _, position := range unit {
+			covered |= MaskFor(grid.Values[position.Row][position.Cell])
+			covered |= grid.Candidates[position.Row][position.Cell]
+		}
+
+		if covered != grid.Layout.AllValuesMask() {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (grid *CandidateGrid) place(row int, cell int, value int) {
+	grid.Values[row][cell] = value
+	grid.Candidates[row][cell] = 0
+
+	for _, peer := range grid.Layout.Peers(row, cell) {
+		grid.Candidates[peer.Row][peer.Cell] &^= MaskFor(value)
+	}
+}
+
+func (grid *CandidateGrid) Apply(step Step) {
+	for _, placement := range step.Placements {
+		grid.place(placement.Row, placement.Cell, placement.Value)
+	}
+
+	for _, elimination := range step.Eliminations {
+		grid.Candidates[elimination.Row][elimination.Cell] &^= MaskFor(elimination.Value)
+	}
+}
+
+func (grid *CandidateGrid) has(position Position, value int) bool {
+	return grid.Candidates[position.Row][position.Cell].Has(value)
+}
+
+// The empty cells of the unit that still have value as a candidate.
+func (grid *CandidateGrid) positionsFor(unit []Position, value int) []Position {
+	positions := []Position{}
+	for _, position := range unit {
+		if grid.has(position, value) {
+			positions = append(positions, position)
+		}
+	}
+
+	return positions
+}
+
+type techniqueFinder func(grid *CandidateGrid) (Step, bool)
+
+var techniqueFinders = map[Technique]techniqueFinder{
+	HiddenSingle:     findHiddenSingle,
+	NakedSingle:      findNakedSingle,
+	PointingPair:     findPointingPair,
+	BoxLineReduction: findBoxLineReduction,
+	NakedPair:        func(grid *CandidateGrid) (Step, bool) { return findNakedSubset(grid, 2) },
+	HiddenPair:       func(grid *CandidateGrid) (Step, bool) { return findHiddenSubset(grid, 2) },
+	NakedTriple:      func(grid *CandidateGrid) (Step, bool) { return findNakedSubset(grid, 3) },
+	HiddenTriple:     func(grid *CandidateGrid) (Step, bool) { return findHiddenSubset(grid, 3) },
+	NakedQuad:        func(grid *CandidateGrid) (Step, bool) { return findNakedSubset(grid, 4) },
+	HiddenQuad:       func(grid *CandidateGrid) (Step, bool) { return findHiddenSubset(grid, 4) },
+	XWing:            func(grid *CandidateGrid) (Step, bool) { return findBasicFish(grid, 2) },
+	Swordfish:        func(grid *CandidateGrid) (Step, bool) { return findBasicFish(grid, 3) },
+	Jellyfish:        func(grid *CandidateGrid) (Step, bool) { return findBasicFish(grid, 4) },
+	FinnedXWing:      func(grid *CandidateGrid) (Step, bool) { return findFinnedFish(grid, 2, false) },
+	FinnedSwordfish:  func(grid *CandidateGrid) (Step, bool) { return findFinnedFish(grid, 3, false) },
+	FinnedJellyfish:  func(grid *CandidateGrid) (Step, bool) { return findFinnedFish(grid, 4, false) },
+	SashimiXWing:     func(grid *CandidateGrid) (Step, bool) { return findFinnedFish(grid, 2, true) },
+	SashimiSwordfish: func(grid *CandidateGrid) (Step, bool) { return findFinnedFish(grid, 3, true) },
+	SashimiJellyfish: func(grid *CandidateGrid) (Step, bool) { return findFinnedFish(grid, 4, true) },
+	XYWing:           findXYWing,
+	XYZWing:          findXYZWing,
+	WWing:            findWWing,
+	SimpleColoring:   findSimpleColoring,
+	MultiColoring:    findMultiColoring,
+	XChain:           func(grid *CandidateGrid) (Step, bool) { return findChain(grid, chainTechniqueRules[XChain]) },
+	XCycle:           func(grid *CandidateGrid) (Step, bool) { return findChain(grid, chainTechniqueRules[XCycle]) },
+	XYChain:          func(grid *CandidateGrid) (Step, bool) { return findChain(grid, chainTechniqueRules[XYChain]) },
+	AIC:              func(grid *CandidateGrid) (Step, bool) { return findChain(grid, chainTechniqueRules[AIC]) },
+}
+
+// Every technique the logic solver knows, from simplest to hardest.
+var AllTechniques = []Technique{
+	HiddenSingle,
+	NakedSingle,
+	PointingPair,
+	BoxLineReduction,
+	NakedPair,
+	HiddenPair,
+	NakedTriple,
+	HiddenTriple,
+	XWing,
+	XYWing,
+	XYZWing,
+	WWing,
+	Swordfish,
+	SimpleColoring,
+	NakedQuad,
+	HiddenQuad,
+	Jellyfish,
+	FinnedXWing,
+	SashimiXWing,
+	FinnedSwordfish,
+	SashimiSwordfish,
+	FinnedJellyfish,
+	SashimiJellyfish,
+	MultiColoring,
+	XChain,
+	XCycle,
+	XYChain,
+	AIC,
+}
+
+type LogicSolver struct {
+	// The techniques to try, in order. After every step the solver starts
+	// over from the first one, so simpler techniques are always preferred.
+	Techniques []Technique
+}
+
+// The logic solver has no techniques for the cages of a Killer Sudoku, so on
+// its own it gets next to nowhere with a killer puzzle.
+var ErrCagesBeyondLogic = errors.New("the logic solver doesn't know any techniques for killer cages")
+
+// Whether the logic solver can work through the puzzle, which it can't with
+// cages, see ErrCagesBeyondLogic. Explaining or grading a puzzle it gets stuck
+// on for lack of techniques would make it look harder than it is.
+func CheckLogicSupport(puzzle Puzzle) error {
+	if len(puzzle.Cages) > 0 {
+		return ErrCagesBeyondLogic
+	}
+
+	return nil
+}
+
+func NewLogicSolver() LogicSolver {
+	return LogicSolver{Techniques: AllTechniques}
+}
+
+// A copy of the solver that won't use any of the given techniques.
+func (solver LogicSolver) Without(techniques ...Technique) LogicSolver {
+	remaining := []Technique{}
+	for _, technique := range solver.Techniques {
+		if !slices.Contains(techniques, technique) {
+			remaining = append(remaining, technique)
+		}
+	}
+
+	return LogicSolver{Techniques: remaining}
+}
+
+// The technique's name as it's written on the command line, e.g. x-wing or
+// box-line-reduction.
+func (technique Technique) Slug() string {
+	return strings.NewReplacer(" ", "-", "/", "-").Replace(strings.ToLower(string(technique)))
+}
+
+func ParseTechnique(value string) (Technique, error) {
+	for _, technique := range AllTechniques {
+		if technique.Slug() == value || string(technique) == value {
+			return technique, nil
+		}
+	}
+
+	slugs := []string{}
+	for _, technique := range AllTechniques {
+		slugs = append(slugs, technique.Slug())
+	}
+
+	return "", fmt.Errorf("Unrecognized technique '%s', expected one of %v", value, slugs)
+}
+
+type LogicResult struct {
+	Steps []Step
+	Grid  CandidateGrid
+	// whether the techniques were enough to fill in every cell
+	Solved bool
+	// whether the solver ran into a cell with no candidates left, which only
+	// happens when the puzzle has no solution
+	Contradiction bool
+}
+
+// Solve the puzzle one deduction at a time, without ever guessing. The
+// solver stops when the puzzle is solved or none of its techniques apply.
+func (solver LogicSolver) Solve(puzzle Puzzle) LogicResult {
+	grid := NewCandidateGrid(puzzle)
+	result := LogicResult{}
+
+	for !grid.IsSolved() {
+		if grid.HasContradiction() {
+			result.Contradiction = true
+			break
+		}
+
+		step, found := solver.nextStep(&grid)
+		if !found {
+			break
+		}
+
+		grid.Apply(step)
+		result.Steps = append(result.Steps, step)
+	}
+
+	result.Grid = grid
+	result.Solved = grid.IsSolved()
+
+	return result
+}
+
+func (solver LogicSolver) nextStep(grid *CandidateGrid) (Step, bool) {
+	for _, technique := range solver.Techniques {
+		finder, ok := techniqueFinders[technique]
+		if !ok {
+			panic(fmt.Sprintf("Unrecognized technique '%s' provided to LogicSolver", technique))
+		}
+
+		if step, found := finder(grid); found {
+			return step, true
+		}
+	}
+
+	return Step{}, false
+}
+
+func findNakedSingle(grid *CandidateGrid) (Step, bool) {
+	size := grid.Layout.Size()
+	for row := range size {
+		for cell := range size {
+			candidates := grid.Candidates[row][cell]
+			if grid.Values[row][cell] != 0 || candidates.Count() != 1 {
+				continue
+			}
+
+			value := candidates.Values()[0]
+			return Step{
+				Technique:   NakedSingle,
+				Cells:       []Position{{Row: row, Cell: cell}},
+				Values:      []int{value},
+				Placements:  []Placement{{Row: row, Cell: cell, Value: value}},
+				Description: fmt.Sprintf("%s can only be %d", cellName(row, cell), value),
+			}, true
+		}
+	}
+
+	return Step{}, false
+}
+
+func findHiddenSingle(grid *CandidateGrid) (Step, bool) {
+	// boxes first, since that's where people look for them first
+	size := grid.Layout.Size()
+	units := grid.Layout.Units()
+	order := []int{}
+	for i := 2 * size; i < len(units); i++ {
+		order = append(order, i)
+	}
+	for i := range 2 * size {
+		order = append(order, i)
+	}
+
+	for _, unitIndex := range order {
+		unit := units[unitIndex]
+		for value := 1; value <= size; value++ {
+			positions := grid.positionsFor(unit, value)
+			if len(positions) != 1 {
+				continue
+			}
+
+			position := positions[0]
+			return Step{
+				Technique:   HiddenSingle,
+				Cells:       positions,
+				Values:      []int{value},
+				Placements:  []Placement{{Row: position.Row, Cell: position.Cell, Value: value}},
+				Description: fmt.Sprintf("%s is the only place for %d in %s", cellName(position.Row, position.Cell), value, grid.Layout.unitName(unitIndex)),
+			}, true
+		}
+	}
+
+	return Step{}, false
+}
+
+// When all of a box's candidates for a value are in one row (or column), the
+// value has to go in that part of the row, so it can't go anywhere else in it.
+func findPointingPair(grid *CandidateGrid) (Step, bool) {
+	size := grid.Layout.Size()
+	units := grid.Layout.Units()
+
+	for boxIndex := 2 * size; boxIndex < 3*size; boxIndex++ {
+		for value := 1; value <= size; value++ {
+			positions := grid.positionsFor(units[boxIndex], value)
+			if len(positions) < 2 {
+				continue
+			}
+
+			for lineIndex := range 2 * size {
+				if !containsAll(units[lineIndex], positions) {
+					continue
+				}
+
+				eliminations := []Candidate{}
+				for _, position := range units[lineIndex] {
+					if grid.has(position, value) && !containsPosition(positions, position) {
+						eliminations = append(eliminations, Candidate{Row: position.Row, Cell: position.Cell, Value: value})
+					}
+				}
+
+				if len(eliminations) > 0 {
+					return Step{
+						Technique:    PointingPair,
+						Cells:        positions,
+						Values:       []int{value},
+						Eliminations: eliminations,
+						Description:  fmt.Sprintf("%d in %s is locked to %s (%s)", value, grid.Layout.unitName(boxIndex), grid.Layout.unitName(lineIndex), cellNames(positions)),
+					}, true
+				}
+			}
+		}
+	}
+
+	return Step{}, false
+}
+
+// When all of a row's (or column's) candidates for a value are in one box, the
+// value has to go in that part of the box, so it can't go anywhere else in it.
+func findBoxLineReduction(grid *CandidateGrid) (Step, bool) {
+	size := grid.Layout.Size()
+	units := grid.Layout.Units()
+
+	for lineIndex := range 2 * size {
+		for value := 1; value <= size; value++ {
+			positions := grid.positionsFor(units[lineIndex], value)
+			if len(positions) < 2 {
+				continue
+			}
+
+			for boxIndex := 2 * size; boxIndex < 3*size; boxIndex++ {
+				if !containsAll(units[boxIndex], positions) {
+					continue
+				}
+
+				eliminations := []Candidate{}
+				for _, position := range units[boxIndex] {
+					if grid.has(position, value) && !containsPosition(positions, position) {
+						eliminations = append(eliminations, Candidate{Row: position.Row, Cell: position.Cell, Value: value})
+					}
+				}
+
+				if len(eliminations) > 0 {
+					return Step{
+						Technique:    BoxLineReduction,
+						Cells:        positions,
+						Values:       []int{value},
+						Eliminations: eliminations,
+						Description:  fmt.Sprintf("%d in %s is locked to %s (%s)", value, grid.Layout.unitName(lineIndex), grid.Layout.unitName(boxIndex), cellNames(positions)),
+					}, true
+				}
+			}
+		}
+	}
+
+	return Step{}, false
+}
+
+var nakedSubsetTechniques = map[int]Technique{2: NakedPair, 3: NakedTriple, 4: NakedQuad}
+var hiddenSubsetTechniques = map[int]Technique{2: HiddenPair, 3: HiddenTriple, 4: HiddenQuad}
+
+// When n cells of a unit only have n values between them, those values have
+// to go in those cells, so they can't go anywhere else in the unit.
+func findNakedSubset(grid *CandidateGrid, size int) (Step, bool) {
+	for unitIndex, unit := range grid.Layout.Units() {
+		empty := []Position{}
+		for _, position := range unit {
+			count := grid.Candidates[position.Row][position.Cell].Count()
+			if count >= 2 && count <= size {
+				empty = append(empty, position)
+			}
+		}
+
+		for _, combination := range combinations(len(empty), size) {
+			cells := []Position{}
+			var values CandidateMask
+			for _, i := range combination {
+				cells = append(cells, empty[i])
+				values |= grid.Candidates[empty[i].Row][empty[i].Cell]
+			}
+			if values.Count() != size {
+				continue
+			}
+
+			eliminations := []Candidate{}
+			for _, position := range unit {
+				if containsPosition(cells, position) {
+					continue
+				}
+				for _, value := range (grid.Candidates[position.Row][position.Cell] & values).Values() {
+					eliminations = append(eliminations, Candidate{Row: position.Row, Cell: position.Cell, Value: value})
+				}
+			}
+
+			if len(eliminations) > 0 {
+				return Step{
+					Technique:    nakedSubsetTechniques[size],
+					Cells:        cells,
+					Values:       values.Values(),
+					Eliminations: eliminations,
+					Description:  fmt.Sprintf("%s only contain %s in %s", cellNames(cells), valueNames(values.Values()), grid.Layout.unitName(unitIndex)),
+				}, true
+			}
+		}
+	}
+
+	return Step{}, false
+}
+
+// When n values of a unit can only go in the same n cells, those cells have to
+// hold those values, so they can't hold anything else.
+func findHiddenSubset(grid *CandidateGrid, size int) (Step, bool) {
+	for unitIndex, unit := range grid.Layout.Units() {
+		values := []int{}
+		for value := 1; value <= grid.Layout.Size(); value++ {
+			count := len(grid.positionsFor(unit, value))
+			if count >= 2 && count <= size {
+				values = append(values, value)
+			}
+		}
+
+		for _, combination := range combinations(len(values), size) {
+			var subset CandidateMask
+			cells := []Position{}
+			for _, i := range combination {
+				subset |= MaskFor(values[i])
+				for _, position := range grid.positionsFor(unit, values[i]) {
+					if !containsPosition(cells, position) {
+						cells = append(cells, position)
+					}
+				}
+			}
+			if len(cells) != size {
+				continue
+			}
+
+			eliminations := []Candidate{}
+			for _, position := range unit {
+				if !containsPosition(cells, position) {
+					continue
+				}
+				for _, value := range (grid.Candidates[position.Row][position.Cell] &^ subset).Values() {
+					eliminations = append(eliminations, Candidate{Row: position.Row, Cell: position.Cell, Value: value})
+				}
+			}
+
+			if len(eliminations) > 0 {
+				return Step{
+					Technique:    hiddenSubsetTechniques[size],
+					Cells:        cells,
+					Values:       subset.Values(),
+					Eliminations: eliminations,
+					Description:  fmt.Sprintf("%s can only go in %s in %s", valueNames(subset.Values()), cellNames(cells), grid.Layout.unitName(unitIndex)),
+				}, true
+			}
+		}
+	}
+
+	return Step{}, false
+}
+
+// Every way of picking k of the indexes 0 through n-1, in lexicographic order.
+func combinations(n int, k int) [][]int {
+	result := [][]int{}
+	if k > n {
+		return result
+	}
+
+	combination := make([]int, k)
+	var build func(start int, depth int)
+	build = func(start int, depth int) {
+		if depth == k {
+			result = append(result, append([]int{}, combination...))
+			return
+		}
+
+		for i := start; i <= n-(k-depth); i++ {
+			combination[depth] = i
+			build(i+1, depth+1)
+		}
+	}
+	build(0, 0)
+
+	return result
+}
+
+func containsPosition(positions []Position, position Position) bool {
+	for _, p := range positions {
+		if p == position {
+			return true
+		}
+	}
+
+	return false
+}
+
+func containsAll(unit []Position, positions []Position) bool {
+	for _, position := range positions {
+		if !containsPosition(unit, position) {
+			return false
+		}
+	}
+
+	return true
+}
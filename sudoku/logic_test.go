@@ -0,0 +1,297 @@
+package sudoku
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// A grid with every cell empty and every value still possible, for setting up
+// candidate patterns by hand.
+func openCandidateGrid() CandidateGrid {
+	grid := CandidateGrid{
+		Layout:     Layout{Shape: StandardShape},
+		Values:     make([][]int, 9),
+		Candidates: make([][]CandidateMask, 9),
+	}
+	for row := range 9 {
+		grid.Values[row] = make([]int, 9)
+		grid.Candidates[row] = make([]CandidateMask, 9)
+		for cell := range 9 {
+			grid.Candidates[row][cell] = StandardShape.AllValuesMask()
+		}
+	}
+
+	return grid
+}
+
+func maskOf(values ...int) CandidateMask {
+	var mask CandidateMask
+	for _, value := range values {
+		mask |= MaskFor(value)
+	}
+
+	return mask
+}
+
+func eliminatedFrom(step Step, row int, cell int) []int {
+	values := []int{}
+	for _, elimination := range step.Eliminations {
+		if elimination.Row == row && elimination.Cell == cell {
+			values = append(values, elimination.Value)
+		}
+	}
+
+	return values
+}
+
+func TestLogicTechniques(t *testing.T) {
+	t.Run("naked single", func(t *testing.T) {
+		grid := openCandidateGrid()
+		grid.Candidates[4][4] = maskOf(7)
+
+		step, found := findNakedSingle(&grid)
+
+		assert.True(t, found)
+		assert.Equal(t, []Placement{{Row: 4, Cell: 4, Value: 7}}, step.Placements)
+		assert.Equal(t, "Naked Single: r5c5 can only be 7", step.String())
+	})
+
+	t.Run("hidden single", func(t *testing.T) {
+		grid := openCandidateGrid()
+		for _, position := range StandardShape.Units()[18] {
+			grid.Candidates[position.Row][position.Cell] &^= MaskFor(3)
+		}
+		grid.Candidates[1][2] |= MaskFor(3)
+
+		step, found := findHiddenSingle(&grid)
+
+		assert.True(t, found)
+		assert.Equal(t, []Placement{{Row: 1, Cell: 2, Value: 3}}, step.Placements)
+		assert.Equal(t, "r2c3 is the only place for 3 in box 1", step.Description)
+	})
+
+	t.Run("pointing pair", func(t *testing.T) {
+		grid := openCandidateGrid()
+		for _, position := range StandardShape.Units()[18] {
+			if position.Row != 0 {
+				grid.Candidates[position.Row][position.Cell] &^= MaskFor(5)
+			}
+		}
+
+		step, found := findPointingPair(&grid)
+
+		assert.True(t, found)
+		assert.Equal(t, PointingPair, step.Technique)
+		assert.Len(t, step.Eliminations, 6)
+		assert.Equal(t, []int{5}, eliminatedFrom(step, 0, 3))
+		assert.Empty(t, eliminatedFrom(step, 0, 0))
+	})
+
+	t.Run("box/line reduction", func(t *testing.T) {
+		grid := openCandidateGrid()
+		for cell := 3; cell < 9; cell++ {
+			grid.Candidates[0][cell] &^= MaskFor(5)
+		}
+
+		step, found := findBoxLineReduction(&grid)
+
+		assert.True(t, found)
+		assert.Equal(t, BoxLineReduction, step.Technique)
+		assert.Len(t, step.Eliminations, 6)
+		assert.Equal(t, []int{5}, eliminatedFrom(step, 2, 1))
+	})
+
+	t.Run("naked pair", func(t *testing.T) {
+		grid := openCandidateGrid()
+		grid.Candidates[0][0] = maskOf(1, 2)
+		grid.Candidates[0][5] = maskOf(1, 2)
+
+		step, found := findNakedSubset(&grid, 2)
+
+		assert.True(t, found)
+		assert.Equal(t, NakedPair, step.Technique)
+		assert.Equal(t, []int{1, 2}, step.Values)
+		assert.Equal(t, []int{1, 2}, eliminatedFrom(step, 0, 8))
+		assert.Empty(t, eliminatedFrom(step, 0, 5))
+		assert.Equal(t, "r1c1,r1c6 only contain 1/2 in row 1", step.Description)
+	})
+
+	t.Run("naked triple with no cell holding all three values", func(t *testing.T) {
+		grid := openCandidateGrid()
+		grid.Candidates[0][0] = maskOf(1, 2)
+		grid.Candidates[0][1] = maskOf(2, 3)
+		grid.Candidates[0][2] = maskOf(1, 3)
+
+		step, found := findNakedSubset(&grid, 3)
+
+		assert.True(t, found)
+		assert.Equal(t, NakedTriple, step.Technique)
+		assert.Equal(t, []int{1, 2, 3}, eliminatedFrom(step, 0, 4))
+	})
+
+	t.Run("hidden pair", func(t *testing.T) {
+		grid := openCandidateGrid()
+		for cell := 2; cell < 9; cell++ {
+			grid.Candidates[0][cell] &^= maskOf(8, 9)
+		}
+
+		step, found := findHiddenSubset(&grid, 2)
+
+		assert.True(t, found)
+		assert.Equal(t, HiddenPair, step.Technique)
+		assert.Equal(t, []Position{{Row: 0, Cell: 0}, {Row: 0, Cell: 1}}, step.Cells)
+		assert.Equal(t, []int{1, 2, 3, 4, 5, 6, 7}, eliminatedFrom(step, 0, 1))
+	})
+
+	t.Run("nothing to find", func(t *testing.T) {
+		grid := openCandidateGrid()
+
+		for _, technique := range AllTechniques {
+			_, found := techniqueFinders[technique](&grid)
+			assert.False(t, found, string(technique))
+		}
+	})
+}
+
+func TestLogicSolver(t *testing.T) {
+	// needs a naked pair partway through
+	board := [][]int{
+		{0, 0, 0, 5, 0, 1, 0, 7, 0},
+		{0, 0, 0, 0, 0, 9, 0, 0, 0},
+		{0, 0, 0, 0, 6, 0, 0, 2, 5},
+		{8, 0, 0, 0, 0, 0, 0, 9, 0},
+		{5, 0, 1, 0, 9, 3, 0, 0, 0},
+		{4, 0, 0, 0, 2, 0, 0, 0, 0},
+		{1, 2, 7, 0, 0, 0, 0, 0, 4},
+		{0, 0, 0, 0, 0, 6, 9, 0, 0},
+		{0, 0, 5, 0, 0, 0, 0, 3, 1},
+	}
+
+	t.Run("solves with every technique", func(t *testing.T) {
+		result := NewLogicSolver().Solve(Puzzle{Board: board})
+
+		assert.True(t, result.Solved)
+		assert.False(t, result.Contradiction)
+
+		techniques := map[Technique]bool{}
+		placements := 0
+		for _, step := range result.Steps {
+			techniques[step.Technique] = true
+			placements += len(step.Placements)
+		}
+		assert.True(t, techniques[NakedPair])
+		assert.Equal(t, 57, placements)
+	})
+
+	t.Run("gets stuck with singles alone", func(t *testing.T) {
+		solver := LogicSolver{Techniques: []Technique{HiddenSingle, NakedSingle}}
+
+		result := solver.Solve(Puzzle{Board: board})
+
+		assert.False(t, result.Solved)
+		assert.False(t, result.Contradiction)
+	})
+
+	t.Run("does not modify the puzzle", func(t *testing.T) {
+		puzzle := Puzzle{Board: board}
+
+		NewLogicSolver().Solve(puzzle)
+
+		assert.Equal(t, 57, puzzle.EmptyCellCount())
+	})
+
+	t.Run("reports a contradiction", func(t *testing.T) {
+		contradiction := [][]int{
+			{1, 2, 3, 4, 5, 6, 7, 8, 0},
+			{0, 0, 0, 0, 0, 0, 0, 0, 9},
+			{0, 0, 0, 0, 0, 0, 0, 0, 0},
+			{0, 0, 0, 0, 0, 0, 0, 0, 0},
+			{0, 0, 0, 0, 0, 0, 0, 0, 0},
+			{0, 0, 0, 0, 0, 0, 0, 0, 0},
+			{0, 0, 0, 0, 0, 0, 0, 0, 0},
+			{0, 0, 0, 0, 0, 0, 0, 0, 0},
+			{0, 0, 0, 0, 0, 0, 0, 0, 0},
+		}
+
+		result := NewLogicSolver().Solve(Puzzle{Board: contradiction})
+
+		assert.False(t, result.Solved)
+		assert.True(t, result.Contradiction)
+		assert.Empty(t, result.Steps)
+	})
+}
+
+func TestCombinations(t *testing.T) {
+	assert.Equal(t, [][]int{{0, 1}, {0, 2}, {1, 2}}, combinations(3, 2))
+	assert.Len(t, combinations(9, 4), 126)
+	assert.Empty(t, combinations(2, 3))
+}
+
+func TestLogicSolverToggles(t *testing.T) {
+	// needs an xy-wing partway through
+	board := [][]int{
+		{0, 4, 0, 1, 0, 8, 3, 0, 0},
+		{0, 0, 0, 0, 0, 0, 7, 4, 0},
+		{0, 7, 9, 3, 0, 0, 0, 0, 0},
+		{0, 1, 0, 2, 0, 4, 0, 0, 7},
+		{0, 0, 0, 0, 0, 0, 0, 0, 0},
+		{2, 0, 8, 0, 3, 0, 0, 6, 0},
+		{0, 5, 0, 7, 0, 2, 0, 8, 0},
+		{0, 0, 0, 9, 0, 0, 0, 0, 0},
+		{0, 0, 0, 0, 0, 0, 1, 0, 2},
+	}
+
+	usesTechnique := func(result LogicResult, technique Technique) bool {
+		for _, step := range result.Steps {
+			if step.Technique == technique {
+				return true
+			}
+		}
+		return false
+	}
+
+	t.Run("uses every technique by default", func(t *testing.T) {
+		result := NewLogicSolver().Solve(Puzzle{Board: board})
+
+		assert.True(t, result.Solved)
+		assert.True(t, usesTechnique(result, XYWing))
+	})
+
+	t.Run("leaves out techniques that are turned off", func(t *testing.T) {
+		solver := NewLogicSolver().Without(XYWing)
+
+		result := solver.Solve(Puzzle{Board: board})
+
+		assert.NotContains(t, solver.Techniques, XYWing)
+		assert.Len(t, solver.Techniques, len(AllTechniques)-1)
+		assert.False(t, usesTechnique(result, XYWing))
+	})
+}
+
+func TestParseTechnique(t *testing.T) {
+	tests := []struct {
+		value     string
+		technique Technique
+	}{
+		{value: "x-wing", technique: XWing},
+		{value: "box-line-reduction", technique: BoxLineReduction},
+		{value: "sashimi-jellyfish", technique: SashimiJellyfish},
+		{value: "Naked Pair", technique: NakedPair},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			technique, err := ParseTechnique(tt.value)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.technique, technique)
+		})
+	}
+
+	t.Run("unknown technique", func(t *testing.T) {
+		_, err := ParseTechnique("guessing")
+
+		assert.Error(t, err)
+	})
+}
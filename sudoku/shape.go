@@ -0,0 +1,171 @@
+package sudoku
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// The size of a board comes from the shape of its sectors: a board with
+// sectors of BoxRows x BoxColumns cells has BoxRows*BoxColumns rows, columns,
+// sectors, and values. Sectors are laid out BoxColumns across and BoxRows
+// down, so a 6x6 board with 2x3 sectors has two sectors in each band of rows.
+type Shape struct {
+	BoxRows    int
+	BoxColumns int
+}
+
+var StandardShape = Shape{BoxRows: 3, BoxColumns: 3}
+
+// The largest board a CandidateMask can hold the values of, and the values
+// have a symbol for.
+const MaxSize = 25
+
+// The usual shape of a board with the given number of rows: the sectors are
+// as close to square as they can be, and wider than they are tall.
+func ShapeForSize(size int) (Shape, error) {
+	if size < 4 || size > MaxSize {
+		return Shape{}, fmt.Errorf("Unsupported board size %d, expected between 4 and %d", size, MaxSize)
+	}
+
+	boxRows := 1
+	for rows := 2; rows*rows <= size; rows++ {
+		if size%rows == 0 {
+			boxRows = rows
+		}
+	}
+	if boxRows == 1 {
+		return Shape{}, fmt.Errorf("Unsupported board size %d, it can't be split into sectors", size)
+	}
+
+	return Shape{BoxRows: boxRows, BoxColumns: size / boxRows}, nil
+}
+
+// Parse a sector shape written as rows x columns, e.g. 2x3.
+func ParseShape(value string) (Shape, error) {
+	parts := strings.Split(strings.ToLower(value), "x")
+	if len(parts) != 2 {
+		return Shape{}, fmt.Errorf("Unrecognized box shape '%s', expected rows x columns, e.g. 2x3", value)
+	}
+
+	boxRows, rowsErr := strconv.Atoi(parts[0])
+	boxColumns, columnsErr := strconv.Atoi(parts[1])
+	if rowsErr != nil || columnsErr != nil {
+		return Shape{}, fmt.Errorf("Unrecognized box shape '%s', expected rows x columns, e.g. 2x3", value)
+	}
+
+	shape := Shape{BoxRows: boxRows, BoxColumns: boxColumns}
+	if boxRows < 2 || boxColumns < 2 || shape.Size() > MaxSize {
+		return Shape{}, fmt.Errorf("Unsupported box shape '%s', sectors need at least 2 rows and columns and the board can be at most %d cells across", value, MaxSize)
+	}
+
+	return shape, nil
+}
+
+func (shape Shape) String() string {
+	return fmt.Sprintf("%dx%d", shape.BoxRows, shape.BoxColumns)
+}
+
+// The number of rows, columns, sectors, and values of the board.
+func (shape Shape) Size() int {
+	return shape.BoxRows * shape.BoxColumns
+}
+
+func (shape Shape) SectorIndexFor(row int, cell int) int {
+	return (row/shape.BoxRows)*shape.BoxRows + cell/shape.BoxColumns
+}
+
+// The top-left cell of a sector.
+func (shape Shape) sectorOrigin(sectorIndex int) (int, int) {
+	return (sectorIndex / shape.BoxRows) * shape.BoxRows, (sectorIndex % shape.BoxRows) * shape.BoxColumns
+}
+
+// Every value of the board.
+func (shape Shape) AllValuesMask() CandidateMask {
+	return CandidateMask(1)<<shape.Size() - 1
+}
+
+// The units of each shape, which are looked up far too often to build every
+// time. Callers only read them.
+var unitsByShape sync.Map
+
+// Every row, column, and sector of the board, each as the positions of the
+// cells that make it up.
+func (shape Shape) Units() [][]Position {
+	if units, ok := unitsByShape.Load(shape); ok {
+		return units.([][]Position)
+	}
+
+	size := shape.Size()
+	units := [][]Position{}
+
+	for row := range size {
+		unit := []Position{}
+		for cell := range size {
+			unit = append(unit, Position{Row: row, Cell: cell})
+		}
+		units = append(units, unit)
+	}
+
+	for cell := range size {
+		unit := []Position{}
+		for row := range size {
+			unit = append(unit, Position{Row: row, Cell: cell})
+		}
+		units = append(units, unit)
+	}
+
+	for secIndex := range size {
+		firstRow, firstCell := shape.sectorOrigin(secIndex)
+
+		unit := []Position{}
+		for i := range shape.BoxRows {
+			for j := range shape.BoxColumns {
+				unit = append(unit, Position{Row: firstRow + i, Cell: firstCell + j})
+			}
+		}
+		units = append(units, unit)
+	}
+
+	unitsByShape.Store(shape, units)
+
+	return units
+}
+
+// Values are written 1 through 9 and then with letters, so every value of a
+// board up to 25x25 fits in a single character.
+const symbols = "123456789ABCDEFGHIJKLMNOP"
+
+// The character a value is written with, 0 for an empty cell.
+func Symbol(value int) string {
+	if value < 1 || value > len(symbols) {
+		return "0"
+	}
+
+	return string(symbols[value-1])
+}
+
+// Parse a value written as a symbol (letters in either case), as a number
+// (which may have more than one digit), or as 0, . or _ for an empty cell.
+func ParseSymbol(value string) (int, error) {
+	if value == "." || value == "_" {
+		return 0, nil
+	}
+
+	if number, err := strconv.Atoi(value); err == nil {
+		if number < 0 || number > MaxSize {
+			return 0, fmt.Errorf("Value '%s' is out of range, expected between 0 and %d", value, MaxSize)
+		}
+
+		return number, nil
+	}
+
+	if len(value) == 1 {
+		if index := strings.Index(symbols, strings.ToUpper(value)); index >= 0 {
+			return index + 1, nil
+		}
+	}
+
+	return 0, fmt.Errorf("Unrecognized value '%s', expected a number, a letter, or . or _ for an empty cell", value)
+}
@@ -0,0 +1,168 @@
+package sudoku
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// +-----------------+-----------------+-----------------+
+// | 0,0   0,1   0,2 | 0,3   0,4   0,5 | 0,6   0,7   0,8 |
+// | 1,0   1,1   1,2 | 1,3   1,4   1,5 | 1,6   1,7   1,8 |
+// | 2,0   2,1   2,2 | 2,3   2,4   2,5 | 2,6   2,7   2,8 |
+// +-----------------+-----------------+-----------------+
+// | 3,0   3,1   3,2 | 3,3   3,4   3,5 | 3,6   3,7   3,8 |
+// | 4,0   4,1   4,2 | 4,3   4,4   4,5 | 4,6   4,7   4,8 |
+// | 5,0   5,1   5,2 | 5,3   5,4   5,5 | 5,6   5,7   5,8 |
+// +-----------------+-----------------+-----------------+
+// | 6,0   6,1   6,2 | 6,3   6,4   6,5 | 6,6   6,7   6,8 |
+// | 7,0   7,1   7,2 | 7,3   7,4   7,5 | 7,6   7,7   7,8 |
+// | 8,0   8,1   8,2 | 8,3   8,4   8,5 | 8,6   8,7   8,8 |
+// +-----------------+-----------------+-----------------+
+
+func TestSectorIndexFor(t *testing.T) {
+	t.Run("first sector", func(t *testing.T) {
+		assert.Equal(t, 0, StandardShape.SectorIndexFor(0, 0))
+		assert.Equal(t, 0, StandardShape.SectorIndexFor(2, 2))
+		assert.Equal(t, 0, StandardShape.SectorIndexFor(2, 0))
+	})
+
+	t.Run("second sector", func(t *testing.T) {
+		assert.Equal(t, 1, StandardShape.SectorIndexFor(0, 3))
+		assert.Equal(t, 1, StandardShape.SectorIndexFor(2, 3))
+		assert.Equal(t, 1, StandardShape.SectorIndexFor(2, 5))
+	})
+
+	t.Run("ninth sector", func(t *testing.T) {
+		assert.Equal(t, 8, StandardShape.SectorIndexFor(6, 8))
+		assert.Equal(t, 8, StandardShape.SectorIndexFor(7, 7))
+		assert.Equal(t, 8, StandardShape.SectorIndexFor(8, 6))
+	})
+
+	// +-------+-------+
+	// | 0 0 0 | 1 1 1 |
+	// | 0 0 0 | 1 1 1 |
+	// +-------+-------+
+	// | 2 2 2 | 3 3 3 |
+	// | 2 2 2 | 3 3 3 |
+	// +-------+-------+
+	// | 4 4 4 | 5 5 5 |
+	// | 4 4 4 | 5 5 5 |
+	// +-------+-------+
+	t.Run("2x3 sectors", func(t *testing.T) {
+		shape := Shape{BoxRows: 2, BoxColumns: 3}
+
+		assert.Equal(t, 0, shape.SectorIndexFor(1, 2))
+		assert.Equal(t, 1, shape.SectorIndexFor(0, 3))
+		assert.Equal(t, 2, shape.SectorIndexFor(2, 0))
+		assert.Equal(t, 5, shape.SectorIndexFor(5, 5))
+	})
+
+	t.Run("3x2 sectors", func(t *testing.T) {
+		shape := Shape{BoxRows: 3, BoxColumns: 2}
+
+		assert.Equal(t, 0, shape.SectorIndexFor(2, 1))
+		assert.Equal(t, 2, shape.SectorIndexFor(0, 4))
+		assert.Equal(t, 3, shape.SectorIndexFor(3, 0))
+		assert.Equal(t, 5, shape.SectorIndexFor(5, 5))
+	})
+}
+
+func TestShapeUnits(t *testing.T) {
+	for _, shape := range []Shape{{BoxRows: 2, BoxColumns: 3}, {BoxRows: 3, BoxColumns: 2}, StandardShape, {BoxRows: 4, BoxColumns: 4}} {
+		t.Run(shape.String(), func(t *testing.T) {
+			units := shape.Units()
+			assert.Len(t, units, 3*shape.Size())
+
+			// every sector is made up of the cells with its sector index
+			for sectorIndex, unit := range units[2*shape.Size():] {
+				assert.Len(t, unit, shape.Size())
+				for _, position := range unit {
+					assert.Equal(t, sectorIndex, shape.SectorIndexFor(position.Row, position.Cell))
+				}
+			}
+		})
+	}
+}
+
+func TestShapeForSize(t *testing.T) {
+	tests := []struct {
+		size          int
+		expectedShape Shape
+	}{
+		{size: 4, expectedShape: Shape{BoxRows: 2, BoxColumns: 2}},
+		{size: 6, expectedShape: Shape{BoxRows: 2, BoxColumns: 3}},
+		{size: 8, expectedShape: Shape{BoxRows: 2, BoxColumns: 4}},
+		{size: 9, expectedShape: StandardShape},
+		{size: 12, expectedShape: Shape{BoxRows: 3, BoxColumns: 4}},
+		{size: 16, expectedShape: Shape{BoxRows: 4, BoxColumns: 4}},
+		{size: 25, expectedShape: Shape{BoxRows: 5, BoxColumns: 5}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expectedShape.String(), func(t *testing.T) {
+			shape, err := ShapeForSize(tt.size)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedShape, shape)
+		})
+	}
+
+	for _, size := range []int{1, 3, 7, 13, 36} {
+		_, err := ShapeForSize(size)
+		assert.Error(t, err, "size %d", size)
+	}
+}
+
+func TestParseShape(t *testing.T) {
+	shape, err := ParseShape("3x4")
+	assert.NoError(t, err)
+	assert.Equal(t, Shape{BoxRows: 3, BoxColumns: 4}, shape)
+
+	shape, err = ParseShape("2X3")
+	assert.NoError(t, err)
+	assert.Equal(t, Shape{BoxRows: 2, BoxColumns: 3}, shape)
+
+	for _, value := range []string{"3", "3x", "axb", "1x6", "6x6"} {
+		_, err := ParseShape(value)
+		assert.Error(t, err, value)
+	}
+}
+
+func TestSymbols(t *testing.T) {
+	tests := []struct {
+		value  int
+		symbol string
+	}{
+		{value: 0, symbol: "0"},
+		{value: 9, symbol: "9"},
+		{value: 10, symbol: "A"},
+		{value: 16, symbol: "G"},
+		{value: 25, symbol: "P"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.symbol, func(t *testing.T) {
+			assert.Equal(t, tt.symbol, Symbol(tt.value))
+
+			value, err := ParseSymbol(tt.symbol)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.value, value)
+		})
+	}
+
+	t.Run("other ways of writing values", func(t *testing.T) {
+		for symbol, expected := range map[string]int{".": 0, "_": 0, "g": 16, "12": 12, "25": 25} {
+			value, err := ParseSymbol(symbol)
+			assert.NoError(t, err)
+			assert.Equal(t, expected, value, symbol)
+		}
+	})
+
+	t.Run("unrecognized values", func(t *testing.T) {
+		for _, symbol := range []string{"Q", "26", "-1", "AB", "*"} {
+			_, err := ParseSymbol(symbol)
+			assert.Error(t, err, symbol)
+		}
+	})
+}
@@ -0,0 +1,536 @@
+package sudoku
+
+import (
+	"fmt"
+	"strings"
+)
+
+type Placement struct {
+	Row   int
+	Cell  int
+	Value int
+}
+
+type Puzzle struct {
+	Board    [][]int
+	Solution []Placement
+	// The shape of the board's sectors. When it's left out, the board gets
+	// the usual shape for its size, see ShapeForSize.
+	Shape Shape
+	// The region of every cell of a jigsaw puzzle, which takes the place of
+	// the boxes. Nil for a puzzle with boxes.
+	Regions [][]int
+	// The cages of a Killer Sudoku, nil for any other puzzle.
+	Cages []Cage
+	// The rules of a variant on top of the usual ones, like the diagonals of
+	// a Sudoku-X. See AllConstraints for every rule the puzzle has.
+	Constraints []Constraint
+
+	search *searchState
+}
+
+// The shape of the board's sectors, filling in the usual one for the board's
+// size if the puzzle doesn't have one.
+func (puz *Puzzle) GridShape() Shape {
+	if puz.Shape != (Shape{}) {
+		return puz.Shape
+	}
+
+	shape, err := ShapeForSize(len(puz.Board))
+	if err != nil && puz.Regions != nil {
+		// a jigsaw puzzle doesn't need boxes, so any size will do, even
+		// one that can't be split into them
+		return Shape{BoxRows: 1, BoxColumns: len(puz.Board)}
+	}
+	if err != nil {
+		// an unsupported size is reported by validation, until then treat
+		// the board as a standard one
+		return StandardShape
+	}
+
+	return shape
+}
+
+// How the board is split into sectors, its regions for a jigsaw puzzle or
+// else the boxes of its shape.
+func (puz *Puzzle) Layout() Layout {
+	return Layout{Shape: puz.GridShape(), Regions: puz.Regions}
+}
+
+// The number of rows, columns, sectors, and values of the board.
+func (puz *Puzzle) Size() int {
+	return puz.GridShape().Size()
+}
+
+// Every cell as a symbol, 0 for an empty one, with a line per row.
+func (puz *Puzzle) String() string {
+	board := puz.CurrentBoard()
+
+	var builder strings.Builder
+	for i, row := range board {
+		for _, cell := range row {
+			builder.WriteString(Symbol(cell))
+		}
+
+		// add a new line after each row of cells, unless it is the last row
+		if i != len(board)-1 {
+			builder.WriteString("\n")
+		}
+	}
+
+	return builder.String()
+}
+
+func (puz *Puzzle) PrettyString() string {
+	marks := puz.marks()
+	if puz.Cages != nil {
+		return puz.killerString(marks)
+	}
+	if puz.Regions != nil || !marks.empty() {
+		return puz.outlinedString(marks)
+	}
+
+	shape := puz.GridShape()
+	size := shape.Size()
+
+	// each sector is a space and a symbol per cell, plus a trailing space
+	border := func(left string, middle string, right string) string {
+		segment := strings.Repeat("═", shape.BoxColumns*2+1)
+		segments := make([]string, size/shape.BoxColumns)
+		for i := range segments {
+			segments[i] = segment
+		}
+
+		return left + strings.Join(segments, middle) + right
+	}
+	header := border("╔", "╤", "╗")
+	sectorDivider := border("╠", "╪", "╣")
+	footer := border("╚", "╧", "╝")
+
+	currentBoard := puz.CurrentBoard()
+
+	var builder strings.Builder
+
+	builder.WriteString(header)
+	builder.WriteString("\n")
+	for i, row := range currentBoard {
+		builder.WriteString("║")
+		for j, cell := range row {
+			if cell == 0 {
+				builder.WriteString(" _")
+			} else {
+				builder.WriteString(fmt.Sprintf(" %s", Symbol(cell)))
+			}
+
+			if j%shape.BoxColumns == shape.BoxColumns-1 {
+				if j == size-1 {
+					builder.WriteString(" ║")
+				} else {
+					builder.WriteString(" │")
+				}
+			}
+		}
+
+		builder.WriteString("\n")
+
+		if i%shape.BoxRows == shape.BoxRows-1 && i != size-1 {
+			builder.WriteString(sectorDivider)
+			builder.WriteString("\n")
+		}
+	}
+	builder.WriteString(footer)
+
+	return builder.String()
+}
+
+// Make a copy of the initial puzzle board with all Placements in the Solution
+// applied to it.
+func (puz *Puzzle) CurrentBoard() [][]int {
+	grid := puz.state().grid
+
+	currentBoard := make([][]int, len(grid))
+	for i := range grid {
+		currentBoard[i] = make([]int, len(grid[i]))
+		copy(currentBoard[i], grid[i])
+	}
+
+	return currentBoard
+}
+
+// A deep copy of the puzzle that doesn't share any state with the original,
+// so placements on one don't show up on the other.
+func (puz *Puzzle) Clone() Puzzle {
+	board := make([][]int, len(puz.Board))
+	for i, row := range puz.Board {
+		board[i] = make([]int, len(row))
+		copy(board[i], row)
+	}
+
+	solution := make([]Placement, len(puz.Solution))
+	copy(solution, puz.Solution)
+
+	// the regions, cages, and constraints never change, so the copies can
+	// share them
+	return Puzzle{Board: board, Solution: solution, Shape: puz.Shape, Regions: puz.Regions, Cages: puz.Cages, Constraints: puz.Constraints}
+}
+
+func removeBlanks(cells []int) []int {
+	compactedSlice := []int{}
+
+	for _, cell := range cells {
+		if cell != 0 {
+			compactedSlice = append(compactedSlice, cell)
+		}
+	}
+
+	return compactedSlice
+}
+
+func (puz *Puzzle) ValuesInRow(rowIndex int) []int {
+	return removeBlanks(puz.RowAt(rowIndex))
+}
+
+func (puz *Puzzle) ValuesInColumn(columnIndex int) []int {
+	return removeBlanks(puz.ColumnAt(columnIndex))
+}
+
+func (puz *Puzzle) ValuesInSector(sectorIndex int) []int {
+	return removeBlanks(puz.SectorAt(sectorIndex))
+}
+
+func (puz *Puzzle) RowAt(rowIndex int) []int {
+	if rowIndex < 0 || rowIndex >= puz.Size() {
+		panic(fmt.Sprintf("Invalid rowIndex %d", rowIndex))
+	}
+
+	row := make([]int, puz.Size())
+	copy(row, puz.state().grid[rowIndex])
+
+	return row
+}
+
+func (puz *Puzzle) ColumnAt(colIndex int) []int {
+	column := make([]int, 0, puz.Size())
+	for _, row := range puz.state().grid {
+		column = append(column, row[colIndex])
+	}
+
+	return column
+}
+
+func (puz *Puzzle) SectorAt(secIndex int) []int {
+	grid := puz.state().grid
+	layout := puz.Layout()
+
+	sector := make([]int, 0, layout.Size())
+	for _, position := range layout.Units()[2*layout.Size()+secIndex] {
+		sector = append(sector, grid[position.Row][position.Cell])
+	}
+
+	return sector
+}
+
+func (puz *Puzzle) PlaceValue(row int, cell int, value int) {
+	puz.state().place(row, cell, value)
+
+	potentialPlacement := Placement{Row: row, Cell: cell, Value: value}
+	puz.Solution = append(puz.Solution, potentialPlacement)
+}
+
+func (puz *Puzzle) UndoLastPlacement() {
+	if len(puz.Solution) == 0 {
+		return
+	}
+
+	// the state has to be looked up while it still matches the placements
+	search := puz.state()
+	last := puz.Solution[len(puz.Solution)-1]
+	puz.Solution = puz.Solution[:len(puz.Solution)-1]
+
+	// the cell goes back to whatever was there before the placement
+	previousValue := puz.Board[last.Row][last.Cell]
+	for i := len(puz.Solution) - 1; i >= 0; i-- {
+		p := puz.Solution[i]
+		if p.Row == last.Row && p.Cell == last.Cell {
+			previousValue = p.Value
+			break
+		}
+	}
+
+	search.restore(last.Row, last.Cell, previousValue)
+}
+
+type Position struct {
+	Row  int
+	Cell int
+}
+
+// The cell in r1c1 notation, counting rows and columns from 1.
+func (position Position) String() string {
+	return cellName(position.Row, position.Cell)
+}
+
+// A jigsaw puzzle's regions can have a border between any two cells, and a
+// variant's marks, like kropki dots, go between cells too, so every cell gets
+// room for them on each side. Borders between sectors are drawn as lines and
+// the ones inside a sector are left blank.
+func (puz *Puzzle) outlinedString(marks boardMarks) string {
+	board := puz.CurrentBoard()
+	layout := puz.Layout()
+
+	edge := func(a Position, b Position) outlineEdge {
+		return pick(layout.SectorIndexFor(a.Row, a.Cell) != layout.SectorIndexFor(b.Row, b.Cell), solidEdge, noEdge)
+	}
+	cellText := func(row int, cell int) string {
+		if board[row][cell] == 0 {
+			return " _ "
+		}
+
+		return fmt.Sprintf(" %s ", Symbol(board[row][cell]))
+	}
+
+	return outlineString(puz.Size(), puz.Size(), 3, edge, cellText, marks)
+}
+
+// What a variant draws on the board besides the values.
+type boardMarks struct {
+	// the mark between two cells that touch, keyed by the two of them in
+	// reading order
+	between map[[2]Position]string
+	// the brackets around a cell's value, like a thermometer's bulb
+	brackets map[Position][2]string
+}
+
+// A constraint that's drawn on the board, like a thermometer or a kropki dot.
+type drawnConstraint interface {
+	draw(marks boardMarks)
+}
+
+func (puz *Puzzle) marks() boardMarks {
+	marks := boardMarks{between: map[[2]Position]string{}, brackets: map[Position][2]string{}}
+	for _, constraint := range puz.Constraints {
+		if drawn, ok := constraint.(drawnConstraint); ok {
+			drawn.draw(marks)
+		}
+	}
+
+	return marks
+}
+
+func (marks boardMarks) empty() bool {
+	return len(marks.between) == 0 && len(marks.brackets) == 0
+}
+
+func inReadingOrder(a Position, b Position) [2]Position {
+	if b.Row < a.Row || (b.Row == a.Row && b.Cell < a.Cell) {
+		return [2]Position{b, a}
+	}
+
+	return [2]Position{a, b}
+}
+
+func (marks boardMarks) mark(a Position, b Position, glyph string) {
+	marks.between[inReadingOrder(a, b)] = glyph
+}
+
+// The mark between the two cells, "" if there isn't one.
+func (marks boardMarks) at(a Position, b Position) string {
+	return marks.between[inReadingOrder(a, b)]
+}
+
+func (marks boardMarks) bracket(position Position, open string, close string) {
+	marks.brackets[position] = [2]string{open, close}
+}
+
+// Join up every cell of a line with the next one, diagonally if they only
+// touch at a corner.
+func (marks boardMarks) connectPath(path []Position) {
+	for i := 1; i < len(path); i++ {
+		pair := inReadingOrder(path[i-1], path[i])
+		upper, lower := pair[0], pair[1]
+
+		switch {
+		case upper.Row == lower.Row:
+			marks.mark(upper, lower, "─")
+		case upper.Cell == lower.Cell:
+			marks.mark(upper, lower, "│")
+		case upper.Cell < lower.Cell:
+			marks.mark(upper, lower, "╲")
+		default:
+			marks.mark(upper, lower, "╱")
+		}
+	}
+}
+
+// The mark where the cells at (row, cell) and (row+1, cell+1) meet the ones
+// at (row, cell+1) and (row+1, cell), "" if neither pair has one.
+func (marks boardMarks) corner(row int, cell int) string {
+	down := marks.at(Position{Row: row, Cell: cell}, Position{Row: row + 1, Cell: cell + 1})
+	up := marks.at(Position{Row: row, Cell: cell + 1}, Position{Row: row + 1, Cell: cell})
+	if down != "" && up != "" {
+		return "╳"
+	}
+
+	return down + up
+}
+
+// How the border between two neighbouring cells is drawn.
+type outlineEdge int
+
+const (
+	noEdge outlineEdge = iota
+	dottedEdge
+	solidEdge
+)
+
+// Draw a board whose borders can run between any two cells, with the text
+// of every cell taking up the given width and its value second to last. The
+// marks go over the borders and gaps between the cells, and brackets go on
+// either side of the value.
+func outlineString(rows int, columns int, cellWidth int, edge func(a Position, b Position) outlineEdge, cellText func(row int, cell int) string, marks boardMarks) string {
+	// the border right of, or below, the cell
+	edgeRight := func(row int, cell int) outlineEdge {
+		if cell == columns-1 {
+			return noEdge
+		}
+		return edge(Position{Row: row, Cell: cell}, Position{Row: row, Cell: cell + 1})
+	}
+	edgeBelow := func(row int, cell int) outlineEdge {
+		if row == rows-1 {
+			return noEdge
+		}
+		return edge(Position{Row: row, Cell: cell}, Position{Row: row + 1, Cell: cell})
+	}
+
+	frame := func(left string, junction string, right string, row int) string {
+		var builder strings.Builder
+		builder.WriteString(left)
+		for cell := range columns {
+			builder.WriteString(strings.Repeat("═", cellWidth))
+			if cell < columns-1 {
+				builder.WriteString(pick(edgeRight(row, cell) == solidEdge, junction, "═"))
+			}
+		}
+		builder.WriteString(right)
+
+		return builder.String()
+	}
+	horizontal := map[outlineEdge]string{
+		noEdge:     strings.Repeat(" ", cellWidth),
+		dottedEdge: strings.Repeat("┄", cellWidth),
+		solidEdge:  strings.Repeat("─", cellWidth),
+	}
+	vertical := map[outlineEdge]string{noEdge: " ", dottedEdge: "┆", solidEdge: "│"}
+
+	var builder strings.Builder
+
+	builder.WriteString(frame("╔", "╤", "╗", 0))
+	builder.WriteString("\n")
+
+	for row := range rows {
+		builder.WriteString("║")
+		for cell := range columns {
+			text := cellText(row, cell)
+			if brackets, ok := marks.brackets[Position{Row: row, Cell: cell}]; ok {
+				text = text[:cellWidth-3] + brackets[0] + text[cellWidth-2:cellWidth-1] + brackets[1]
+			}
+			builder.WriteString(text)
+
+			if cell < columns-1 {
+				separator := vertical[edgeRight(row, cell)]
+				if glyph := marks.at(Position{Row: row, Cell: cell}, Position{Row: row, Cell: cell + 1}); glyph != "" {
+					separator = glyph
+				}
+				builder.WriteString(separator)
+			}
+		}
+		builder.WriteString("║\n")
+
+		if row == rows-1 {
+			break
+		}
+
+		builder.WriteString(pick(edgeBelow(row, 0) == solidEdge, "╟", "║"))
+		for cell := range columns {
+			segment := horizontal[edgeBelow(row, cell)]
+			if glyph := marks.at(Position{Row: row, Cell: cell}, Position{Row: row + 1, Cell: cell}); glyph != "" {
+				// under the value, like the brackets
+				runes := []rune(segment)
+				segment = string(runes[:cellWidth-2]) + glyph + string(runes[cellWidth-1:])
+			}
+			builder.WriteString(segment)
+
+			if cell < columns-1 {
+				corner := outlineJunction(
+					edgeRight(row, cell),
+					edgeRight(row+1, cell),
+					edgeBelow(row, cell),
+					edgeBelow(row, cell+1),
+				)
+				if glyph := marks.corner(row, cell); glyph != "" {
+					corner = glyph
+				}
+				builder.WriteString(corner)
+			}
+		}
+		builder.WriteString(pick(edgeBelow(row, columns-1) == solidEdge, "╢", "║"))
+		builder.WriteString("\n")
+	}
+
+	builder.WriteString(frame("╚", "╧", "╝", rows-1))
+
+	return builder.String()
+}
+
+// Where four cells meet, solid borders are joined up and dotted ones only
+// get a dot of their own when there's no solid border to meet.
+func outlineJunction(up outlineEdge, down outlineEdge, left outlineEdge, right outlineEdge) string {
+	solid := junction(up == solidEdge, down == solidEdge, left == solidEdge, right == solidEdge)
+	if solid != " " {
+		return solid
+	}
+
+	switch {
+	case up == dottedEdge && down == dottedEdge && left == noEdge && right == noEdge:
+		return "┆"
+	case left == dottedEdge && right == dottedEdge && up == noEdge && down == noEdge:
+		return "┄"
+	case up != noEdge || down != noEdge || left != noEdge || right != noEdge:
+		return "·"
+	default:
+		return " "
+	}
+}
+
+func pick[T any](condition bool, ifTrue T, ifFalse T) T {
+	if condition {
+		return ifTrue
+	}
+
+	return ifFalse
+}
+
+// The character where four cells meet, joining whichever of the borders
+// around it are there.
+func junction(up bool, down bool, left bool, right bool) string {
+	junctions := map[[4]bool]string{
+		{false, false, false, false}: " ",
+		{true, true, false, false}:   "│",
+		{false, false, true, true}:   "─",
+		{true, true, true, true}:     "┼",
+		{true, true, false, true}:    "├",
+		{true, true, true, false}:    "┤",
+		{false, true, true, true}:    "┬",
+		{true, false, true, true}:    "┴",
+		{false, true, false, true}:   "┌",
+		{false, true, true, false}:   "┐",
+		{true, false, false, true}:   "└",
+		{true, false, true, false}:   "┘",
+		{true, false, false, false}:  "╵",
+		{false, true, false, false}:  "╷",
+		{false, false, true, false}:  "╴",
+		{false, false, false, true}:  "╶",
+	}
+
+	return junctions[[4]bool{up, down, left, right}]
+}
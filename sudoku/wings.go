@@ -0,0 +1,195 @@
+package sudoku
+
+import (
+	"fmt"
+)
+
+func (grid *CandidateGrid) candidatesAt(position Position) CandidateMask {
+	return grid.Candidates[position.Row][position.Cell]
+}
+
+// The candidates of a cell the way they're written in pencil marks, e.g.
+// r1c1{12}.
+func pencilMarks(grid *CandidateGrid, position Position) string {
+	marks := ""
+	for _, value := range grid.candidatesAt(position).Values() {
+		marks += Symbol(value)
+	}
+
+	return fmt.Sprintf("%s{%s}", cellName(position.Row, position.Cell), marks)
+}
+
+// Every empty cell with exactly count candidates.
+func (grid *CandidateGrid) cellsWithCandidateCount(count int) []Position {
+	size := grid.Layout.Size()
+	cells := []Position{}
+	for row := range size {
+		for cell := range size {
+			if grid.Values[row][cell] == 0 && grid.Candidates[row][cell].Count() == count {
+				cells = append(cells, Position{Row: row, Cell: cell})
+			}
+		}
+	}
+
+	return cells
+}
+
+// Remove value from every cell that sees all of the given cells.
+func (grid *CandidateGrid) eliminationsSeenBy(value int, cells ...Position) []Candidate {
+	size := grid.Layout.Size()
+	eliminations := []Candidate{}
+	for row := range size {
+		for cell := range size {
+			position := Position{Row: row, Cell: cell}
+			if !grid.has(position, value) || containsPosition(cells, position) {
+				continue
+			}
+
+			seesAll := true
+			for _, other := range cells {
+				if !grid.Layout.sees(position, other) {
+					seesAll = false
+					break
+				}
+			}
+			if seesAll {
+				eliminations = append(eliminations, Candidate{Row: row, Cell: cell, Value: value})
+			}
+		}
+	}
+
+	return eliminations
+}
+
+// A pivot cell with candidates xy sees two pincers, one with xz and one with
+// yz. Whichever value the pivot takes, one of the pincers has to be z, so no
+// cell that sees both pincers can be z.
+func findXYWing(grid *CandidateGrid) (Step, bool) {
+	bivalues := grid.cellsWithCandidateCount(2)
+
+	for _, pivot := range bivalues {
+		pivotCandidates := grid.candidatesAt(pivot)
+
+		for i, first := range bivalues {
+			for _, second := range bivalues[i+1:] {
+				if !grid.Layout.sees(pivot, first) || !grid.Layout.sees(pivot, second) {
+					continue
+				}
+
+				firstCandidates := grid.candidatesAt(first)
+				secondCandidates := grid.candidatesAt(second)
+				shared := firstCandidates & secondCandidates
+				if shared.Count() != 1 || pivotCandidates&shared != 0 {
+					continue
+				}
+				if firstCandidates|secondCandidates != pivotCandidates|shared {
+					continue
+				}
+				// each pincer has to share a different value with the pivot
+				if firstCandidates&pivotCandidates == secondCandidates&pivotCandidates {
+					continue
+				}
+
+				value := shared.Values()[0]
+				eliminations := grid.eliminationsSeenBy(value, first, second)
+				if len(eliminations) > 0 {
+					return Step{
+						Technique:    XYWing,
+						Cells:        []Position{pivot, first, second},
+						Values:       []int{value},
+						Eliminations: eliminations,
+						Description:  fmt.Sprintf("pivot %s with pincers %s and %s, one of the pincers is %d", pencilMarks(grid, pivot), pencilMarks(grid, first), pencilMarks(grid, second), value),
+					}, true
+				}
+			}
+		}
+	}
+
+	return Step{}, false
+}
+
+// Like an XY-Wing, but the pivot also has z as a candidate, so one of the
+// three cells is z, and only a cell that sees all three can lose it.
+func findXYZWing(grid *CandidateGrid) (Step, bool) {
+	bivalues := grid.cellsWithCandidateCount(2)
+
+	for _, pivot := range grid.cellsWithCandidateCount(3) {
+		pivotCandidates := grid.candidatesAt(pivot)
+
+		for i, first := range bivalues {
+			for _, second := range bivalues[i+1:] {
+				if !grid.Layout.sees(pivot, first) || !grid.Layout.sees(pivot, second) {
+					continue
+				}
+
+				firstCandidates := grid.candidatesAt(first)
+				secondCandidates := grid.candidatesAt(second)
+				if firstCandidates == secondCandidates || firstCandidates|secondCandidates != pivotCandidates {
+					continue
+				}
+
+				shared := firstCandidates & secondCandidates
+				value := shared.Values()[0]
+				eliminations := grid.eliminationsSeenBy(value, pivot, first, second)
+				if len(eliminations) > 0 {
+					return Step{
+						Technique:    XYZWing,
+						Cells:        []Position{pivot, first, second},
+						Values:       []int{value},
+						Eliminations: eliminations,
+						Description:  fmt.Sprintf("pivot %s with pincers %s and %s, one of the three is %d", pencilMarks(grid, pivot), pencilMarks(grid, first), pencilMarks(grid, second), value),
+					}, true
+				}
+			}
+		}
+	}
+
+	return Step{}, false
+}
+
+// Two cells with the same two candidates xy, joined by a strong link on x: a
+// unit where x can only go in two cells, one seeing each of the xy cells. At
+// least one of the xy cells can't be x, so it has to be y, and no cell that
+// sees both of them can be y.
+func findWWing(grid *CandidateGrid) (Step, bool) {
+	bivalues := grid.cellsWithCandidateCount(2)
+	units := grid.Layout.Units()
+
+	for i, first := range bivalues {
+		for _, second := range bivalues[i+1:] {
+			candidates := grid.candidatesAt(first)
+			if grid.candidatesAt(second) != candidates || grid.Layout.sees(first, second) {
+				continue
+			}
+
+			values := candidates.Values()
+			for _, pair := range [][2]int{{values[0], values[1]}, {values[1], values[0]}} {
+				linked, eliminated := pair[0], pair[1]
+
+				eliminations := grid.eliminationsSeenBy(eliminated, first, second)
+				if len(eliminations) == 0 {
+					continue
+				}
+
+				for unitIndex, unit := range units {
+					ends := grid.positionsFor(unit, linked)
+					if len(ends) != 2 || containsPosition(ends, first) || containsPosition(ends, second) {
+						continue
+					}
+
+					if (grid.Layout.sees(ends[0], first) && grid.Layout.sees(ends[1], second)) || (grid.Layout.sees(ends[1], first) && grid.Layout.sees(ends[0], second)) {
+						return Step{
+							Technique:    WWing,
+							Cells:        []Position{first, second, ends[0], ends[1]},
+							Values:       []int{linked, eliminated},
+							Eliminations: eliminations,
+							Description:  fmt.Sprintf("%s and %s are linked by the strong link on %d in %s (%s), one of them is %d", pencilMarks(grid, first), pencilMarks(grid, second), linked, grid.Layout.unitName(unitIndex), cellNames(ends), eliminated),
+						}, true
+					}
+				}
+			}
+		}
+	}
+
+	return Step{}, false
+}
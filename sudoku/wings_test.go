@@ -0,0 +1,84 @@
+package sudoku
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWings(t *testing.T) {
+	t.Run("xy-wing", func(t *testing.T) {
+		grid := openCandidateGrid()
+		grid.Candidates[0][0] = maskOf(1, 2)
+		grid.Candidates[0][4] = maskOf(1, 3)
+		grid.Candidates[2][1] = maskOf(2, 3)
+
+		step, found := findXYWing(&grid)
+
+		assert.True(t, found)
+		assert.Equal(t, []Position{{Row: 0, Cell: 0}, {Row: 0, Cell: 4}, {Row: 2, Cell: 1}}, step.Cells)
+		assert.Equal(t, []Candidate{
+			{Row: 0, Cell: 1, Value: 3},
+			{Row: 0, Cell: 2, Value: 3},
+			{Row: 2, Cell: 3, Value: 3},
+			{Row: 2, Cell: 4, Value: 3},
+			{Row: 2, Cell: 5, Value: 3},
+		}, step.Eliminations)
+		assert.Equal(t, "pivot r1c1{12} with pincers r1c5{13} and r3c2{23}, one of the pincers is 3", step.Description)
+	})
+
+	t.Run("xy-wing needs pincers on different values", func(t *testing.T) {
+		grid := openCandidateGrid()
+		grid.Candidates[0][0] = maskOf(1, 2)
+		grid.Candidates[0][4] = maskOf(1, 3)
+		grid.Candidates[2][1] = maskOf(1, 3)
+
+		_, found := findXYWing(&grid)
+
+		assert.False(t, found)
+	})
+
+	t.Run("xyz-wing", func(t *testing.T) {
+		grid := openCandidateGrid()
+		grid.Candidates[0][0] = maskOf(1, 2, 3)
+		grid.Candidates[0][4] = maskOf(1, 3)
+		grid.Candidates[1][1] = maskOf(2, 3)
+
+		step, found := findXYZWing(&grid)
+
+		assert.True(t, found)
+		assert.Equal(t, []Candidate{{Row: 0, Cell: 1, Value: 3}, {Row: 0, Cell: 2, Value: 3}}, step.Eliminations)
+	})
+
+	t.Run("w-wing", func(t *testing.T) {
+		grid := openCandidateGrid()
+		grid.Candidates[0][0] = maskOf(1, 2)
+		grid.Candidates[4][3] = maskOf(1, 2)
+		restrictRows(&grid, 1, map[int][]int{2: {1, 3}})
+
+		step, found := findWWing(&grid)
+
+		assert.True(t, found)
+		assert.Equal(t, []int{1, 2}, step.Values)
+		assert.Equal(t, []Candidate{{Row: 0, Cell: 3, Value: 2}, {Row: 4, Cell: 0, Value: 2}}, step.Eliminations)
+		assert.Equal(t, "r1c1{12} and r5c4{12} are linked by the strong link on 1 in row 3 (r3c2,r3c4), one of them is 2", step.Description)
+	})
+
+	t.Run("w-wing without a strong link", func(t *testing.T) {
+		grid := openCandidateGrid()
+		grid.Candidates[0][0] = maskOf(1, 2)
+		grid.Candidates[4][3] = maskOf(1, 2)
+
+		_, found := findWWing(&grid)
+
+		assert.False(t, found)
+	})
+
+	t.Run("sees", func(t *testing.T) {
+		assert.True(t, Layout{Shape: StandardShape}.sees(Position{Row: 0, Cell: 0}, Position{Row: 0, Cell: 8}))
+		assert.True(t, Layout{Shape: StandardShape}.sees(Position{Row: 0, Cell: 0}, Position{Row: 8, Cell: 0}))
+		assert.True(t, Layout{Shape: StandardShape}.sees(Position{Row: 0, Cell: 0}, Position{Row: 2, Cell: 2}))
+		assert.False(t, Layout{Shape: StandardShape}.sees(Position{Row: 0, Cell: 0}, Position{Row: 3, Cell: 3}))
+		assert.False(t, Layout{Shape: StandardShape}.sees(Position{Row: 0, Cell: 0}, Position{Row: 0, Cell: 0}))
+	})
+}